@@ -0,0 +1,364 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/antonyuhnovets/flash-loan-arbitrage/config"
+	"github.com/antonyuhnovets/flash-loan-arbitrage/internal/app"
+	"github.com/antonyuhnovets/flash-loan-arbitrage/internal/entities"
+)
+
+// commands of daemon binary by name
+var commands = map[string]func(conf *config.Config, args []string) error{
+	"serve":        serve,
+	"parse":        parse,
+	"pools":        pools,
+	"tokens":       tokens,
+	"profit-check": profitCheck,
+	"arbitrage":    arbitrage,
+	"withdraw":     withdraw,
+	"deploy":       deploy,
+}
+
+// newFlags returns flags of command with output format flag
+func newFlags(name, usage string) (
+	fs *flag.FlagSet,
+	format *string,
+) {
+	fs = flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "usage:\n  %s\n", usage)
+		fs.PrintDefaults()
+	}
+	format = outputFlag(fs)
+
+	return
+}
+
+// parseFlags parses command flags & checks output format before
+// anything is sent
+func parseFlags(fs *flag.FlagSet, args []string, format *string) (
+	err error,
+) {
+	err = fs.Parse(args)
+	if err != nil {
+		return
+	}
+
+	if *format != formatJSON && *format != formatTable {
+		err = fmt.Errorf("unknown output format %q", *format)
+	}
+
+	return
+}
+
+// addressArgs returns positional arguments of command in checksum form
+func addressArgs(fs *flag.FlagSet, names ...string) (
+	out []string,
+	err error,
+) {
+	if fs.NArg() != len(names) {
+		fs.Usage()
+		err = fmt.Errorf("%s required", strings.Join(names, " & "))
+
+		return
+	}
+
+	for i, name := range names {
+		addr := fs.Arg(i)
+		if !entities.ValidAddress(addr) {
+			err = fmt.Errorf("invalid %s address %q", name, addr)
+
+			return
+		}
+		out = append(out, entities.Checksum(addr))
+	}
+
+	return
+}
+
+// useCases builds use cases of config, context is cancelled on interrupt
+func useCases(conf *config.Config) (
+	ctx context.Context,
+	stop context.CancelFunc,
+	a *app.App,
+	err error,
+) {
+	ctx, stop = signal.NotifyContext(
+		context.Background(),
+		os.Interrupt,
+		syscall.SIGTERM,
+	)
+
+	a, err = app.New(ctx, conf)
+	if err != nil {
+		stop()
+	}
+
+	return
+}
+
+// serve runs http api, tracker & trader until interrupted
+func serve(conf *config.Config, args []string) (
+	err error,
+) {
+	fs := flag.NewFlagSet("serve", flag.ContinueOnError)
+
+	err = fs.Parse(args)
+	if err != nil {
+		return
+	}
+
+	log.Printf(
+		"network: %s, \naccount: %s \ncontract: %s - %s\n",
+		conf.Blockchain.Name,
+		conf.Account.Address,
+		conf.Contract.Name,
+		conf.Contract.Address,
+	)
+
+	app.Run(conf)
+
+	return
+}
+
+// parse finds pools of stored tokens on configured protocols
+func parse(conf *config.Config, args []string) (
+	err error,
+) {
+	fs, format := newFlags("parse", "parse [-store] [-o format]")
+	store := fs.Bool("store", false, "store found pools")
+
+	err = parseFlags(fs, args, format)
+	if err != nil {
+		return
+	}
+
+	ctx, stop, a, err := useCases(conf)
+	if err != nil {
+		return
+	}
+	defer stop()
+
+	var found []entities.Pool
+	if *store {
+		err = a.Parse.ParseAndStore(ctx)
+		if err != nil {
+			return
+		}
+		found, err = a.Parse.GetPools(ctx)
+	} else {
+		found, err = a.Parse.JustParse(ctx)
+	}
+	if err != nil {
+		return
+	}
+	if found == nil {
+		found = make([]entities.Pool, 0)
+	}
+
+	err = printResult(os.Stdout, *format, poolTable(found))
+
+	return
+}
+
+// pools lists stored pools
+func pools(conf *config.Config, args []string) (
+	err error,
+) {
+	fs, format := newFlags("pools", "pools list [-o format]")
+	if len(args) == 0 || args[0] != "list" {
+		fs.Usage()
+		err = fmt.Errorf("unknown pools command %q", strings.Join(args, " "))
+
+		return
+	}
+
+	err = parseFlags(fs, args[1:], format)
+	if err != nil {
+		return
+	}
+
+	ctx, stop, a, err := useCases(conf)
+	if err != nil {
+		return
+	}
+	defer stop()
+
+	out, err := a.Parse.Repository.ListPools(ctx, "pools")
+	if err != nil {
+		return
+	}
+	if out == nil {
+		out = make([]entities.Pool, 0)
+	}
+
+	err = printResult(os.Stdout, *format, poolTable(out))
+
+	return
+}
+
+// tokens stores tokens with symbol, name & decimals read from chain
+func tokens(conf *config.Config, args []string) (
+	err error,
+) {
+	fs, format := newFlags("tokens", "tokens add [-o format] <address>...")
+	if len(args) == 0 || args[0] != "add" {
+		fs.Usage()
+		err = fmt.Errorf("unknown tokens command %q", strings.Join(args, " "))
+
+		return
+	}
+
+	err = parseFlags(fs, args[1:], format)
+	if err != nil {
+		return
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		err = fmt.Errorf("token address required")
+
+		return
+	}
+
+	added := make([]entities.Token, 0, fs.NArg())
+	for _, addr := range fs.Args() {
+		t := entities.Token{Address: addr}
+		if err = t.Validate(); err != nil {
+			return
+		}
+		added = append(added, t.Normalize())
+	}
+
+	ctx, stop, a, err := useCases(conf)
+	if err != nil {
+		return
+	}
+	defer stop()
+
+	added, err = a.Parse.Enrich(ctx, added)
+	if err != nil {
+		return
+	}
+
+	err = a.Parse.Repository.StoreTokens(ctx, "tokens", added)
+	if err != nil {
+		return
+	}
+
+	err = printResult(os.Stdout, *format, tokenTable(added))
+
+	return
+}
+
+// profitCheck estimates net profit of pair & sizes it
+func profitCheck(conf *config.Config, args []string) (
+	err error,
+) {
+	fs, format := newFlags("profit-check", "profit-check [-o format] <pool0> <pool1>")
+
+	err = parseFlags(fs, args, format)
+	if err != nil {
+		return
+	}
+	addrs, err := addressArgs(fs, "pool0", "pool1")
+	if err != nil {
+		return
+	}
+
+	ctx, stop, a, err := useCases(conf)
+	if err != nil {
+		return
+	}
+	defer stop()
+
+	est, err := a.Trade.CheckProfit(ctx, addrs[0], addrs[1])
+	if err != nil {
+		return
+	}
+
+	err = printResult(os.Stdout, *format, est)
+
+	return
+}
+
+// arbitrage sends flashArbitrage of pair if it is profitable,
+// dry run only simulates it from contract owner
+func arbitrage(conf *config.Config, args []string) (
+	err error,
+) {
+	fs, format := newFlags(
+		"arbitrage",
+		"arbitrage [-dry-run [-pending]] [-o format] <pool0> <pool1>",
+	)
+	dryRun := fs.Bool("dry-run", false, "simulate trade without sending tx")
+	pending := fs.Bool("pending", false, "simulate on pending state")
+
+	err = parseFlags(fs, args, format)
+	if err != nil {
+		return
+	}
+	if *pending && !*dryRun {
+		err = fmt.Errorf("-pending is only used with -dry-run")
+
+		return
+	}
+	addrs, err := addressArgs(fs, "pool0", "pool1")
+	if err != nil {
+		return
+	}
+
+	ctx, stop, a, err := useCases(conf)
+	if err != nil {
+		return
+	}
+	defer stop()
+
+	var res interface{}
+	if *dryRun {
+		res, err = a.Trade.Simulate(ctx, addrs[0], addrs[1], *pending)
+	} else {
+		res, err = a.Trade.Arbitrage(ctx, addrs[0], addrs[1])
+	}
+	if err != nil {
+		return
+	}
+
+	err = printResult(os.Stdout, *format, res)
+
+	return
+}
+
+// withdraw sends contract balance to owner
+func withdraw(conf *config.Config, args []string) (
+	err error,
+) {
+	fs, format := newFlags("withdraw", "withdraw [-o format]")
+
+	err = parseFlags(fs, args, format)
+	if err != nil {
+		return
+	}
+
+	ctx, stop, a, err := useCases(conf)
+	if err != nil {
+		return
+	}
+	defer stop()
+
+	tx, err := a.Trade.Withdraw(ctx)
+	if err != nil {
+		return
+	}
+
+	err = printResult(os.Stdout, *format, tx)
+
+	return
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/antonyuhnovets/flash-loan-arbitrage/config"
+	"github.com/antonyuhnovets/flash-loan-arbitrage/internal/app"
+)
+
+const deployUsage = `usage:
+  deploy [-base-tokens addr,...] [-env file]  deploy FlashBot with CONTRACT_INPUT as WETH
+  deploy verify [-address addr]               compare code at address with bindings
+  deploy transfer-ownership [-address addr] <new owner>`
+
+// deploy runs deploy subcommand, address of deployed contract
+// is written to env file as CONTRACT_ADDRESS
+func deploy(conf *config.Config, args []string) (
+	err error,
+) {
+	action := ""
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		action, args = args[0], args[1:]
+	}
+
+	fs := flag.NewFlagSet("deploy", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), deployUsage)
+		fs.PrintDefaults()
+	}
+	format := outputFlag(fs)
+	timeout := fs.Duration("timeout", 5*time.Minute, "time to wait for txs to be mined")
+	address := fs.String("address", conf.Contract.Address, "contract address")
+	baseTokens := fs.String("base-tokens", "", "comma separated base tokens to add after deployment")
+	envFile := fs.String("env", ".env", "env file to write address to, empty to skip")
+
+	err = parseFlags(fs, args, format)
+	if err != nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	d, err := app.NewDeployer(ctx, conf)
+	if err != nil {
+		return
+	}
+
+	var (
+		res      interface{}
+		mismatch error
+	)
+
+	switch action {
+	case "":
+		dep, _err := d.Deploy(ctx, splitList(*baseTokens)...)
+		if _err != nil {
+			err = _err
+
+			return
+		}
+		res = dep
+
+		conf.Contract.Address = dep.Address
+		os.Setenv("CONTRACT_ADDRESS", dep.Address)
+
+		if *envFile != "" {
+			err = config.WriteEnv(*envFile, "CONTRACT_ADDRESS", dep.Address)
+			if err != nil {
+				err = fmt.Errorf("contract %s deployed, write env: %w", dep.Address, err)
+
+				return
+			}
+		}
+	case "verify":
+		v, _err := d.Verify(ctx, *address)
+		if _err != nil {
+			err = _err
+
+			return
+		}
+		res = v
+
+		if !v.Match {
+			mismatch = fmt.Errorf("code at %s doesn't match bindings", v.Address)
+		}
+	case "transfer-ownership":
+		if fs.NArg() != 1 {
+			fs.Usage()
+			err = fmt.Errorf("new owner is required")
+
+			return
+		}
+
+		hash, _err := d.TransferOwnership(ctx, *address, fs.Arg(0))
+		if _err != nil {
+			err = _err
+
+			return
+		}
+		res = map[string]string{
+			"address": *address,
+			"owner":   fs.Arg(0),
+			"txHash":  hash,
+		}
+	default:
+		fs.Usage()
+		err = fmt.Errorf("unknown deploy command %q", action)
+
+		return
+	}
+
+	err = printResult(os.Stdout, *format, res)
+	if err == nil {
+		err = mismatch
+	}
+
+	return
+}
+
+func splitList(s string) (
+	out []string,
+) {
+	for _, item := range strings.Split(s, ",") {
+		item = strings.TrimSpace(item)
+		if item != "" {
+			out = append(out, item)
+		}
+	}
+
+	return
+}
@@ -1,24 +1,58 @@
 package main
 
 import (
+	"errors"
+	"flag"
+	"fmt"
 	"log"
+	"os"
 
 	"github.com/antonyuhnovets/flash-loan-arbitrage/config"
-	"github.com/antonyuhnovets/flash-loan-arbitrage/internal/app"
 )
 
+const usage = `usage: daemon [command] [flags] [args]
+
+commands:
+  serve                                   run http api, tracker & trader, the default
+  parse [-store]                          find pools of stored tokens
+  pools list                              list stored pools
+  tokens add <address>...                 store tokens with metadata read from chain
+  profit-check <pool0> <pool1>            estimate net profit & optimal size of pair
+  arbitrage [-dry-run] <pool0> <pool1>    send flashArbitrage of pair or simulate it
+  withdraw                                withdraw contract balance to owner
+  deploy [verify | transfer-ownership]    deploy & manage FlashBot contract
+
+results are printed as json, or as table with -o table.
+run "daemon <command> -h" for command flags`
+
 func main() {
+	name, args := "serve", os.Args[1:]
+	if len(args) > 0 {
+		name, args = args[0], args[1:]
+	}
+
+	if name == "help" || name == "-h" || name == "--help" {
+		fmt.Println(usage)
+
+		return
+	}
+
+	cmd, ok := commands[name]
+	if !ok {
+		fmt.Fprintln(os.Stderr, usage)
+		log.Fatalf("unknown command %q", name)
+	}
+
 	conf, err := config.LoadConfig()
 	if err != nil {
 		log.Fatal(err)
 	}
-	log.Printf(
-		"network: %s, \naccount: %s \ncontract: %s - %s\n",
-		conf.Blockchain.Name,
-		conf.Account.Address,
-		conf.Contract.Name,
-		conf.Contract.Address,
-	)
-
-	app.Run(conf)
+
+	err = cmd(conf, args)
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
 }
@@ -0,0 +1,194 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/antonyuhnovets/flash-loan-arbitrage/internal/entities"
+)
+
+// output formats of command results
+const (
+	formatJSON  = "json"
+	formatTable = "table"
+)
+
+// table is a result printed in its own columns, other results are
+// printed as field & value lines named by json tags
+type table interface {
+	header() []string
+	rows() [][]string
+}
+
+type poolTable []entities.Pool
+
+func (t poolTable) header() []string {
+	return []string{"ADDRESS", "PROTOCOL", "TOKEN0", "TOKEN1", "FEE_TIER", "FEE_BPS"}
+}
+
+func (t poolTable) rows() (rows [][]string) {
+	for _, p := range t {
+		rows = append(rows, []string{
+			p.Address,
+			p.Protocol.Name,
+			tokenName(p.Pair.Token0),
+			tokenName(p.Pair.Token1),
+			fmt.Sprint(p.FeeTier),
+			fmt.Sprint(p.FeeBps),
+		})
+	}
+
+	return
+}
+
+type tokenTable []entities.Token
+
+func (t tokenTable) header() []string {
+	return []string{"ADDRESS", "SYMBOL", "NAME", "DECIMALS"}
+}
+
+func (t tokenTable) rows() (rows [][]string) {
+	for _, tok := range t {
+		rows = append(rows, []string{
+			tok.Address,
+			tok.Symbol,
+			tok.Name,
+			fmt.Sprint(tok.Decimals),
+		})
+	}
+
+	return
+}
+
+// tokenName is symbol of token, address if symbol is not known
+func tokenName(t entities.Token) string {
+	if t.Symbol != "" {
+		return t.Symbol
+	}
+
+	return t.Address
+}
+
+// outputFlag adds -o flag of result format to command flags
+func outputFlag(fs *flag.FlagSet) *string {
+	return fs.String("o", formatJSON, "output format: json | table")
+}
+
+// printResult writes result of command in format
+func printResult(w io.Writer, format string, res interface{}) (
+	err error,
+) {
+	switch format {
+	case formatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+
+		err = enc.Encode(res)
+	case formatTable:
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+		var rows [][]string
+		if t, ok := res.(table); ok {
+			rows = append([][]string{t.header()}, t.rows()...)
+		} else {
+			rows = fields(reflect.ValueOf(res))
+		}
+		for _, row := range rows {
+			fmt.Fprintln(tw, strings.Join(row, "\t"))
+		}
+
+		err = tw.Flush()
+	default:
+		err = fmt.Errorf("unknown output format %q", format)
+	}
+
+	return
+}
+
+// fields returns json names & values of struct or map, embedded
+// structs are flattened & empty omitempty fields skipped as in json
+func fields(v reflect.Value) (
+	rows [][]string,
+) {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return
+		}
+		v = v.Elem()
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			name, opts, _ := strings.Cut(f.Tag.Get("json"), ",")
+			if name == "-" {
+				continue
+			}
+			fv := v.Field(i)
+
+			// fields of embedded struct are promoted even if it's unexported
+			if f.Anonymous && name == "" && fv.Kind() == reflect.Struct {
+				rows = append(rows, fields(fv)...)
+
+				continue
+			}
+			if !f.IsExported() {
+				continue
+			}
+			if strings.Contains(opts, "omitempty") && empty(fv) {
+				continue
+			}
+			if name == "" {
+				name = f.Name
+			}
+			rows = append(rows, []string{name, cell(fv)})
+		}
+	case reflect.Map:
+		keys := v.MapKeys()
+		sort.Slice(keys, func(i, j int) bool {
+			return fmt.Sprint(keys[i]) < fmt.Sprint(keys[j])
+		})
+		for _, k := range keys {
+			rows = append(rows, []string{fmt.Sprint(k), cell(v.MapIndex(k))})
+		}
+	default:
+		rows = [][]string{{cell(v)}}
+	}
+
+	return
+}
+
+func empty(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Slice, reflect.Map, reflect.String, reflect.Array:
+		return v.Len() == 0
+	}
+
+	return v.IsZero()
+}
+
+// cell is json of value, strings are unquoted
+func cell(v reflect.Value) string {
+	b, err := json.Marshal(v.Interface())
+	if err != nil {
+		return fmt.Sprint(v.Interface())
+	}
+
+	var s string
+	if json.Unmarshal(b, &s) == nil {
+		return s
+	}
+	if string(b) == "null" {
+		return ""
+	}
+
+	return string(b)
+}
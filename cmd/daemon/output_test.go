@@ -0,0 +1,96 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/antonyuhnovets/flash-loan-arbitrage/internal/entities"
+)
+
+type testInner struct {
+	Pool0 string `json:"pool0"`
+	Pool1 string `json:"pool1"`
+}
+
+type testResult struct {
+	testInner
+	Profit *entities.Amount `json:"profit"`
+	Reason string           `json:"reason,omitempty"`
+	Tokens []string         `json:"tokens"`
+	Hidden int              `json:"-"`
+}
+
+func TestPrintTable(t *testing.T) {
+	res := testResult{
+		testInner: testInner{Pool0: "0xA", Pool1: "0xB"},
+		Profit:    entities.NewAmount(big.NewInt(1500)),
+		Tokens:    []string{"0xC"},
+		Hidden:    1,
+	}
+
+	var buf bytes.Buffer
+	if err := printResult(&buf, formatTable, res); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	want := [][]string{
+		{"pool0", "0xA"},
+		{"pool1", "0xB"},
+		{"profit", "1500"},
+		{"tokens", `["0xC"]`},
+	}
+	if len(lines) != len(want) {
+		t.Fatalf("lines %q, expected %v", lines, want)
+	}
+	for i, w := range want {
+		if got := strings.Fields(lines[i]); strings.Join(got, " ") != strings.Join(w, " ") {
+			t.Errorf("line %v: %q, expected %q", i, got, w)
+		}
+	}
+}
+
+func TestPrintPoolTable(t *testing.T) {
+	pools := poolTable{{
+		Address:  "0xPool",
+		Protocol: entities.SwapProtocol{Name: "Uniswap-V3"},
+		Pair: entities.TokenPair{
+			Token0: entities.Token{Address: "0xT0", Symbol: "WETH"},
+			Token1: entities.Token{Address: "0xT1"},
+		},
+		FeeTier: 500,
+	}}
+
+	var buf bytes.Buffer
+	if err := printResult(&buf, formatTable, pools); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("lines %q", lines)
+	}
+	if got := strings.Join(strings.Fields(lines[1]), " "); got != "0xPool Uniswap-V3 WETH 0xT1 500 0" {
+		t.Errorf("row %q", got)
+	}
+
+	// json of table is json of pools
+	buf.Reset()
+	if err := printResult(&buf, formatJSON, pools); err != nil {
+		t.Fatal(err)
+	}
+	var out []entities.Pool
+	if err := json.Unmarshal(buf.Bytes(), &out); err != nil {
+		t.Fatal(err)
+	}
+	if len(out) != 1 || out[0].Address != "0xPool" {
+		t.Errorf("decoded %+v", out)
+	}
+
+	if err := printResult(&buf, "yaml", pools); err == nil {
+		t.Error("unknown format accepted")
+	}
+}
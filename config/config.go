@@ -1,6 +1,9 @@
 package config
 
 import (
+	"fmt"
+	"time"
+
 	"github.com/ilyakaznacheev/cleanenv"
 	"github.com/joho/godotenv"
 )
@@ -10,6 +13,12 @@ type Config struct {
 	HttpServer
 	Storage
 	Blockchain
+	Trader
+	Tracker
+	Relay
+	Discovery
+	Validation
+	Routes
 }
 
 type Log struct {
@@ -42,13 +51,50 @@ type Database struct {
 type Blockchain struct {
 	Name string `env:"BLOCKCHAIN_NAME" env-default:"goerli"`
 	Url  string `env:"BLOCKCHAIN_RPC_URL"`
+	Fees
 	Account
+	Wallets
 	Contract
 }
 
+type Fees struct {
+	Mode       string  `env:"BLOCKCHAIN_FEE_MODE" env-default:"dynamic"` // dynamic | legacy
+	Percentile float64 `env:"BLOCKCHAIN_FEE_PERCENTILE" env-default:"50"`
+	Blocks     uint64  `env:"BLOCKCHAIN_FEE_HISTORY_BLOCKS" env-default:"10"`
+	GasMargin  uint64  `env:"BLOCKCHAIN_GAS_MARGIN" env-default:"20"` // percent
+}
+
 type Account struct {
-	Address string `env:"ACCOUNT_ADDRESS"`
-	// pk      string `env:"ACCOUNT_PRIVATE_KEY"`
+	Address        string   `env:"ACCOUNT_ADDRESS"`                                             // checked against signer, required by remote one
+	Signer         string   `env:"ACCOUNT_SIGNER" env-default:"keystore"`                       // keystore | remote | key (development only)
+	Keystore       string   `env:"ACCOUNT_KEYSTORE"`                                            // V3 json key file
+	PassphraseFile string   `env:"ACCOUNT_PASSPHRASE_FILE"`                                     // passphrase on the first line
+	SignerUrl      string   `env:"ACCOUNT_SIGNER_URL"`                                          // clef or web3signer json-rpc
+	SignerMethod   string   `env:"ACCOUNT_SIGNER_METHOD" env-default:"account_signTransaction"` // eth_signTransaction for web3signer
+	PoolKeystores  []string `env:"ACCOUNT_POOL_KEYSTORES"`                                      // more trade signers, same passphrase
+	KeystoreDir    string   `env:"ACCOUNT_KEYSTORE_DIR"`                                        // keystores added over api by file name, api can't add any if empty
+	PoolAddresses  []string `env:"ACCOUNT_POOL_ADDRESSES"`                                      // more trade signers held by remote signer
+	PrivateKey     string   `env:"ACCOUNT_PRIVATE_KEY"`                                         // hex key of key signer only
+	PoolKeys       []string `env:"ACCOUNT_POOL_KEYS"`                                           // hex keys of trade signers, key signer only
+}
+
+// Validate rejects raw keys unless the development key signer is used
+func (a Account) Validate() error {
+	if a.Signer == "key" {
+		return nil
+	}
+	if a.PrivateKey != "" || len(a.PoolKeys) > 0 {
+		return fmt.Errorf(
+			"ACCOUNT_PRIVATE_KEY & ACCOUNT_POOL_KEYS are only used by key signer, not %q",
+			a.Signer,
+		)
+	}
+
+	return nil
+}
+
+type Wallets struct {
+	Selection string `env:"WALLET_SELECTION" env-default:"round-robin"` // round-robin | least-pending
 }
 
 type Contract struct {
@@ -57,6 +103,56 @@ type Contract struct {
 	Input   string `env:"CONTRACT_INPUT"`
 }
 
+type Trader struct {
+	Enabled   bool          `env:"TRADER_ENABLED" env-default:"false"`
+	Mode      string        `env:"TRADER_MODE" env-default:"poll"` // subscribe | poll
+	Interval  time.Duration `env:"TRADER_INTERVAL" env-default:"12s"`
+	MinProfit string        `env:"TRADER_MIN_PROFIT" env-default:"0"`           // before gas, priced in WETH wei
+	MinNet    string        `env:"TRADER_MIN_NET_PROFIT" env-default:"0"`       // after gas, in base token wei
+	MaxTrades int           `env:"TRADER_MAX_TRADES_PER_BLOCK" env-default:"1"` // 0 is unlimited
+	DryRun    bool          `env:"TRADER_DRY_RUN" env-default:"true"`
+	Private   bool          `env:"TRADER_PRIVATE" env-default:"false"` // send trades through relay
+}
+
+// Validate rejects negative trade limit
+func (t Trader) Validate() error {
+	if t.MaxTrades < 0 {
+		return fmt.Errorf("TRADER_MAX_TRADES_PER_BLOCK is %v, 0 is unlimited", t.MaxTrades)
+	}
+
+	return nil
+}
+
+type Tracker struct {
+	Interval    time.Duration `env:"TRACKER_INTERVAL" env-default:"4s"`
+	DropAfter   time.Duration `env:"TRACKER_DROP_AFTER" env-default:"10m"`      // unknown to node for this long means dropped
+	BumpAfter   uint64        `env:"TRACKER_BUMP_AFTER_BLOCKS" env-default:"3"` // 0 disables fee bumping
+	BumpPercent uint64        `env:"TRACKER_BUMP_PERCENT" env-default:"12"`
+	MaxBumps    int           `env:"TRACKER_MAX_BUMPS" env-default:"3"`
+}
+
+type Relay struct {
+	Url        string `env:"RELAY_URL"`
+	SigningKey string `env:"RELAY_SIGNING_KEY"` // identity key, not the wallet one
+	Window     int    `env:"RELAY_BLOCK_WINDOW" env-default:"3"`
+}
+
+type Validation struct {
+	Enabled      bool   `env:"VALIDATION_ENABLED" env-default:"true"`
+	BaseToken    string `env:"VALIDATION_BASE_TOKEN"`                    // CONTRACT_INPUT if empty
+	MinLiquidity string `env:"VALIDATION_MIN_LIQUIDITY" env-default:"0"` // in base token wei
+}
+
+type Discovery struct {
+	Chunk      uint64 `env:"DISCOVERY_CHUNK_BLOCKS" env-default:"5000"` // blocks per eth_getLogs
+	StartBlock uint64 `env:"DISCOVERY_START_BLOCK" env-default:"0"`     // required by factories without checkpoint, e.g. deployment block
+}
+
+type Routes struct {
+	MaxHops int `env:"ROUTES_MAX_HOPS" env-default:"3"` // longest cycle searched
+	Limit   int `env:"ROUTES_LIMIT" env-default:"100"`  // routes kept
+}
+
 func LoadConfig() (*Config, error) {
 	var conf Config
 
@@ -68,5 +164,13 @@ func LoadConfig() (*Config, error) {
 		return nil, err
 	}
 
+	if err := conf.Account.Validate(); err != nil {
+		return nil, err
+	}
+
+	if err := conf.Trader.Validate(); err != nil {
+		return nil, err
+	}
+
 	return &conf, nil
 }
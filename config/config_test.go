@@ -0,0 +1,30 @@
+package config
+
+import "testing"
+
+func TestAccountValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		account Account
+		ok      bool
+	}{
+		{"keystore", Account{Signer: "keystore", PoolKeystores: []string{"a.json"}}, true},
+		{"key signer with keys", Account{Signer: "key", PrivateKey: "0x01", PoolKeys: []string{"0x02"}}, true},
+		{"keystore with raw key", Account{Signer: "keystore", PrivateKey: "0x01"}, false},
+		{"remote with pool keys", Account{Signer: "remote", PoolKeys: []string{"0x02"}}, false},
+	}
+
+	for _, tc := range tests {
+		if err := tc.account.Validate(); (err == nil) != tc.ok {
+			t.Errorf("%s: %v", tc.name, err)
+		}
+	}
+}
+
+func TestTraderValidate(t *testing.T) {
+	for max, ok := range map[int]bool{-1: false, 0: true, 3: true} {
+		if err := (Trader{MaxTrades: max}).Validate(); (err == nil) != ok {
+			t.Errorf("max trades %v: %v", max, err)
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// WriteEnv sets key in env file keeping other lines & comments,
+// key is appended if it isn't there & file is created if missing
+func WriteEnv(path, key, value string) (
+	err error,
+) {
+	data, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return
+	}
+
+	line := fmt.Sprintf("%s = %q", key, value)
+	re := regexp.MustCompile(
+		`(?m)^[ \t]*(export[ \t]+)?` + regexp.QuoteMeta(key) + `[ \t]*=.*$`,
+	)
+
+	content := string(data)
+	switch {
+	case re.MatchString(content):
+		content = re.ReplaceAllLiteralString(content, line)
+	case content == "" || strings.HasSuffix(content, "\n"):
+		content += line + "\n"
+	default:
+		content += "\n" + line + "\n"
+	}
+
+	err = os.WriteFile(path, []byte(content), 0600)
+
+	return
+}
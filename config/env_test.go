@@ -0,0 +1,64 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriteEnv(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".env")
+
+	tests := []struct {
+		name, before, key, value, after string
+	}{
+		{
+			name:  "missing file",
+			key:   "CONTRACT_ADDRESS",
+			value: "0x01",
+			after: "CONTRACT_ADDRESS = \"0x01\"\n",
+		},
+		{
+			name:   "replace keeping comments",
+			before: "# Contract\nCONTRACT_NAME = \"FlashBot\"\nCONTRACT_ADDRESS = \"\"\nCONTRACT_INPUT = \"0x02\"\n",
+			key:    "CONTRACT_ADDRESS",
+			value:  "0x01",
+			after:  "# Contract\nCONTRACT_NAME = \"FlashBot\"\nCONTRACT_ADDRESS = \"0x01\"\nCONTRACT_INPUT = \"0x02\"\n",
+		},
+		{
+			name:   "exported key",
+			before: "export CONTRACT_ADDRESS=0x03",
+			key:    "CONTRACT_ADDRESS",
+			value:  "0x01",
+			after:  "CONTRACT_ADDRESS = \"0x01\"",
+		},
+		{
+			name:   "append without trailing newline",
+			before: "CONTRACT_ADDRESS_OLD = \"0x03\"",
+			key:    "CONTRACT_ADDRESS",
+			value:  "0x01",
+			after:  "CONTRACT_ADDRESS_OLD = \"0x03\"\nCONTRACT_ADDRESS = \"0x01\"\n",
+		},
+	}
+
+	for _, tt := range tests {
+		os.Remove(path)
+		if tt.before != "" {
+			if err := os.WriteFile(path, []byte(tt.before), 0600); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		if err := WriteEnv(path, tt.key, tt.value); err != nil {
+			t.Fatalf("%s: %s", tt.name, err)
+		}
+
+		got, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != tt.after {
+			t.Errorf("%s: got %q, expected %q", tt.name, got, tt.after)
+		}
+	}
+}
@@ -4,12 +4,13 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"math/big"
 	"syscall"
 
 	"os"
-	"os/exec"
 	"os/signal"
 
+	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/gin-gonic/gin"
 
 	"github.com/antonyuhnovets/flash-loan-arbitrage/config"
@@ -21,34 +22,56 @@ import (
 	"github.com/antonyuhnovets/flash-loan-arbitrage/internal/trade/parser"
 	"github.com/antonyuhnovets/flash-loan-arbitrage/internal/trade/provider"
 	"github.com/antonyuhnovets/flash-loan-arbitrage/internal/trade/repo"
+	"github.com/antonyuhnovets/flash-loan-arbitrage/pkg/bundle"
+	"github.com/antonyuhnovets/flash-loan-arbitrage/pkg/discovery"
+	"github.com/antonyuhnovets/flash-loan-arbitrage/pkg/erc20"
 	"github.com/antonyuhnovets/flash-loan-arbitrage/pkg/ethereum"
 	"github.com/antonyuhnovets/flash-loan-arbitrage/pkg/httpserver"
 	"github.com/antonyuhnovets/flash-loan-arbitrage/pkg/logger"
+	"github.com/antonyuhnovets/flash-loan-arbitrage/pkg/reserves"
+	"github.com/antonyuhnovets/flash-loan-arbitrage/pkg/routes"
+	"github.com/antonyuhnovets/flash-loan-arbitrage/pkg/signer"
 )
 
-func Run(conf *config.Config) {
-
-	ctx := context.Background()
+// App holds use cases built from config, they are shared by
+// http server & cli commands
+type App struct {
+	Trade  *trade.TradeCase
+	Parse  trade.ParseCase
+	Logger *logger.Logger
+}
 
+// New connects ethereum client, contract & storage and builds use cases
+func New(ctx context.Context, conf *config.Config) (
+	a *App,
+	err error,
+) {
 	// ethereum client setup
+	_, err = ethereum.ParseSelection(conf.Blockchain.Wallets.Selection)
+	if err != nil {
+		return
+	}
+	clientOpts := ClientOptions(conf.Blockchain)
+
 	cl, err := ethereum.NewClient(
 		conf.Blockchain.Url,
+		clientOpts...,
 	)
 	if err != nil {
-		fmt.Println(err)
+		return
 	}
 
 	// contract connect
 	ap, err := api.NewApi(
 		ethereum.ToAddress(conf.Blockchain.Contract.Address),
-		cl.Client,
+		cl.Backend(),
 	)
 	if err != nil {
-		log.Fatal(err)
+		return
 	}
 	cont, err := contract.New(conf.Blockchain.Contract.Address, ap)
 	if err != nil {
-		log.Fatal(err)
+		return
 	}
 
 	// Tradecase
@@ -59,77 +82,282 @@ func Run(conf *config.Config) {
 		make([]entities.TradePair, 0),
 	)
 	// provider create
+	accountSigner, err := Signer(ctx, conf.Blockchain.Account)
+	if err != nil {
+		err = fmt.Errorf("account signer: %w", err)
+
+		return
+	}
 	provider, err := provider.NewTradeProvider(
-		ctx, conf.Blockchain.Url, os.Getenv("ACCOUNT_PRIVATE_KEY"),
+		ctx, conf.Blockchain.Url, accountSigner,
+		clientOpts...,
 	)
 	if err != nil {
-		log.Fatal(err)
+		return
 	}
 
-	// repository setup
-	var repository trade.Repository
+	provider.Signers = &signer.Source{
+		PassphraseFile: conf.Blockchain.Account.PassphraseFile,
+		KeystoreDir:    conf.Blockchain.Account.KeystoreDir,
+		URL:            conf.Blockchain.Account.SignerUrl,
+		Method:         conf.Blockchain.Account.SignerMethod,
+	}
 
-	switch conf.Storage.Type {
-	case "localfile":
-		files := map[string]string{
-			"pools": fmt.Sprintf(
-				"%s/pools.json",
-				conf.Storage.Localstorage.Path,
-			),
-			"tokens": fmt.Sprintf(
-				"%s/tokens.json",
-				conf.Storage.Localstorage.Path,
-			),
+	// extra trade signers
+	// configured keystores are trusted, api opens them by name only
+	for _, path := range conf.Blockchain.Account.PoolKeystores {
+		var k *signer.Key
+		k, err = provider.Signers.Keystore(path)
+		if err == nil {
+			_, err = provider.AddSigner(ctx, k)
 		}
-		repository, err = repo.NewStorage(files)
 		if err != nil {
-			log.Fatal(err)
+			err = fmt.Errorf("add pool wallet %s: %w", path, err)
+
+			return
 		}
-	case "database":
-		if conf.Storage.Database.Driver == "postgres" {
-			repository, err = repo.New(conf.Database)
+	}
+
+	for _, addr := range conf.Blockchain.Account.PoolAddresses {
+		_, err = provider.AddWallet(ctx, "", addr)
+		if err != nil {
+			err = fmt.Errorf("add pool wallet %s: %w", addr, err)
+
+			return
+		}
+	}
+
+	// raw keys are only loaded by development key signer
+	if conf.Blockchain.Account.Signer == "key" {
+		for _, pk := range conf.Blockchain.Account.PoolKeys {
+			key, _err := signer.NewKey(pk)
+			if _err != nil {
+				err = fmt.Errorf("pool key: %w", _err)
+
+				return
+			}
+
+			_, err = provider.AddSigner(ctx, key)
 			if err != nil {
-				log.Fatal(err)
+				err = fmt.Errorf("add pool wallet: %w", err)
+
+				return
 			}
 		}
 	}
 
+	// repository setup
+	repository, err := Repository(conf)
+	if err != nil {
+		return
+	}
+
 	// new tradecase
+	minNetProfit, ok := new(big.Int).SetString(conf.Trader.MinNet, 10)
+	if !ok {
+		err = fmt.Errorf("invalid trader min net profit %s", conf.Trader.MinNet)
+
+		return
+	}
+
+	tradeOpts := []trade.Option{
+		trade.WETH(conf.Contract.Input),
+		trade.MinNetProfit(minNetProfit),
+		trade.Routes(routes.NewFinder(
+			routes.MaxHops(conf.Routes.MaxHops),
+			routes.Limit(conf.Routes.Limit),
+		)),
+	}
+
+	if conf.Relay.Url != "" {
+		key, _err := crypto.HexToECDSA(conf.Relay.SigningKey)
+		if _err != nil {
+			err = fmt.Errorf("invalid relay signing key: %w", _err)
+
+			return
+		}
+		tradeOpts = append(tradeOpts, trade.Relay(
+			bundle.NewRelay(conf.Relay.Url, key),
+			conf.Relay.Window,
+		))
+	}
+
 	tc := trade.New(
 		repository,
 		provider,
 		ctr,
+		tradeOpts...,
 	)
 
 	// Parsecase
 
 	// new parser with protocol
 	p := parser.NewParser()
-	p.AddProtocol(entities.SwapProtocol{
-		Name:       "Uniswap-V2",
-		Factory:    "0x5C69bEe701ef814a2B6a3EDD4B1652CB9cc5aA6f",
-		SwapRouter: "0x68b3465833fb72A70ecDF485E0e4C7bD8665Fc45",
-	})
-	p.AddProtocol(entities.SwapProtocol{
-		ID:         1,
-		Name:       "Sushiswap-V2",
-		Factory:    "0xc35DADB65012eC5796536bD9864eD8773aBc74C4",
-		SwapRouter: "0x1b02dA8Cb0d097eB8D57A175b88c7D8b47997506",
-	})
-
-	// name: "Uniswap-V3"
-	// factory: "0x1F98431c8aD98523631AE4a59f267346ea31F984"
-	// router: "0xE592427A0AEce92De3Edee1F18E0157C05861564"
+	for _, sp := range []entities.SwapProtocol{
+		{
+			Name:         "Uniswap-V2",
+			Factory:      "0x5C69bEe701ef814a2B6a3EDD4B1652CB9cc5aA6f",
+			SwapRouter:   "0x7a250d5630B4cF539739dF2C5dAcb4c659F2488D",
+			InitCodeHash: "0x96e8ac4277198ff8b6f785478aa9a39f403cb768dd02cbee326c3e7da348845f",
+			FeeBps:       30,
+			Family:       entities.FamilyUniV2,
+		},
+		{
+			ID:           1,
+			Name:         "Sushiswap-V2",
+			Factory:      "0xc35DADB65012eC5796536bD9864eD8773aBc74C4",
+			SwapRouter:   "0x1b02dA8Cb0d097eB8D57A175b88c7D8b47997506",
+			InitCodeHash: "0xe18a34eb0e04b04f7a0ac29a6e80748dca96319b42c54d679cb821dca90c6303",
+			FeeBps:       30,
+			Family:       entities.FamilyUniV2,
+		},
+		// v3 pools are resolved for every fee tier
+		{
+			Name:         "Uniswap-V3",
+			Factory:      "0x1F98431c8aD98523631AE4a59f267346ea31F984",
+			SwapRouter:   "0xE592427A0AEce92De3Edee1F18E0157C05861564",
+			InitCodeHash: "0xe34f199b19b2b4f47f68442619d555527d244f78a3297ea89325f843f87b8b54",
+			Family:       entities.FamilyUniV3,
+		},
+	} {
+		err = p.AddProtocol(sp)
+		if err != nil {
+			err = fmt.Errorf("add protocol: %w", err)
+
+			return
+		}
+	}
 
 	// parsecase create
+	parseOpts := []trade.ParseOption{
+		trade.Discovery(
+			discovery.NewScanner(
+				cl.Client,
+				discovery.Chunk(conf.Discovery.Chunk),
+			),
+			conf.Discovery.StartBlock,
+		),
+		trade.Metadata(erc20.NewFetcher(cl.RPC(), erc20.DefaultBatchSize)),
+	}
+
+	if conf.Validation.Enabled {
+		minLiquidity, ok := new(big.Int).SetString(conf.Validation.MinLiquidity, 10)
+		if !ok {
+			err = fmt.Errorf("invalid validation min liquidity %s", conf.Validation.MinLiquidity)
+
+			return
+		}
+
+		base := conf.Validation.BaseToken
+		if base == "" {
+			base = conf.Contract.Input
+		}
+
+		parseOpts = append(parseOpts, trade.Validation(
+			reserves.NewLoader(cl.RPC(), reserves.DefaultBatchSize),
+			base,
+			minLiquidity,
+		))
+	}
+
 	pc := trade.NewParseCase(
 		repository,
 		p,
+		parseOpts...,
 	)
 
-	// logger
+	// protocols added over api
+	err = pc.LoadProtocols(ctx)
+	if err != nil {
+		return
+	}
+
 	l := logger.New(conf.Log.Level)
 
+	// stored addresses to checksum form, pairs to token0 < token1,
+	// once for every command reading storage
+	migrated, err := pc.Normalize(ctx)
+	if err != nil {
+		err = fmt.Errorf("normalize storage: %w", err)
+
+		return
+	}
+	if migrated.Changed() {
+		l.Info(
+			"app - New - normalized storage: %+v",
+			migrated,
+		)
+	}
+
+	a = &App{
+		Trade:  tc,
+		Parse:  pc,
+		Logger: l,
+	}
+
+	return
+}
+
+// Repository opens storage of config
+func Repository(conf *config.Config) (
+	repository trade.Repository,
+	err error,
+) {
+	switch conf.Storage.Type {
+	case "localfile":
+		files := map[string]string{
+			"pools": fmt.Sprintf(
+				"%s/pools.json",
+				conf.Storage.Localstorage.Path,
+			),
+			"tokens": fmt.Sprintf(
+				"%s/tokens.json",
+				conf.Storage.Localstorage.Path,
+			),
+			"transactions": fmt.Sprintf(
+				"%s/transactions.json",
+				conf.Storage.Localstorage.Path,
+			),
+			"checkpoints": fmt.Sprintf(
+				"%s/checkpoints.json",
+				conf.Storage.Localstorage.Path,
+			),
+			"routes": fmt.Sprintf(
+				"%s/routes.json",
+				conf.Storage.Localstorage.Path,
+			),
+			"protocols": fmt.Sprintf(
+				"%s/protocols.json",
+				conf.Storage.Localstorage.Path,
+			),
+		}
+		repository, err = repo.NewStorage(files)
+	case "database":
+		if conf.Storage.Database.Driver != "postgres" {
+			err = fmt.Errorf("unsupported database driver %q", conf.Storage.Database.Driver)
+
+			return
+		}
+		repository, err = repo.New(conf.Database)
+	default:
+		err = fmt.Errorf("unknown storage type %q", conf.Storage.Type)
+	}
+
+	return
+}
+
+// Run serves http api & runs tracker and trader until interrupted
+func Run(conf *config.Config) {
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	a, err := New(ctx, conf)
+	if err != nil {
+		log.Fatal(err)
+	}
+	tc, pc, l := a.Trade, a.Parse, a.Logger
+
 	// http server
 	handler := gin.New()
 	v1.NewRouter(handler, l, *tc, pc)
@@ -138,6 +366,46 @@ func Run(conf *config.Config) {
 		httpserver.Port(conf.HttpServer.Port),
 	)
 
+	// sent transactions tracking
+	tracker := trade.NewTracker(
+		tc,
+		trade.TrackerConfig{
+			Interval:    conf.Tracker.Interval,
+			DropAfter:   conf.Tracker.DropAfter,
+			BumpAfter:   conf.Tracker.BumpAfter,
+			BumpPercent: conf.Tracker.BumpPercent,
+			MaxBumps:    conf.Tracker.MaxBumps,
+		},
+		l,
+	)
+	tracker.Start(ctx)
+
+	// trading loop
+	var traderNotify <-chan error
+
+	var trader *trade.Trader
+	if conf.Trader.Enabled {
+		minProfit, ok := new(big.Int).SetString(conf.Trader.MinProfit, 10)
+		if !ok {
+			log.Fatalf("invalid trader min profit %s", conf.Trader.MinProfit)
+		}
+
+		trader = trade.NewTrader(
+			tc,
+			trade.TraderConfig{
+				Subscribe: conf.Trader.Mode == "subscribe",
+				Interval:  conf.Trader.Interval,
+				MinProfit: minProfit,
+				MaxTrades: conf.Trader.MaxTrades,
+				DryRun:    conf.Trader.DryRun,
+				Private:   conf.Trader.Private,
+			},
+			l,
+		)
+		trader.Start(ctx)
+		traderNotify = trader.Notify()
+	}
+
 	// waiting signal
 	interrupt := make(
 		chan os.Signal,
@@ -158,9 +426,19 @@ func Run(conf *config.Config) {
 			"app - Run - httpServer.Notify: %w",
 			err,
 		))
+	case err = <-traderNotify:
+		l.Error(fmt.Errorf(
+			"app - Run - trader.Notify: %w",
+			err,
+		))
 	}
 
 	// Shutdown
+	if trader != nil {
+		trader.Shutdown()
+	}
+	tracker.Shutdown()
+
 	err = httpServer.Shutdown()
 	if err != nil {
 		l.Error(fmt.Errorf(
@@ -168,79 +446,63 @@ func Run(conf *config.Config) {
 			err,
 		))
 	}
-
-	// err = tc.Trade(ctx)
-	// log.Println(tc)
 }
 
-func IsDeployed(address string) bool {
+// ClientOptions builds ethereum client options from blockchain config
+func ClientOptions(conf config.Blockchain) (
+	opts []ethereum.Option,
+) {
+	var fees ethereum.FeeStrategy = ethereum.LegacyFees{}
 
-	return address != ""
+	if conf.Fees.Mode == "dynamic" {
+		fees = ethereum.NewDynamicFees(
+			conf.Fees.Percentile,
+			conf.Fees.Blocks,
+		)
+	}
+
+	opts = []ethereum.Option{
+		ethereum.Fees(fees),
+		ethereum.GasMargin(conf.Fees.GasMargin),
+		ethereum.WalletSelection(
+			ethereum.Selection(conf.Wallets.Selection),
+		),
+	}
+
+	return
 }
 
-func Deploy(
-	ctx context.Context,
-	cl *ethereum.Client,
-	ctr *contract.Contract,
-	conf *config.Config,
-) (
-	address, txHash string,
-	ap *api.Api,
+// Signer builds signer of account by config, raw key of
+// ACCOUNT_PRIVATE_KEY env is accepted for development only
+func Signer(ctx context.Context, conf config.Account) (
+	s ethereum.Signer,
 	err error,
 ) {
-	b, err := cl.GetNextTransaction(ctx)
+	switch conf.Signer {
+	case "keystore":
+		s, err = signer.NewKeystore(conf.Keystore, conf.PassphraseFile)
+	case "remote":
+		s, err = signer.NewRemote(
+			ctx, conf.SignerUrl,
+			ethereum.ToAddress(conf.Address),
+			conf.SignerMethod,
+		)
+	case "key":
+		log.Print("raw private key signer is for development only")
+
+		s, err = signer.NewKey(conf.PrivateKey)
+	default:
+		err = fmt.Errorf("unknown signer %q", conf.Signer)
+	}
 	if err != nil {
 		return
 	}
-	addr, tx, ap, err := api.DeployApi(
-		b, cl.Client,
-		ethereum.ToAddress(conf.Blockchain.Contract.Input),
-	)
-	address = ethereum.FromAddress(addr)
-	txHash = tx.Hash().Hex()
-
-	return
-}
-
-func Build(conf *config.Config) error {
-	arg := fmt.Sprintf(
-		"contract=%s",
-		conf.Contract.Address,
-	)
-
-	cmd := exec.Command("make", "build", arg)
-	cmd.Stderr = os.Stderr
 
-	err := cmd.Run()
-	if err != nil {
-		return err
+	if conf.Address != "" && s.Address() != ethereum.ToAddress(conf.Address) {
+		err = fmt.Errorf(
+			"signer account %s is not %s", s.Address(), conf.Address,
+		)
 	}
 
-	return nil
+	return
 }
-
-// func Deploy(conf *config.Config) (string, error) {
-// 	arg := fmt.Sprintf(
-// 		"network=%s",
-// 		conf.Blockchain.Name,
-// 	)
-// 	arg1 := fmt.Sprintf(
-// 		"contract=%s",
-// 		conf.Contract.Address,
-// 	)
-
-// 	cmd := exec.Command("make", "deploy", arg, arg1)
-// 	cmd.Stderr = os.Stderr
-
-// 	out, err := cmd.Output()
-// 	if err != nil {
-// 		return "", err
-// 	}
-
-// 	address := string(out)[len(string(out))-43:]
-// 	os.Setenv("CONTRACT_ADDRESS", address)
-
-// 	return address, nil
-// }
-
-func Verify(conf *config.Config) {}
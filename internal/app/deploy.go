@@ -0,0 +1,310 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/antonyuhnovets/flash-loan-arbitrage/config"
+	"github.com/antonyuhnovets/flash-loan-arbitrage/internal/api"
+	"github.com/antonyuhnovets/flash-loan-arbitrage/pkg/ethereum"
+)
+
+var ErrNotOwner = errors.New("account is not contract owner")
+
+// Deployment is a result of FlashBot deployment
+type Deployment struct {
+	Address    string   `json:"address"`
+	TxHash     string   `json:"txHash"`
+	Block      uint64   `json:"block"`
+	GasUsed    uint64   `json:"gasUsed"`
+	Owner      string   `json:"owner"`
+	BaseTokens []string `json:"baseTokens"` // seeded after deployment
+}
+
+// Verification is a result of comparing deployed code with the code
+// bindings deploy with configured WETH
+type Verification struct {
+	Address      string `json:"address"`
+	Match        bool   `json:"match"`
+	CodeHash     string `json:"codeHash"`
+	ExpectedHash string `json:"expectedHash"`
+	Owner        string `json:"owner"`
+}
+
+// Deployer deploys & manages FlashBot contract from configured account,
+// every sent tx is waited to be mined
+type Deployer struct {
+	cl   *ethereum.Client
+	weth common.Address
+}
+
+func NewDeployer(ctx context.Context, conf *config.Config) (
+	d *Deployer,
+	err error,
+) {
+	if !common.IsHexAddress(conf.Contract.Input) {
+		err = fmt.Errorf("invalid WETH address %q", conf.Contract.Input)
+
+		return
+	}
+
+	cl, err := ethereum.NewClient(
+		conf.Blockchain.Url,
+		ClientOptions(conf.Blockchain)...,
+	)
+	if err != nil {
+		return
+	}
+
+	s, err := Signer(ctx, conf.Blockchain.Account)
+	if err != nil {
+		return
+	}
+
+	err = cl.Setup(ctx, ethereum.NewWallet(s))
+	if err != nil {
+		return
+	}
+
+	d = &Deployer{
+		cl:   cl,
+		weth: ethereum.ToAddress(conf.Contract.Input),
+	}
+
+	return
+}
+
+// Deploy deploys FlashBot with WETH as constructor argument & adds
+// base tokens, WETH is base token from the start
+func (d *Deployer) Deploy(ctx context.Context, baseTokens ...string) (
+	dep Deployment,
+	err error,
+) {
+	var (
+		addr common.Address
+		ap   *api.Api
+	)
+
+	tx, err := d.cl.Transact(ctx, func(b *bind.TransactOpts) (
+		tx *types.Transaction, err error,
+	) {
+		addr, tx, ap, err = api.DeployApi(b, d.cl.Backend(), d.weth)
+
+		return
+	})
+	if err != nil {
+		return
+	}
+	dep.TxHash = tx.Hash().Hex()
+
+	receipt, err := d.cl.WaitMined(ctx, tx)
+	if err != nil {
+		return
+	}
+	dep.Address = addr.Hex()
+	dep.Block = receipt.BlockNumber.Uint64()
+	dep.GasUsed = receipt.GasUsed
+
+	code, err := d.cl.Client.CodeAt(ctx, addr, nil)
+	if err != nil {
+		return
+	}
+	if len(code) == 0 {
+		err = fmt.Errorf("no code at %s, bindings may lack bytecode", addr)
+
+		return
+	}
+
+	owner, err := ap.Owner(ethereum.CallOpts(ctx))
+	if err != nil {
+		return
+	}
+	dep.Owner = owner.Hex()
+
+	dep.BaseTokens, err = d.addBaseTokens(ctx, ap, baseTokens)
+
+	return
+}
+
+// addBaseTokens adds tokens which contract doesn't hold yet
+func (d *Deployer) addBaseTokens(
+	ctx context.Context,
+	ap *api.Api,
+	tokens []string,
+) (
+	added []string,
+	err error,
+) {
+	for _, token := range tokens {
+		if !common.IsHexAddress(token) {
+			err = fmt.Errorf("invalid base token address %q", token)
+
+			return
+		}
+		addr := ethereum.ToAddress(token)
+
+		ok, _err := ap.BaseTokensContains(ethereum.CallOpts(ctx), addr)
+		if _err != nil {
+			err = _err
+
+			return
+		}
+		if ok {
+			continue
+		}
+
+		tx, _err := d.cl.Transact(ctx, func(b *bind.TransactOpts) (
+			*types.Transaction, error,
+		) {
+			return ap.AddBaseToken(b, addr)
+		})
+		if _err != nil {
+			err = fmt.Errorf("add base token %s: %w", addr, _err)
+
+			return
+		}
+
+		_, err = d.cl.WaitMined(ctx, tx)
+		if err != nil {
+			err = fmt.Errorf("add base token %s: %w", addr, err)
+
+			return
+		}
+		added = append(added, addr.Hex())
+	}
+
+	return
+}
+
+// TransferOwnership hands contract over to new owner, only current
+// owner may do it
+func (d *Deployer) TransferOwnership(
+	ctx context.Context,
+	address, newOwner string,
+) (
+	txHash string,
+	err error,
+) {
+	if !common.IsHexAddress(newOwner) {
+		err = fmt.Errorf("invalid owner address %q", newOwner)
+
+		return
+	}
+
+	ap, err := d.bind(address)
+	if err != nil {
+		return
+	}
+
+	owner, err := ap.Owner(ethereum.CallOpts(ctx))
+	if err != nil {
+		return
+	}
+	if owner != d.cl.Wallet.Address {
+		err = fmt.Errorf("%w: owner is %s", ErrNotOwner, owner)
+
+		return
+	}
+
+	tx, err := d.cl.Transact(ctx, func(b *bind.TransactOpts) (
+		*types.Transaction, error,
+	) {
+		return ap.TransferOwnership(b, ethereum.ToAddress(newOwner))
+	})
+	if err != nil {
+		return
+	}
+	txHash = tx.Hash().Hex()
+
+	_, err = d.cl.WaitMined(ctx, tx)
+
+	return
+}
+
+// Verify compares code at address with code bindings deploy
+// with configured WETH, creation is simulated by call
+func (d *Deployer) Verify(ctx context.Context, address string) (
+	v Verification,
+	err error,
+) {
+	ap, err := d.bind(address)
+	if err != nil {
+		return
+	}
+	addr := ethereum.ToAddress(address)
+	v.Address = addr.Hex()
+
+	code, err := d.cl.Client.CodeAt(ctx, addr, nil)
+	if err != nil {
+		return
+	}
+	if len(code) == 0 {
+		err = fmt.Errorf("no code at %s", addr)
+
+		return
+	}
+
+	creation, err := d.creationCode()
+	if err != nil {
+		return
+	}
+	expected, err := d.cl.DeployedCode(ctx, creation)
+	if err != nil {
+		err = fmt.Errorf("simulate deployment: %w", err)
+
+		return
+	}
+
+	v.CodeHash = crypto.Keccak256Hash(code).Hex()
+	v.ExpectedHash = crypto.Keccak256Hash(expected).Hex()
+	v.Match = bytes.Equal(code, expected)
+
+	owner, err := ap.Owner(ethereum.CallOpts(ctx))
+	if err != nil {
+		return
+	}
+	v.Owner = owner.Hex()
+
+	return
+}
+
+// creationCode is bytecode of bindings with packed constructor argument
+func (d *Deployer) creationCode() (
+	code []byte,
+	err error,
+) {
+	parsed, err := api.ApiMetaData.GetAbi()
+	if err != nil {
+		return
+	}
+
+	args, err := parsed.Pack("", d.weth)
+	if err != nil {
+		return
+	}
+	code = append(common.FromHex(api.ApiMetaData.Bin), args...)
+
+	return
+}
+
+func (d *Deployer) bind(address string) (
+	ap *api.Api,
+	err error,
+) {
+	if !common.IsHexAddress(address) {
+		err = fmt.Errorf("invalid contract address %q", address)
+
+		return
+	}
+
+	ap, err = api.NewApi(ethereum.ToAddress(address), d.cl.Backend())
+
+	return
+}
@@ -1,7 +1,11 @@
 package v1
 
 import (
+	"fmt"
+
 	"github.com/antonyuhnovets/flash-loan-arbitrage/internal/entities"
+	"github.com/antonyuhnovets/flash-loan-arbitrage/internal/trade"
+	"github.com/antonyuhnovets/flash-loan-arbitrage/pkg/pairs"
 
 	"github.com/gin-gonic/gin"
 )
@@ -11,6 +15,12 @@ type listPairs struct {
 	Pairs []entities.TradePair `json:"pairs" bson:"pairs"` // list of trade pools
 } //@name ListPairs
 
+// @Description Profitable pairs & pairs rejected with reason
+type loadedPairs struct {
+	Pairs    []entities.TradePair `json:"pairs" bson:"pairs"`       // pairs with net profit above floor
+	Rejected []trade.Estimate     `json:"rejected" bson:"rejected"` // rejected pairs with reason
+} //@name LoadedPairs
+
 // @Description Request list of trading pools
 type listPools struct {
 	Pools []entities.Pool `json:"pools" bson:"pools" gorm:"type:pools"` // list of trade pools
@@ -26,12 +36,111 @@ type listProtocols struct {
 	Protocols []entities.SwapProtocol `json:"protocols" bson:"protocols"` // list of protocols
 } //@name ListProtocols
 
+// @Description List of tracked transactions
+type listTxs struct {
+	Txs []entities.Transaction `json:"txs" bson:"txs"` // transactions sent by bot
+} //@name ListTxs
+
+type parsedPools struct {
+	Pools    []entities.Pool          `json:"pools" bson:"pools"`       // pools passed validation
+	Rejected []entities.PoolRejection `json:"rejected" bson:"rejected"` // pools rejected with reason code
+} //@name ParsedPools
+
+type listDiscovered struct {
+	Factories []trade.Discovered `json:"factories" bson:"factories"` // scan result per factory
+} //@name ListDiscovered
+
+type listFeeMismatches struct {
+	Pairs []pairs.FeeMismatch `json:"pairs" bson:"pairs"` // pairs priced with a wrong fee by contract
+} //@name ListFeeMismatches
+
+// @Description Multi-hop routes ranked from the most profitable
+type listRoutes struct {
+	Routes []entities.TradeRoute `json:"routes" bson:"routes"` // cycles through base tokens
+} //@name ListRoutes
+
+// @Description Pooled wallets trades are sent from
+type listWallets struct {
+	Wallets []entities.Wallet `json:"wallets" bson:"wallets"` // wallets without keys
+} //@name ListWallets
+
+// @Description Request to add wallet to pool
+type addWallet struct {
+	Keystore string `json:"keystore,omitempty" bson:"keystore"` // keystore file name in configured keystore dir, unlocked by configured passphrase
+	Address  string `json:"address,omitempty" bson:"address"`   // account of configured remote signer
+} //@name AddWallet
+
 // @Description Request for searching trade pair
 type tokenPair struct {
 	Protocol  entities.SwapProtocol `json:"protocol" bson:"protocol"`   // trade protocol
 	TokenPair entities.TokenPair    `json:"tokenPair" bson:"tokenPair"` // pair of tokens
 } //@name RequestPairs
 
+// normalize checksums token addresses, invalid ones are rejected
+func (l *listTokens) normalize() error {
+	for i, t := range l.Tokens {
+		if err := t.Validate(); err != nil {
+			return err
+		}
+		l.Tokens[i] = t.Normalize()
+	}
+
+	return nil
+}
+
+// normalize checksums addresses & orders pair tokens of pools
+func (l *listPools) normalize() error {
+	for i, p := range l.Pools {
+		if err := p.Validate(); err != nil {
+			return err
+		}
+		l.Pools[i] = p.Normalize()
+	}
+
+	return nil
+}
+
+func (l *listPairs) normalize() error {
+	for i, tp := range l.Pairs {
+		for _, p := range []entities.Pool{tp.Pool0, tp.Pool1} {
+			if err := p.Validate(); err != nil {
+				return err
+			}
+		}
+		l.Pairs[i] = tp.Normalize()
+	}
+
+	return nil
+}
+
+func (r *tokenPair) normalize() error {
+	if err := r.TokenPair.Validate(); err != nil {
+		return err
+	}
+	r.TokenPair = r.TokenPair.Normalize()
+	r.Protocol = r.Protocol.Normalize()
+
+	return nil
+}
+
+// queryAddresses returns checksummed addresses of query params
+func queryAddresses(c *gin.Context, keys ...string) (
+	out []string,
+	err error,
+) {
+	for _, key := range keys {
+		addr := c.Query(key)
+		if !entities.ValidAddress(addr) {
+			err = fmt.Errorf("invalid %s address %q", key, addr)
+
+			return
+		}
+		out = append(out, entities.Checksum(addr))
+	}
+
+	return
+}
+
 // @Description Response object
 type response struct {
 	Body interface{} `json:"body" bson:"body"` // returned data
@@ -2,6 +2,7 @@ package v1
 
 import (
 	"context"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 
@@ -16,7 +17,8 @@ type parsecaseRoutes struct {
 }
 
 // @Summary     Add Tokens
-// @Description Add list of tokens to storage
+// @Description Add list of tokens to storage, symbol, name & decimals are
+// @Description read from chain. Tokens failing erc-20 calls are rejected
 // @ID          storeTokens
 // @Tags  	    Storage: tokens
 // @Accept      json
@@ -24,6 +26,7 @@ type parsecaseRoutes struct {
 // @Param       request body listTokens true "Add tokens"
 // @Success     201 {object} listTokens
 // @Failure     400 {object} responseErr
+// @Failure     502 {object} responseErr
 // @Failure     507 {object} responseErr
 // @Router      /storage/tokens [post]
 func (pr *parsecaseRoutes) AddTokens(
@@ -46,6 +49,43 @@ func (pr *parsecaseRoutes) AddTokens(
 		return
 	}
 
+	err = tokens.normalize()
+	if err != nil {
+		errorBadRequest(
+			c, err.Error(),
+			Log(
+				pr.l.Error,
+				err,
+				"rest - v1 - AddTokens",
+			),
+		)
+		return
+	}
+
+	tokens.Tokens, err = pr.pc.Enrich(c, tokens.Tokens)
+	if trade.TokenRejected(err) {
+		errorBadRequest(
+			c, err.Error(),
+			Log(
+				pr.l.Error,
+				err,
+				"rest - v1 - AddTokens",
+			),
+		)
+		return
+	}
+	if err != nil {
+		errorBadGateway(
+			c, err.Error(),
+			Log(
+				pr.l.Error,
+				err,
+				"rest - v1 - AddTokens",
+			),
+		)
+		return
+	}
+
 	err = pr.pc.Repository.StoreTokens(c, "tokens", tokens.Tokens)
 	if err != nil {
 		errorInufficientStorage(
@@ -127,6 +167,10 @@ func (pr *parsecaseRoutes) DeleteTokens(
 		return
 	}
 
+	for i, el := range tokens.Tokens {
+		tokens.Tokens[i] = el.Normalize()
+	}
+
 	out, err := pr.pc.Repository.RemoveTokens(
 		c, "tokens", tokens.Tokens,
 	)
@@ -179,6 +223,19 @@ func (pr *parsecaseRoutes) AddPools(
 		return
 	}
 
+	err = pools.normalize()
+	if err != nil {
+		errorBadRequest(
+			c, err.Error(),
+			Log(
+				pr.l.Error,
+				err,
+				"rest - v1 - AddPools",
+			),
+		)
+		return
+	}
+
 	err = pr.pc.Repository.StorePools(c, "pools", pools.Pools)
 	if err != nil {
 		errorInufficientStorage(
@@ -255,6 +312,10 @@ func (pr *parsecaseRoutes) DeletePools(
 		return
 	}
 
+	for i, el := range pools.Pools {
+		pools.Pools[i] = el.Normalize()
+	}
+
 	out, err := pr.pc.Repository.RemovePools(
 		c, "pools", pools.Pools,
 	)
@@ -277,19 +338,22 @@ func (pr *parsecaseRoutes) DeletePools(
 }
 
 // @Summary     Get parsed pools
-// @Description Get pools from current parser
+// @Description Get pools from current parser & pools rejected by
+// @Description validation: no_code, call_failed, token_mismatch,
+// @Description no_base_token, low_liquidity
 // @ID          getParsed
 // @Tags  	    Parse: setup parser
 // @Accept      json
 // @Produce     json
-// @Success     200 {object} listPools
+// @Success     200 {object} parsedPools
 // @Failure     500 {object} responseErr
 // @Router      /parser/pools [get]
 func (pr *parsecaseRoutes) ReadParsed(
 	c *gin.Context,
 ) {
-	pools := listPools{
-		Pools: pr.pc.Parser.ListPools(),
+	pools := parsedPools{
+		Pools:    pr.pc.Parser.ListPools(),
+		Rejected: pr.pc.Parser.ListRejected(),
 	}
 
 	respondOk(c, pools)
@@ -353,8 +417,58 @@ func (pr *parsecaseRoutes) Parse(
 	respondOk(c, listPools{pools})
 }
 
+// @Summary     Discover pools
+// @Description Scan factories of registered protocols for created pools
+// @Description and store them with tokens, every factory resumes from
+// @Description its last scanned block
+// @ID          discover
+// @Tags  	    Parse: core
+// @Accept      json
+// @Produce     json
+// @Param		to query int false "Last block to scan, latest if empty"
+// @Success     200 {object} listDiscovered
+// @Failure     400 {object} responseErr
+// @Failure     503 {object} responseErr
+// @Router      /parser/core/discover [post]
+func (pr *parsecaseRoutes) Discover(
+	c *gin.Context,
+) {
+	var to uint64
+
+	if q := c.Query("to"); q != "" {
+		n, err := strconv.ParseUint(q, 10, 64)
+		if err != nil {
+			errorBadRequest(
+				c, err.Error(),
+				Log(
+					pr.l.Error,
+					err,
+					"rest - v1 - Discover",
+				),
+			)
+			return
+		}
+		to = n
+	}
+
+	res, err := pr.pc.Discover(c, to)
+	if err != nil {
+		errorServiceUnavailable(
+			c, err.Error(),
+			Log(
+				pr.l.Error,
+				err,
+				"rest - v1 - Discover",
+			),
+		)
+		return
+	}
+
+	respondOk(c, listDiscovered{res})
+}
+
 // @Summary     Add protocols
-// @Description Add parse protocols
+// @Description Add parse protocols of known AMM family by factory, router, init code hash & fee
 // @ID          addProtocols
 // @Tags  	    Parse: setup parser
 // @Accept      json
@@ -362,7 +476,6 @@ func (pr *parsecaseRoutes) Parse(
 // @Param       request body listProtocols true "Set protocol"
 // @Success     200 {object} listProtocols
 // @Failure     400 {object} responseErr
-// @Failure     502 {object} responseErr
 // @Router      /parser/protocols [post]
 func (pr *parsecaseRoutes) AddProtocols(
 	c *gin.Context,
@@ -379,12 +492,14 @@ func (pr *parsecaseRoutes) AddProtocols(
 				"rest - v1 - AddProtocol",
 			),
 		)
+
+		return
 	}
 
 	for _, p := range protocols.Protocols {
 		err = pr.pc.AddProtocol(context.Background(), p)
 		if err != nil {
-			errorBadGateway(
+			errorBadRequest(
 				c, err.Error(),
 				Log(
 					pr.l.Error,
@@ -392,11 +507,12 @@ func (pr *parsecaseRoutes) AddProtocols(
 					"rest - v1 - AddProtocol",
 				),
 			)
+
+			return
 		}
 	}
 
 	respondOk(c, protocols)
-
 }
 
 // @Summary     Get protocols
@@ -523,6 +639,10 @@ func NewParserRouter(
 			"/core/parse",
 			pr.Parse,
 		)
+		handler.POST(
+			"/core/discover",
+			pr.Discover,
+		)
 		handler.POST(
 			"/protocols",
 			pr.AddProtocols,
@@ -2,7 +2,10 @@ package v1
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"math/big"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 
@@ -26,6 +29,7 @@ type tradecaseRoutes struct {
 // @Produce     json
 // @Param       request body listPairs true "Add pairs"
 // @Success     201 {object} listPairs
+// @Failure     400 {object} responseErr
 // @Failure     500 {object} responseErr
 // @Router      /contract/pairs [post]
 func (tr *tradecaseRoutes) AddPairs(
@@ -48,6 +52,19 @@ func (tr *tradecaseRoutes) AddPairs(
 		return
 	}
 
+	err = req.normalize()
+	if err != nil {
+		errorBadRequest(
+			c, err.Error(),
+			Log(
+				tr.l.Error,
+				err,
+				"rest - v1 - AddPairs",
+			),
+		)
+		return
+	}
+
 	err = tr.t.Contract.SetPairs(c, req.Pairs)
 	if err != nil {
 		errorInternalServer(
@@ -117,6 +134,19 @@ func (tr *tradecaseRoutes) GetPairs(
 		return
 	}
 
+	err = req.normalize()
+	if err != nil {
+		errorBadRequest(
+			c, err.Error(),
+			Log(
+				tr.l.Error,
+				err,
+				"rest - v1 - GetPairs",
+			),
+		)
+		return
+	}
+
 	lst := listPairs{
 		Pairs: make([]entities.TradePair, 0),
 	}
@@ -168,6 +198,19 @@ func (tr *tradecaseRoutes) AddTokens(c *gin.Context) {
 		return
 	}
 
+	err = req.normalize()
+	if err != nil {
+		errorBadRequest(
+			c, err.Error(),
+			Log(
+				tr.l.Error,
+				err,
+				"rest - v1 - AddTokens",
+			),
+		)
+		return
+	}
+
 	for _, token := range req.Tokens {
 		err := tr.t.Provider.AddToken(c, token)
 		if err != nil {
@@ -203,6 +246,159 @@ func (tr *tradecaseRoutes) ListTokens(
 	respondOk(c, tokens)
 }
 
+// @Summary     List Wallets
+// @Description List pooled wallets with balances & nonces, keys are never returned
+// @ID          listWallets
+// @Tags  	    Provider: wallets
+// @Accept      json
+// @Produce     json
+// @Success     200 {object} listWallets
+// @Failure     502 {object} responseErr
+// @Router      /provider/wallets [get]
+func (tr *tradecaseRoutes) ListWallets(
+	c *gin.Context,
+) {
+	wallets, err := tr.t.Provider.ListWallets(c)
+	if err != nil {
+		errorBadGateway(
+			c, err.Error(),
+			Log(
+				tr.l.Error,
+				err,
+				"rest - v1 - ListWallets",
+			),
+		)
+		return
+	}
+
+	respondOk(c, listWallets{wallets})
+}
+
+// @Summary     Add Wallet
+// @Description Add wallet to pool of trade signers from keystore file or remote signer, raw keys are not accepted
+// @ID          addWallet
+// @Tags  	    Provider: wallets
+// @Accept      json
+// @Produce     json
+// @Param       request body addWallet true "Wallet keystore or remote address"
+// @Success     201 {object} entities.Wallet
+// @Failure     400 {object} responseErr
+// @Failure     409 {object} responseErr
+// @Router      /provider/wallets [post]
+func (tr *tradecaseRoutes) AddWallet(
+	c *gin.Context,
+) {
+	var req addWallet
+
+	err := c.BindJSON(&req)
+	if err != nil {
+		errorBadRequest(
+			c, err.Error(),
+			Log(
+				tr.l.Error,
+				err,
+				"rest - v1 - AddWallet",
+			),
+		)
+		return
+	}
+
+	wallet, err := tr.t.Provider.AddWallet(c, req.Keystore, req.Address)
+	if errors.Is(err, eth.ErrWalletExists) {
+		errorConflict(
+			c, err.Error(),
+			Log(
+				tr.l.Error,
+				err,
+				"rest - v1 - AddWallet",
+			),
+		)
+		return
+	}
+	if err != nil {
+		errorBadRequest(
+			c, err.Error(),
+			Log(
+				tr.l.Error,
+				err,
+				"rest - v1 - AddWallet",
+			),
+		)
+		return
+	}
+
+	respondCreated(c, wallet)
+}
+
+// @Summary     Disable Wallet
+// @Description Exclude wallet from trade signers, its pending txs are still tracked
+// @ID          disableWallet
+// @Tags  	    Provider: wallets
+// @Accept      json
+// @Produce     json
+// @Param		address path string true "Wallet address"
+// @Success     200 {object} entities.Wallet
+// @Failure     400 {object} responseErr
+// @Failure     404 {object} responseErr
+// @Router      /provider/wallets/{address}/disable [put]
+func (tr *tradecaseRoutes) DisableWallet(
+	c *gin.Context,
+) {
+	tr.enableWallet(c, false, "rest - v1 - DisableWallet")
+}
+
+// @Summary     Enable Wallet
+// @Description Include wallet in trade signers again
+// @ID          enableWallet
+// @Tags  	    Provider: wallets
+// @Accept      json
+// @Produce     json
+// @Param		address path string true "Wallet address"
+// @Success     200 {object} entities.Wallet
+// @Failure     400 {object} responseErr
+// @Failure     404 {object} responseErr
+// @Router      /provider/wallets/{address}/enable [put]
+func (tr *tradecaseRoutes) EnableWallet(
+	c *gin.Context,
+) {
+	tr.enableWallet(c, true, "rest - v1 - EnableWallet")
+}
+
+func (tr *tradecaseRoutes) enableWallet(
+	c *gin.Context,
+	enabled bool,
+	msg string,
+) {
+	address := c.Param("address")
+	if !entities.ValidAddress(address) {
+		err := fmt.Errorf("invalid wallet address %q", address)
+		errorBadRequest(
+			c, err.Error(),
+			Log(
+				tr.l.Error,
+				err,
+				msg,
+			),
+		)
+		return
+	}
+
+	wallet, err := tr.t.Provider.EnableWallet(c, address, enabled)
+	if err != nil {
+		errorNotFound(
+			c, err.Error(),
+			Log(
+				tr.l.Error,
+				err,
+				msg,
+			),
+		)
+		return
+	}
+
+	respondOk(c, wallet)
+}
+
 // @Summary     List base Tokens
 // @Description Request base token list from deployed contract memory
 // @ID          getTokens
@@ -262,18 +458,18 @@ func (tr *tradecaseRoutes) LoadTokens(
 }
 
 // @Summary     Load Pairs
-// @Description Load profitable pool pairs from storage
+// @Description Load pool pairs from storage with net profit above floor
 // @ID          loadPairs
 // @Tags  	    Trade: setup case
 // @Accept      json
 // @Produce     json
-// @Success     200 {object} listPairs
+// @Success     200 {object} loadedPairs
 // @Failure     503 {object} responseErr
 // @Router      /trade/pairs [get]
 func (tr *tradecaseRoutes) LoadPairs(
 	c *gin.Context,
 ) {
-	err := tr.t.SetProfitablePairs(c, "pools")
+	rejected, err := tr.t.SetProfitablePairs(c, "pools")
 	if err != nil {
 		errorServiceUnavailable(
 			c, err.Error(),
@@ -286,7 +482,10 @@ func (tr *tradecaseRoutes) LoadPairs(
 		return
 	}
 
-	respondOk(c, tr.t.Contract.ListPairs(c))
+	respondOk(c, loadedPairs{
+		Pairs:    tr.t.Contract.ListPairs(c),
+		Rejected: rejected,
+	})
 }
 
 // @Summary     Add Base Token
@@ -297,6 +496,7 @@ func (tr *tradecaseRoutes) LoadPairs(
 // @Produce     json
 // @Param		token query string true "Add base token"
 // @Success     201 {object} response
+// @Failure     400 {object} responseErr
 // @Failure     503 {object} responseErr
 // @Router      /trade/tokens/base [post]
 func (tr *tradecaseRoutes) AddBase(
@@ -305,11 +505,22 @@ func (tr *tradecaseRoutes) AddBase(
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	addr := c.Query("token")
+	addrs, err := queryAddresses(c, "token")
+	if err != nil {
+		errorBadRequest(
+			c, err.Error(),
+			Log(
+				tr.l.Error,
+				err,
+				"rest - v1 - AddBase",
+			),
+		)
+		return
+	}
 
 	tx, err := tr.t.AddBaseToken(
 		ctx,
-		addr,
+		addrs[0],
 	)
 	if err != nil {
 		errorServiceUnavailable(
@@ -335,6 +546,7 @@ func (tr *tradecaseRoutes) AddBase(
 // @Produce     json
 // @Param		token query string false "Remove base token"
 // @Success     202 {object} response
+// @Failure     400 {object} responseErr
 // @Failure     503 {object} responseErr
 // @Router      /trade/tokens/base [delete]
 func (tr *tradecaseRoutes) RmBase(
@@ -343,11 +555,22 @@ func (tr *tradecaseRoutes) RmBase(
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	addr := c.Query("token")
+	addrs, err := queryAddresses(c, "token")
+	if err != nil {
+		errorBadRequest(
+			c, err.Error(),
+			Log(
+				tr.l.Error,
+				err,
+				"rest - v1 - RmBase",
+			),
+		)
+		return
+	}
 
 	tx, err := tr.t.RmBaseToken(
 		ctx,
-		addr,
+		addrs[0],
 	)
 	if err != nil {
 		errorServiceUnavailable(
@@ -397,7 +620,9 @@ func (tr *tradecaseRoutes) Withdraw(
 }
 
 // @Summary     CheckProfit
-// @Description Find out if trade with given pools is profitable
+// @Description Find out if trade with given pools is profitable after gas,
+// @Description sizing is optimal input of the pair with price impact & slippage
+// @Description computed from reserves & fees of pools
 // @ID          checkProfit
 // @Tags  	    Trade: core
 // @Accept      json
@@ -405,6 +630,7 @@ func (tr *tradecaseRoutes) Withdraw(
 // @Param		pool0 query string true "Swap pool 0"
 // @Param		pool1 query string true "Swap pool 1"
 // @Success     202 {object} response
+// @Failure     400 {object} responseErr
 // @Failure     503 {object} responseErr
 // @Router      /trade/core/profit-check [get]
 func (tr *tradecaseRoutes) CheckProfit(
@@ -413,42 +639,203 @@ func (tr *tradecaseRoutes) CheckProfit(
 	ctx, cancel := context.WithCancel(c)
 	defer cancel()
 
-	pool0 := c.Query("pool0")
-	pool1 := c.Query("pool1")
+	addrs, err := queryAddresses(c, "pool0", "pool1")
+	if err != nil {
+		errorBadRequest(
+			c, err.Error(),
+			Log(
+				tr.l.Error,
+				err,
+				"rest - v1 - CheckProfit",
+			),
+		)
+		return
+	}
 
-	profit, baseToken, err := tr.t.GetProfit(ctx, pool0, pool1)
+	est, err := tr.t.CheckProfit(ctx, addrs[0], addrs[1])
 	if err != nil {
 		errorServiceUnavailable(
 			c, err.Error(),
 			Log(
 				tr.l.Error,
 				err,
-				"rest - v1 - Withdraw",
+				"rest - v1 - CheckProfit",
 			),
 		)
+		return
 	}
-	var res response
-	if profit > 0 {
-		res = response{map[string]string{
-			"profit":     fmt.Sprintln(profit),
-			"base token": baseToken,
-		}}
-	} else {
-		res = response{false}
+
+	respondAccepted(c, response{est})
+}
+
+// @Summary     QuotePair
+// @Description Quote round trip of base token amount through two pools,
+// @Description uniswap-v3 pools are priced with tick math. Pairs with
+// @Description v3 pools can not be executed by contract
+// @ID          quotePair
+// @Tags  	    Trade: core
+// @Accept      json
+// @Produce     json
+// @Param		pool0 query string true "Swap pool 0"
+// @Param		pool1 query string true "Swap pool 1"
+// @Param		amount query string true "Base token amount in wei"
+// @Success     200 {object} response
+// @Failure     400 {object} responseErr
+// @Failure     503 {object} responseErr
+// @Router      /trade/core/quote [get]
+func (tr *tradecaseRoutes) QuotePair(
+	c *gin.Context,
+) {
+	ctx, cancel := context.WithCancel(c)
+	defer cancel()
+
+	amount, ok := new(big.Int).SetString(c.Query("amount"), 10)
+	if !ok || amount.Sign() <= 0 {
+		errorBadRequest(
+			c, "invalid amount",
+			Log(
+				tr.l.Error,
+				fmt.Errorf("invalid amount %q", c.Query("amount")),
+				"rest - v1 - QuotePair",
+			),
+		)
+		return
 	}
 
-	respondAccepted(c, res)
+	addrs, err := queryAddresses(c, "pool0", "pool1")
+	if err != nil {
+		errorBadRequest(
+			c, err.Error(),
+			Log(
+				tr.l.Error,
+				err,
+				"rest - v1 - QuotePair",
+			),
+		)
+		return
+	}
+
+	q, err := tr.t.QuotePair(ctx, addrs[0], addrs[1], amount)
+	if err != nil {
+		errorServiceUnavailable(
+			c, err.Error(),
+			Log(
+				tr.l.Error,
+				err,
+				"rest - v1 - QuotePair",
+			),
+		)
+		return
+	}
+
+	respondOk(c, response{q})
+}
+
+// @Summary     FeeMismatches
+// @Description List stored pool pairs whose swap fee differs from 0.3% assumed
+// @Description by contract, pairs with reverts=true fail on execution
+// @ID          feeMismatches
+// @Tags  	    Trade: core
+// @Accept      json
+// @Produce     json
+// @Success     200 {object} listFeeMismatches
+// @Failure     500 {object} responseErr
+// @Router      /trade/core/fee-mismatch [get]
+func (tr *tradecaseRoutes) FeeMismatches(
+	c *gin.Context,
+) {
+	out, err := tr.t.FeeMismatches(c)
+	if err != nil {
+		errorInternalServer(
+			c, err.Error(),
+			Log(
+				tr.l.Error,
+				err,
+				"rest - v1 - FeeMismatches",
+			),
+		)
+		return
+	}
+
+	respondOk(c, listFeeMismatches{out})
+}
+
+// @Summary     FindRoutes
+// @Description Search cycles of 3 and more swaps through base tokens over stored
+// @Description pools on the latest block, found routes replace stored ones.
+// @Description Routes are candidates, contract executes two pool pairs only
+// @ID          findRoutes
+// @Tags  	    Trade: routes
+// @Accept      json
+// @Produce     json
+// @Success     200 {object} listRoutes
+// @Failure     503 {object} responseErr
+// @Router      /trade/routes/find [post]
+func (tr *tradecaseRoutes) FindRoutes(
+	c *gin.Context,
+) {
+	ctx, cancel := context.WithCancel(c)
+	defer cancel()
+
+	out, err := tr.t.FindRoutes(ctx)
+	if err != nil {
+		errorServiceUnavailable(
+			c, err.Error(),
+			Log(
+				tr.l.Error,
+				err,
+				"rest - v1 - FindRoutes",
+			),
+		)
+		return
+	}
+
+	respondOk(c, listRoutes{out})
+}
+
+// @Summary     ListRoutes
+// @Description Get routes stored by the last search
+// @ID          listRoutes
+// @Tags  	    Trade: routes
+// @Accept      json
+// @Produce     json
+// @Success     200 {object} listRoutes
+// @Failure     500 {object} responseErr
+// @Router      /trade/routes [get]
+func (tr *tradecaseRoutes) ListRoutes(
+	c *gin.Context,
+) {
+	out, err := tr.t.ListRoutes(c)
+	if err != nil {
+		errorInternalServer(
+			c, err.Error(),
+			Log(
+				tr.l.Error,
+				err,
+				"rest - v1 - ListRoutes",
+			),
+		)
+		return
+	}
+
+	respondOk(c, listRoutes{out})
 }
 
 // @Summary     DoArbitrage
-// @Description Call flash arbitrage func from contract with give pools
+// @Description Call flash arbitrage func from contract with give pools,
+// @Description with simulate=true only run it as eth_call from owner
 // @ID          doArbitrage
 // @Tags  	    Trade: core
 // @Accept      json
 // @Produce     json
 // @Param		pool0 query string true "Swap pool 0"
 // @Param		pool1 query string true "Swap pool 1"
+// @Param		simulate query bool false "Simulate without sending tx"
+// @Param		pending query bool false "Simulate against pending state, latest by default"
+// @Success     200 {object} trade.Simulation
 // @Success     202 {object} response
+// @Failure     400 {object} responseErr
+// @Failure     409 {object} responseErr
 // @Failure     502 {object} responseErr
 // @Router      /trade/core/flash-arbitrage [get]
 func (tr *tradecaseRoutes) DoArbitrage(
@@ -457,10 +844,51 @@ func (tr *tradecaseRoutes) DoArbitrage(
 	ctx, cancel := context.WithCancel(c)
 	defer cancel()
 
-	pool0 := c.Query("pool0")
-	pool1 := c.Query("pool1")
+	addrs, err := queryAddresses(c, "pool0", "pool1")
+	if err != nil {
+		errorBadRequest(
+			c, err.Error(),
+			Log(
+				tr.l.Error,
+				err,
+				"rest - v1 - DoArbitrage",
+			),
+		)
+		return
+	}
+	pool0, pool1 := addrs[0], addrs[1]
+
+	if c.Query("simulate") == "true" {
+		sim, err := tr.t.Simulate(ctx, pool0, pool1, c.Query("pending") == "true")
+		if err != nil {
+			errorBadGateway(
+				c, err.Error(),
+				Log(
+					tr.l.Error,
+					err,
+					"rest - v1 - DoArbitrage - simulate",
+				),
+			)
+			return
+		}
+
+		respondOk(c, sim)
+
+		return
+	}
 
 	tx, err := tr.t.Arbitrage(ctx, pool0, pool1)
+	if errors.Is(err, trade.ErrNotProfitable) {
+		errorConflict(
+			c, err.Error(),
+			Log(
+				tr.l.Error,
+				err,
+				"rest - v1 - DoArbitrage",
+			),
+		)
+		return
+	}
 	if err != nil {
 		errorBadGateway(
 			c, err.Error(),
@@ -470,11 +898,82 @@ func (tr *tradecaseRoutes) DoArbitrage(
 				"rest - v1 - DoArbitrage",
 			),
 		)
+		return
 	}
 	res := response{tx}
 	respondAccepted(c, res)
 }
 
+// @Summary     DoArbitrageBundle
+// @Description Send flash arbitrage of given pools privately as relay bundle for next blocks
+// @ID          doArbitrageBundle
+// @Tags  	    Trade: core
+// @Accept      json
+// @Produce     json
+// @Param		pool0 query string true "Swap pool 0"
+// @Param		pool1 query string true "Swap pool 1"
+// @Success     202 {object} bundle.Result
+// @Failure     400 {object} responseErr
+// @Failure     409 {object} responseErr
+// @Failure     502 {object} responseErr
+// @Failure     503 {object} responseErr
+// @Router      /trade/core/flash-arbitrage/bundle [get]
+func (tr *tradecaseRoutes) DoArbitrageBundle(
+	c *gin.Context,
+) {
+	ctx, cancel := context.WithCancel(c)
+	defer cancel()
+
+	addrs, err := queryAddresses(c, "pool0", "pool1")
+	if err != nil {
+		errorBadRequest(
+			c, err.Error(),
+			Log(
+				tr.l.Error,
+				err,
+				"rest - v1 - DoArbitrageBundle",
+			),
+		)
+		return
+	}
+
+	res, err := tr.t.ArbitrageBundle(ctx, addrs[0], addrs[1])
+	switch {
+	case errors.Is(err, trade.ErrNotProfitable):
+		errorConflict(
+			c, err.Error(),
+			Log(
+				tr.l.Error,
+				err,
+				"rest - v1 - DoArbitrageBundle",
+			),
+		)
+		return
+	case errors.Is(err, trade.ErrNoRelay):
+		errorServiceUnavailable(
+			c, err.Error(),
+			Log(
+				tr.l.Error,
+				err,
+				"rest - v1 - DoArbitrageBundle",
+			),
+		)
+		return
+	case err != nil:
+		errorBadGateway(
+			c, err.Error(),
+			Log(
+				tr.l.Error,
+				err,
+				"rest - v1 - DoArbitrageBundle",
+			),
+		)
+		return
+	}
+
+	respondAccepted(c, res)
+}
+
 // @Summary     Replace Tx with add base
 // @Description Replace transaction by hash with add base token tx
 // @ID          replaceTxAdd
@@ -545,6 +1044,168 @@ func (tr *tradecaseRoutes) ReplaceTxRmBase(
 	respondAccepted(c, res)
 }
 
+// @Summary     Get Tx
+// @Description Get tracked transaction status, gas used & revert reason
+// @ID          getTx
+// @Tags  	    Trade: transactions
+// @Accept      json
+// @Produce     json
+// @Param		hash path string true "Tx hash"
+// @Success     200 {object} entities.Transaction
+// @Failure     404 {object} responseErr
+// @Router      /trade/tx/{hash} [get]
+func (tr *tradecaseRoutes) GetTx(
+	c *gin.Context,
+) {
+	tx, err := tr.t.GetTx(c, c.Param("hash"))
+	if err != nil {
+		errorNotFound(
+			c, err.Error(),
+			Log(
+				tr.l.Error,
+				err,
+				"rest - v1 - GetTx",
+			),
+		)
+		return
+	}
+
+	respondOk(c, tx)
+}
+
+// @Summary     List Txs
+// @Description List tracked transactions, optionally filtered by status
+// @ID          listTxs
+// @Tags  	    Trade: transactions
+// @Accept      json
+// @Produce     json
+// @Param		status query string false "pending, mined, reverted or dropped"
+// @Success     200 {object} listTxs
+// @Failure     507 {object} responseErr
+// @Router      /trade/tx [get]
+func (tr *tradecaseRoutes) ListTxs(
+	c *gin.Context,
+) {
+	txs, err := tr.t.ListTxs(c, c.Query("status"))
+	if err != nil {
+		errorInufficientStorage(
+			c, err.Error(),
+			Log(
+				tr.l.Error,
+				err,
+				"rest - v1 - ListTxs",
+			),
+		)
+		return
+	}
+
+	respondOk(c, listTxs{txs})
+}
+
+// @Summary     Speed up Tx
+// @Description Resend pending transaction with the same nonce & bumped fees
+// @ID          speedUpTx
+// @Tags  	    Trade: transactions
+// @Accept      json
+// @Produce     json
+// @Param		hash path string true "Tx hash"
+// @Param		percent query int false "Fee bump percent, at least 10"
+// @Success     202 {object} entities.Transaction
+// @Failure     400 {object} responseErr
+// @Failure     502 {object} responseErr
+// @Router      /trade/tx/{hash}/speed-up [post]
+func (tr *tradecaseRoutes) SpeedUpTx(
+	c *gin.Context,
+) {
+	percent, err := bumpPercent(c)
+	if err != nil {
+		errorBadRequest(
+			c, err.Error(),
+			Log(
+				tr.l.Error,
+				err,
+				"rest - v1 - SpeedUpTx",
+			),
+		)
+		return
+	}
+
+	tx, err := tr.t.SpeedUpTx(c, c.Param("hash"), percent)
+	if err != nil {
+		errorBadGateway(
+			c, err.Error(),
+			Log(
+				tr.l.Error,
+				err,
+				"rest - v1 - SpeedUpTx",
+			),
+		)
+		return
+	}
+
+	respondAccepted(c, tx)
+}
+
+// @Summary     Cancel Tx
+// @Description Replace pending transaction with zero-value self-transfer
+// @ID          cancelTx
+// @Tags  	    Trade: transactions
+// @Accept      json
+// @Produce     json
+// @Param		hash path string true "Tx hash"
+// @Param		percent query int false "Fee bump percent, at least 10"
+// @Success     202 {object} entities.Transaction
+// @Failure     400 {object} responseErr
+// @Failure     502 {object} responseErr
+// @Router      /trade/tx/{hash}/cancel [post]
+func (tr *tradecaseRoutes) CancelTx(
+	c *gin.Context,
+) {
+	percent, err := bumpPercent(c)
+	if err != nil {
+		errorBadRequest(
+			c, err.Error(),
+			Log(
+				tr.l.Error,
+				err,
+				"rest - v1 - CancelTx",
+			),
+		)
+		return
+	}
+
+	tx, err := tr.t.CancelTx(c, c.Param("hash"), percent)
+	if err != nil {
+		errorBadGateway(
+			c, err.Error(),
+			Log(
+				tr.l.Error,
+				err,
+				"rest - v1 - CancelTx",
+			),
+		)
+		return
+	}
+
+	respondAccepted(c, tx)
+}
+
+func bumpPercent(c *gin.Context) (
+	percent uint64,
+	err error,
+) {
+	q := c.Query("percent")
+	if q == "" {
+		percent = eth.MinBumpPercent
+
+		return
+	}
+
+	percent, err = strconv.ParseUint(q, 10, 64)
+
+	return
+}
+
 func NewTradecaseRouter(
 	h *gin.RouterGroup,
 	t trade.TradeCase,
@@ -596,6 +1257,22 @@ func NewProviderRouter(
 			"/tokens",
 			tr.ListTokens,
 		)
+		handler.GET(
+			"/wallets",
+			tr.ListWallets,
+		)
+		handler.POST(
+			"/wallets",
+			tr.AddWallet,
+		)
+		handler.PUT(
+			"/wallets/:address/disable",
+			tr.DisableWallet,
+		)
+		handler.PUT(
+			"/wallets/:address/enable",
+			tr.EnableWallet,
+		)
 	}
 }
 
@@ -613,10 +1290,30 @@ func NewTradeRouter(
 			"/core/profit-check",
 			tr.CheckProfit,
 		)
+		handler.GET(
+			"/core/quote",
+			tr.QuotePair,
+		)
+		handler.GET(
+			"/core/fee-mismatch",
+			tr.FeeMismatches,
+		)
+		handler.POST(
+			"/routes/find",
+			tr.FindRoutes,
+		)
+		handler.GET(
+			"/routes",
+			tr.ListRoutes,
+		)
 		handler.GET(
 			"/core/flash-arbitrage",
 			tr.DoArbitrage,
 		)
+		handler.GET(
+			"/core/flash-arbitrage/bundle",
+			tr.DoArbitrageBundle,
+		)
 		handler.POST(
 			"/replace-tx-add",
 			tr.ReplaceTxAddBase,
@@ -641,5 +1338,21 @@ func NewTradeRouter(
 			"/pairs",
 			tr.LoadPairs,
 		)
+		handler.GET(
+			"/tx",
+			tr.ListTxs,
+		)
+		handler.GET(
+			"/tx/:hash",
+			tr.GetTx,
+		)
+		handler.POST(
+			"/tx/:hash/speed-up",
+			tr.SpeedUpTx,
+		)
+		handler.POST(
+			"/tx/:hash/cancel",
+			tr.CancelTx,
+		)
 	}
 }
@@ -0,0 +1,142 @@
+package entities
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Checksum returns address in EIP-55 form, a string which is not
+// a hex address is only trimmed, so validation can reject it
+func Checksum(address string) string {
+	address = strings.TrimSpace(address)
+	if !common.IsHexAddress(address) {
+		return address
+	}
+
+	return common.HexToAddress(address).Hex()
+}
+
+// ValidAddress reports whether address is 20 bytes hex
+func ValidAddress(address string) bool {
+	return common.IsHexAddress(strings.TrimSpace(address))
+}
+
+// SameAddress reports whether addresses are equal in any case
+func SameAddress(a, b string) bool {
+	return strings.EqualFold(strings.TrimSpace(a), strings.TrimSpace(b))
+}
+
+// LessAddress orders addresses as uint160, the order of uniswap
+// pair tokens. Non hex strings are ordered case-insensitively
+func LessAddress(a, b string) bool {
+	if ValidAddress(a) && ValidAddress(b) {
+		return bytes.Compare(
+			common.HexToAddress(a).Bytes(),
+			common.HexToAddress(b).Bytes(),
+		) < 0
+	}
+
+	return strings.ToLower(strings.TrimSpace(a)) <
+		strings.ToLower(strings.TrimSpace(b))
+}
+
+func (t Token) Normalize() Token {
+	t.Address = Checksum(t.Address)
+
+	return t
+}
+
+func (t Token) Validate() error {
+	if !ValidAddress(t.Address) {
+		return fmt.Errorf("invalid token address %q", t.Address)
+	}
+
+	return nil
+}
+
+// Normalize checksums tokens of pair & orders them token0 < token1
+// as pools & the contract do
+func (p TokenPair) Normalize() TokenPair {
+	p.Token0 = p.Token0.Normalize()
+	p.Token1 = p.Token1.Normalize()
+
+	if LessAddress(p.Token1.Address, p.Token0.Address) {
+		p.Token0, p.Token1 = p.Token1, p.Token0
+		p.Token0ID, p.Token1ID = p.Token1ID, p.Token0ID
+	}
+
+	return p
+}
+
+// Same reports whether pairs hold the same tokens, token amounts
+// & metadata are not compared
+func (p TokenPair) Same(o TokenPair) bool {
+	p, o = p.Normalize(), o.Normalize()
+
+	return SameAddress(p.Token0.Address, o.Token0.Address) &&
+		SameAddress(p.Token1.Address, o.Token1.Address)
+}
+
+func (p TokenPair) Validate() error {
+	for _, t := range []Token{p.Token0, p.Token1} {
+		if err := t.Validate(); err != nil {
+			return err
+		}
+	}
+	if strings.EqualFold(p.Token0.Address, p.Token1.Address) {
+		return fmt.Errorf("identical pair tokens %s", p.Token0.Address)
+	}
+
+	return nil
+}
+
+// Normalize checksums factory & router, empty ones are kept
+func (sp SwapProtocol) Normalize() SwapProtocol {
+	sp.Factory = Checksum(sp.Factory)
+	sp.SwapRouter = Checksum(sp.SwapRouter)
+
+	return sp
+}
+
+// Same reports whether protocols have the same name or factory
+func (sp SwapProtocol) Same(o SwapProtocol) bool {
+	if sp.Name != "" && sp.Name == o.Name {
+		return true
+	}
+
+	return sp.Factory != "" && SameAddress(sp.Factory, o.Factory)
+}
+
+func (p Pool) Normalize() Pool {
+	p.Address = Checksum(p.Address)
+	p.Pair = p.Pair.Normalize()
+	p.Protocol = p.Protocol.Normalize()
+
+	return p
+}
+
+// Same reports whether pools are at the same address
+func (p Pool) Same(o Pool) bool {
+	return SameAddress(p.Address, o.Address)
+}
+
+func (p Pool) Validate() error {
+	if !ValidAddress(p.Address) {
+		return fmt.Errorf("invalid pool address %q", p.Address)
+	}
+	if err := p.Pair.Validate(); err != nil {
+		return fmt.Errorf("pool %s: %w", p.Address, err)
+	}
+
+	return nil
+}
+
+func (tp TradePair) Normalize() TradePair {
+	tp.Pool0 = tp.Pool0.Normalize()
+	tp.Pool1 = tp.Pool1.Normalize()
+
+	return tp
+}
@@ -0,0 +1,61 @@
+package entities
+
+import (
+	"strings"
+	"testing"
+)
+
+const (
+	testWETH = "0xC02aaA39b223FE8D0A0e5C4F27eAD9083C756Cc2"
+	testUSDC = "0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48"
+)
+
+func TestChecksum(t *testing.T) {
+	for _, tc := range []struct{ in, want string }{
+		{strings.ToLower(testWETH), testWETH},
+		{" 0x" + strings.ToUpper(testWETH[2:]) + "\n", testWETH},
+		{"not an address", "not an address"},
+		{"", ""},
+	} {
+		if got := Checksum(tc.in); got != tc.want {
+			t.Errorf("Checksum(%q) = %q, want %q", tc.in, got, tc.want)
+		}
+	}
+
+	if ValidAddress("0x01") || !ValidAddress(strings.ToLower(testUSDC)) {
+		t.Error("address validation")
+	}
+}
+
+func TestPairNormalize(t *testing.T) {
+	// USDC address is lower than WETH
+	pair := TokenPair{
+		Token0:   Token{Name: "WETH", Address: strings.ToLower(testWETH)},
+		Token0ID: 1,
+		Token1:   Token{Name: "USDC", Address: strings.ToLower(testUSDC)},
+		Token1ID: 2,
+	}
+
+	got := pair.Normalize()
+	if got.Token0.Address != testUSDC || got.Token1.Address != testWETH {
+		t.Fatalf("pair %+v", got)
+	}
+	if got.Token0ID != 2 || got.Token0.Name != "USDC" {
+		t.Errorf("token fields not swapped with address %+v", got)
+	}
+	if got.Normalize() != got {
+		t.Error("normalize is not idempotent")
+	}
+
+	reversed := TokenPair{Token0: pair.Token1, Token1: pair.Token0}
+	if reversed.Normalize().Token0 != got.Token0 {
+		t.Error("pair orders differ")
+	}
+
+	if err := (TokenPair{Token0: got.Token0, Token1: got.Token0}).Validate(); err == nil {
+		t.Error("identical tokens accepted")
+	}
+	if err := (Pool{Address: "0x01", Pair: got}).Validate(); err == nil {
+		t.Error("invalid pool address accepted")
+	}
+}
@@ -0,0 +1,147 @@
+package entities
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+)
+
+// Amount is an integer amount of wei or token units, magnitude is at
+// most 2^256-1 as of uint256 on chain. It is json encoded as decimal
+// string & stored as NUMERIC(78,0), 2^256-1 has 78 digits
+type Amount big.Int
+
+var ErrAmountRange = errors.New("amount out of uint256 range")
+
+var maxAmount = new(big.Int).Sub(
+	new(big.Int).Lsh(big.NewInt(1), 256),
+	big.NewInt(1),
+)
+
+// NewAmount copies i, nil stays nil
+func NewAmount(i *big.Int) *Amount {
+	if i == nil {
+		return nil
+	}
+
+	return (*Amount)(new(big.Int).Set(i))
+}
+
+// ParseAmount parses decimal integer
+func ParseAmount(s string) (
+	a *Amount,
+	err error,
+) {
+	i, ok := new(big.Int).SetString(s, 10)
+	if !ok {
+		err = fmt.Errorf("invalid amount %q", s)
+
+		return
+	}
+	if i.CmpAbs(maxAmount) > 0 {
+		err = fmt.Errorf("%w: %s", ErrAmountRange, s)
+
+		return
+	}
+	a = (*Amount)(i)
+
+	return
+}
+
+// Int returns copy of amount, nil amount is 0
+func (a *Amount) Int() *big.Int {
+	if a == nil {
+		return new(big.Int)
+	}
+
+	return new(big.Int).Set((*big.Int)(a))
+}
+
+func (a *Amount) Sign() int {
+	if a == nil {
+		return 0
+	}
+
+	return (*big.Int)(a).Sign()
+}
+
+func (a *Amount) String() string {
+	if a == nil {
+		return "0"
+	}
+
+	return (*big.Int)(a).String()
+}
+
+func (a *Amount) MarshalJSON() ([]byte, error) {
+	if a != nil && (*big.Int)(a).CmpAbs(maxAmount) > 0 {
+		return nil, fmt.Errorf("%w: %s", ErrAmountRange, a)
+	}
+
+	return []byte(strconv.Quote(a.String())), nil
+}
+
+// UnmarshalJSON accepts decimal string or json number,
+// amounts stored before were plain numbers
+func (a *Amount) UnmarshalJSON(b []byte) error {
+	b = bytes.TrimSpace(b)
+	if bytes.Equal(b, []byte("null")) {
+		return nil
+	}
+
+	s := string(b)
+	if len(b) > 0 && b[0] == '"' {
+		var err error
+		if s, err = strconv.Unquote(s); err != nil {
+			return fmt.Errorf("invalid amount %s", b)
+		}
+	}
+	if s == "" {
+		s = "0"
+	}
+
+	p, err := ParseAmount(s)
+	if err != nil {
+		return err
+	}
+	*a = *p
+
+	return nil
+}
+
+// Value stores amount as decimal string of NUMERIC column
+func (a *Amount) Value() (driver.Value, error) {
+	if a == nil {
+		return nil, nil
+	}
+
+	return a.String(), nil
+}
+
+func (a *Amount) Scan(src interface{}) error {
+	var s string
+
+	switch v := src.(type) {
+	case nil:
+		s = "0"
+	case string:
+		s = v
+	case []byte:
+		s = string(v)
+	case int64:
+		s = strconv.FormatInt(v, 10)
+	default:
+		return fmt.Errorf("can't scan %T to amount", src)
+	}
+
+	p, err := ParseAmount(s)
+	if err != nil {
+		return err
+	}
+	*a = *p
+
+	return nil
+}
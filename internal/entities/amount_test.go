@@ -0,0 +1,139 @@
+package entities
+
+import (
+	"encoding/json"
+	"errors"
+	"math/big"
+	"testing"
+)
+
+const (
+	maxUint256    = "115792089237316195423570985008687907853269984665640564039457584007913129639935"
+	maxUint256Inc = "115792089237316195423570985008687907853269984665640564039457584007913129639936"
+)
+
+func TestParseAmount(t *testing.T) {
+	for _, s := range []string{"0", "1", "-1", maxUint256, "-" + maxUint256} {
+		a, err := ParseAmount(s)
+		if err != nil {
+			t.Errorf("ParseAmount(%s): %v", s, err)
+
+			continue
+		}
+		if a.String() != s {
+			t.Errorf("ParseAmount(%s) = %s", s, a)
+		}
+	}
+
+	for _, s := range []string{maxUint256Inc, "-" + maxUint256Inc} {
+		if _, err := ParseAmount(s); !errors.Is(err, ErrAmountRange) {
+			t.Errorf("ParseAmount(%s): %v, expected range error", s, err)
+		}
+	}
+	for _, s := range []string{"", "1.5", "1e18", "0x10", "ten"} {
+		if _, err := ParseAmount(s); err == nil {
+			t.Errorf("ParseAmount(%q) accepted", s)
+		}
+	}
+}
+
+func TestAmountJSON(t *testing.T) {
+	type holder struct {
+		Wei *Amount `json:"wei,omitempty"`
+	}
+
+	max, _ := ParseAmount(maxUint256)
+	b, err := json.Marshal(holder{max})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != `{"wei":"`+maxUint256+`"}` {
+		t.Errorf("marshalled %s", b)
+	}
+
+	var h holder
+	if err = json.Unmarshal(b, &h); err != nil || h.Wei.String() != maxUint256 {
+		t.Errorf("unmarshalled %s, %v", h.Wei, err)
+	}
+
+	// amounts stored as json numbers & empty strings before
+	for in, out := range map[string]string{
+		`{"wei":10000000000}`: "10000000000",
+		`{"wei":""}`:          "0",
+		`{"wei":null}`:        "0",
+		`{}`:                  "0",
+	} {
+		h = holder{}
+		if err = json.Unmarshal([]byte(in), &h); err != nil || h.Wei.String() != out {
+			t.Errorf("unmarshalled %s to %s, %v", in, h.Wei, err)
+		}
+	}
+
+	for _, in := range []string{
+		`{"wei":"` + maxUint256Inc + `"}`,
+		`{"wei":1.5}`,
+		`{"wei":true}`,
+	} {
+		if err = json.Unmarshal([]byte(in), &h); err == nil {
+			t.Errorf("unmarshalled %s", in)
+		}
+	}
+
+	over := new(big.Int).Lsh(big.NewInt(1), 256)
+	if _, err = json.Marshal(holder{NewAmount(over)}); err == nil {
+		t.Error("marshalled amount above uint256")
+	}
+
+	if b, _ = json.Marshal(holder{}); string(b) != `{}` {
+		t.Errorf("nil amount marshalled %s", b)
+	}
+}
+
+func TestAmountSQL(t *testing.T) {
+	max, _ := ParseAmount(maxUint256)
+
+	v, err := max.Value()
+	if err != nil || v != maxUint256 {
+		t.Errorf("value %v, %v", v, err)
+	}
+	if v, _ = (*Amount)(nil).Value(); v != nil {
+		t.Errorf("nil amount value %v", v)
+	}
+
+	for _, src := range []interface{}{maxUint256, []byte(maxUint256)} {
+		var a Amount
+		if err = a.Scan(src); err != nil || a.String() != maxUint256 {
+			t.Errorf("scanned %T to %s, %v", src, &a, err)
+		}
+	}
+
+	var a Amount
+	if err = a.Scan(int64(-42)); err != nil || a.String() != "-42" {
+		t.Errorf("scanned int64 to %s, %v", &a, err)
+	}
+	if err = a.Scan([]byte(maxUint256Inc)); !errors.Is(err, ErrAmountRange) {
+		t.Errorf("scanned amount above uint256: %v", err)
+	}
+	if err = a.Scan(1.5); err == nil {
+		t.Error("scanned float")
+	}
+}
+
+func TestAmountCopy(t *testing.T) {
+	i := big.NewInt(7)
+	a := NewAmount(i)
+	i.SetInt64(8)
+
+	if a.String() != "7" || a.Int().Cmp(big.NewInt(7)) != 0 {
+		t.Errorf("amount shares int %s", a)
+	}
+	a.Int().SetInt64(9)
+	if a.String() != "7" {
+		t.Errorf("Int returned amount itself %s", a)
+	}
+
+	var zero *Amount
+	if zero.Sign() != 0 || zero.String() != "0" || zero.Int().Sign() != 0 || NewAmount(nil) != nil {
+		t.Error("nil amount is not zero")
+	}
+}
@@ -0,0 +1,11 @@
+package entities
+
+import "time"
+
+// Checkpoint is the last block scanned for pools created by factory
+type Checkpoint struct {
+	ID        int       `json:"id" bson:"id" gorm:"column:id;primaryKey;type:integer;autoIncrement:true"`
+	Factory   string    `json:"factory" bson:"factory" gorm:"column:factory;type:varchar(50);uniqueIndex"`
+	Block     uint64    `json:"block" bson:"block" gorm:"column:block;type:bigint"`
+	UpdatedAt time.Time `json:"updatedAt" bson:"updatedAt" gorm:"column:updated_at"`
+}
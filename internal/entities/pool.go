@@ -1,17 +1,28 @@
 package entities
 
 type Token struct {
-	ID      int    `json:"id" bson:"id" gorm:"column:id;primaryKey;type:integer;autoIncrement:true"`
-	Name    string `json:"name" bson:"name" gorm:"column:name;type:varchar(40)"`
-	Address string `json:"address" bson:"address" gorm:"column:address;type:varchar(50)"`
-	Wei     int    `json:"wei" bson:"wei" gorm:"column:wei;type:bigint"`
+	ID       int     `json:"id" bson:"id" gorm:"column:id;primaryKey;type:integer;autoIncrement:true"`
+	Name     string  `json:"name" bson:"name" gorm:"column:name;type:varchar(100)"`
+	Address  string  `json:"address" bson:"address" gorm:"column:address;type:varchar(50)"`
+	Wei      *Amount `json:"wei" bson:"wei" gorm:"column:wei;type:numeric(78,0)"`
+	Symbol   string  `json:"symbol,omitempty" bson:"symbol" gorm:"column:symbol;type:varchar(40)"`    // erc-20 symbol read from chain
+	Decimals uint8   `json:"decimals,omitempty" bson:"decimals" gorm:"column:decimals;type:smallint"` // erc-20 decimals read from chain
 }
 
+// AMM families of swap protocols
+const (
+	FamilyUniV2 = "uniswap-v2" // constant product pairs, uniswap-v2 forks
+	FamilyUniV3 = "uniswap-v3" // concentrated liquidity pools with fee tiers
+)
+
 type SwapProtocol struct {
-	ID         int    `json:"id" bson:"id" gorm:"column:id;primaryKey;type:integer;autoIncrement:true"`
-	Name       string `json:"name" bson:"name" gorm:"column:name;type:varchar(40)"`
-	Factory    string `json:"factory" bson:"factory" gorm:"column:factory;type:varchar(50)"`
-	SwapRouter string `json:"router" bson:"router" gorm:"column:router;type:varchar(50)"`
+	ID           int    `json:"id" bson:"id" gorm:"column:id;primaryKey;type:integer;autoIncrement:true"`
+	Name         string `json:"name" bson:"name" gorm:"column:name;type:varchar(40)"`
+	Factory      string `json:"factory" bson:"factory" gorm:"column:factory;type:varchar(50)"`
+	SwapRouter   string `json:"router" bson:"router" gorm:"column:router;type:varchar(50)"`
+	InitCodeHash string `json:"initCodeHash" bson:"initCodeHash" gorm:"column:init_code_hash;type:varchar(66)"` // pool creation code hash used in CREATE2
+	FeeBps       uint32 `json:"feeBps" bson:"feeBps" gorm:"column:fee_bps;type:integer"`                        // swap fee of uniswap-v2 family
+	Family       string `json:"family" bson:"family" gorm:"column:family;type:varchar(20)"`
 }
 
 type TokenPair struct {
@@ -29,6 +40,22 @@ type Pool struct {
 	PairID     int          `json:"-"`
 	Protocol   SwapProtocol `json:"protocol" bson:"protocol" gorm:"foreignKey:ProtocolID;constraint:OnUpdate:CASCADE,OnDelete:SET NULL"`
 	ProtocolID int          `json:"-"`
+	FeeTier    uint32       `json:"feeTier,omitempty" bson:"feeTier" gorm:"column:fee_tier;type:integer"` // uniswap-v3 fee in hundredths of a bip, 0 for v2 pools
+	FeeBps     uint32       `json:"feeBps,omitempty" bson:"feeBps" gorm:"column:fee_bps;type:integer"`    // swap fee in basis points, protocol fee if 0
+}
+
+// Reasons of pool rejection by validation
+const (
+	RejectNoCode       = "no_code"        // no contract at address
+	RejectCallFailed   = "call_failed"    // token0, token1 or reserves call failed
+	RejectTokens       = "token_mismatch" // pool tokens differ from pair
+	RejectLowLiquidity = "low_liquidity"  // base token liquidity below threshold
+)
+
+type PoolRejection struct {
+	Pool   Pool   `json:"pool"`
+	Reason string `json:"reason"`
+	Detail string `json:"detail,omitempty"`
 }
 
 type TradePair struct {
@@ -0,0 +1,21 @@
+package entities
+
+import "time"
+
+// TradeRoute is a cycle of swaps from base token back to it found
+// over the pool graph, amounts are in base token wei
+type TradeRoute struct {
+	ID        int      `json:"id" bson:"id" gorm:"column:id;primaryKey;type:integer;autoIncrement:true"`
+	BaseToken string   `json:"baseToken" bson:"baseToken" gorm:"column:base_token;type:varchar(50)"`
+	Tokens    []string `json:"tokens" bson:"tokens" gorm:"column:tokens;serializer:json"` // base token first & last
+	Pools     []string `json:"pools" bson:"pools" gorm:"column:pools;serializer:json"`    // pool of every hop
+	AmountIn  *Amount  `json:"amountIn" bson:"amountIn" gorm:"column:amount_in;type:numeric(78,0)"`
+	AmountOut *Amount  `json:"amountOut" bson:"amountOut" gorm:"column:amount_out;type:numeric(78,0)"`
+	Profit    *Amount  `json:"profit" bson:"profit" gorm:"column:profit;type:numeric(78,0)"`
+
+	PriceImpact          float64 `json:"priceImpact" bson:"priceImpact" gorm:"column:price_impact"`                              // share of spot rate lost at optimal size
+	BreakEvenSlippageBps float64 `json:"breakEvenSlippageBps" bson:"breakEvenSlippageBps" gorm:"column:break_even_slippage_bps"` // output slippage which erases profit
+
+	Block   uint64    `json:"block" bson:"block" gorm:"column:block;type:bigint"`
+	FoundAt time.Time `json:"foundAt" bson:"foundAt" gorm:"column:found_at"`
+}
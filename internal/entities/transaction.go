@@ -0,0 +1,30 @@
+package entities
+
+import "time"
+
+const (
+	TxPending  = "pending"
+	TxMined    = "mined"
+	TxReverted = "reverted"
+	TxDropped  = "dropped"
+	TxReplaced = "replaced"
+)
+
+type Transaction struct {
+	ID                int       `json:"id" bson:"id" gorm:"column:id;primaryKey;type:integer;autoIncrement:true"`
+	Hash              string    `json:"hash" bson:"hash" gorm:"column:hash;type:varchar(66);uniqueIndex"`
+	Method            string    `json:"method" bson:"method" gorm:"column:method;type:varchar(40)"`
+	From              string    `json:"from" bson:"from" gorm:"column:from_address;type:varchar(50)"`
+	To                string    `json:"to" bson:"to" gorm:"column:to_address;type:varchar(50)"`
+	Nonce             uint64    `json:"nonce" bson:"nonce" gorm:"column:nonce;type:bigint"`
+	Status            string    `json:"status" bson:"status" gorm:"column:status;type:varchar(20)"`
+	SentBlock         uint64    `json:"sentBlock" bson:"sentBlock" gorm:"column:sent_block;type:bigint"`
+	Bumps             int       `json:"bumps" bson:"bumps" gorm:"column:bumps;type:integer"`
+	ReplacedBy        string    `json:"replacedBy,omitempty" bson:"replacedBy" gorm:"column:replaced_by;type:varchar(66)"`
+	Block             uint64    `json:"block,omitempty" bson:"block" gorm:"column:block;type:bigint"`
+	GasUsed           uint64    `json:"gasUsed,omitempty" bson:"gasUsed" gorm:"column:gas_used;type:bigint"`
+	EffectiveGasPrice *Amount   `json:"effectiveGasPrice,omitempty" bson:"effectiveGasPrice" gorm:"column:effective_gas_price;type:numeric(78,0)"` // in wei
+	RevertReason      string    `json:"revertReason,omitempty" bson:"revertReason" gorm:"column:revert_reason;type:text"`
+	SentAt            time.Time `json:"sentAt" bson:"sentAt" gorm:"column:sent_at"`
+	UpdatedAt         time.Time `json:"updatedAt" bson:"updatedAt" gorm:"column:updated_at"`
+}
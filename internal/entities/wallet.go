@@ -0,0 +1,14 @@
+package entities
+
+import "time"
+
+// Wallet is a state of pooled signer, its key is never exposed
+type Wallet struct {
+	Address   string    `json:"address"`
+	Primary   bool      `json:"primary"` // sends owner only calls
+	Enabled   bool      `json:"enabled"` // picked for trades
+	Pending   int       `json:"pending"` // sent txs not confirmed
+	Nonce     uint64    `json:"nonce"`   // pending nonce on chain
+	Balance   *Amount   `json:"balance"` // wei
+	UpdatedAt time.Time `json:"updatedAt"`
+}
@@ -0,0 +1,147 @@
+// Package rpcstub is a json-rpc node of tests answering calls by
+// handler, single & batch requests are served alike
+package rpcstub
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+var (
+	ErrReverted = &Error{Code: -32000, Message: "execution reverted"}
+	ErrNotFound = &Error{Code: -32601, Message: "method not found"}
+)
+
+// Request is one json-rpc call
+type Request struct {
+	ID     json.RawMessage   `json:"id"`
+	Method string            `json:"method"`
+	Params []json.RawMessage `json:"params"`
+}
+
+// Param decodes param at index to v
+func (r Request) Param(index int, v interface{}) error {
+	return json.Unmarshal(r.Params[index], v)
+}
+
+// Error is a json-rpc error of response
+type Error struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+type response struct {
+	Version string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  interface{}     `json:"result,omitempty"`
+	Error   *Error          `json:"error,omitempty"`
+}
+
+// Handler answers a call with result or error, handlers of a node are
+// never called concurrently, so they may change state of stub freely
+type Handler func(req Request) (result interface{}, err *Error)
+
+// Node serves calls by handler & counts them
+type Node struct {
+	mu      sync.Mutex
+	handle  Handler
+	calls   int
+	batches int
+}
+
+// New starts node closed with test, client is connected to it
+func New(t testing.TB, handle Handler) (
+	node *Node,
+	cl *rpc.Client,
+) {
+	node = &Node{handle: handle}
+
+	srv := httptest.NewServer(node)
+	t.Cleanup(srv.Close)
+
+	cl, err := rpc.DialHTTP(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(cl.Close)
+
+	return
+}
+
+func (n *Node) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+
+	if bytes.HasPrefix(bytes.TrimSpace(body), []byte("[")) {
+		var reqs []Request
+		if err = json.Unmarshal(body, &reqs); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+
+			return
+		}
+		n.batches++
+
+		out := make([]response, 0, len(reqs))
+		for _, req := range reqs {
+			out = append(out, n.serve(req))
+		}
+		json.NewEncoder(w).Encode(out)
+
+		return
+	}
+
+	var req Request
+	if err = json.Unmarshal(body, &req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+	json.NewEncoder(w).Encode(n.serve(req))
+}
+
+func (n *Node) serve(req Request) (
+	res response,
+) {
+	n.calls++
+
+	res = response{Version: "2.0", ID: req.ID}
+	res.Result, res.Error = n.handle(req)
+
+	return
+}
+
+// Calls returns number of calls served, batched ones included
+func (n *Node) Calls() int {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	return n.calls
+}
+
+// Batches returns number of batch requests served
+func (n *Node) Batches() int {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	return n.batches
+}
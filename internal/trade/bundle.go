@@ -0,0 +1,93 @@
+package trade
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/antonyuhnovets/flash-loan-arbitrage/pkg/bundle"
+	eth "github.com/antonyuhnovets/flash-loan-arbitrage/pkg/ethereum"
+)
+
+var ErrNoRelay = errors.New("bundle relay is not configured")
+
+// ArbitrageBundle sends flashArbitrage tx privately through relay
+// if net profit is above floor
+func (tc *TradeCase) ArbitrageBundle(ctx context.Context, pool0, pool1 string) (
+	res bundle.Result,
+	err error,
+) {
+	est, err := tc.EstimateNetProfit(ctx, pool0, pool1)
+	if err != nil {
+		return
+	}
+	if !est.Profitable {
+		err = fmt.Errorf("%w: %s", ErrNotProfitable, est.Reason)
+
+		return
+	}
+
+	res, err = tc.arbitrageBundle(ctx, pool0, pool1)
+
+	return
+}
+
+// arbitrageBundle signs flashArbitrage without broadcasting & submits it
+// to relay for next blocks, nonce is released if bundle is not included
+func (tc *TradeCase) arbitrageBundle(ctx context.Context, pool0, pool1 string) (
+	res bundle.Result,
+	err error,
+) {
+	if tc.relay == nil {
+		err = ErrNoRelay
+
+		return
+	}
+
+	auth, err := tc.ethClient(ctx)
+	if err != nil {
+		return
+	}
+
+	head, err := auth.Client.BlockNumber(ctx)
+	if err != nil {
+		return
+	}
+
+	t, err := auth.TransactPooled(ctx, func(b *bind.TransactOpts) (
+		*types.Transaction, error,
+	) {
+		b.NoSend = true
+
+		t, err := tc.Contract.Api().Transactor().FlashArbitrage(
+			b, eth.ToAddress(pool0), eth.ToAddress(pool1),
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		res, err = tc.relay.Submit(
+			ctx, auth.Client, []*types.Transaction{t}, head+1, tc.relayWindow,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		return t, nil
+	})
+	if errors.Is(err, bundle.ErrNotIncluded) {
+		err = nil
+
+		return
+	}
+	if err != nil {
+		return
+	}
+
+	_, err = tc.track(ctx, "flashArbitrage", t)
+
+	return
+}
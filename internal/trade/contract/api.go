@@ -1,10 +1,18 @@
 package contract
 
 import (
+	"bytes"
+	"fmt"
+	"math/big"
+
 	"github.com/antonyuhnovets/flash-loan-arbitrage/internal/api"
+	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/crypto"
 )
 
+var panicSelector = crypto.Keccak256([]byte("Panic(uint256)"))[:4]
+
 type Contract struct {
 	address     string
 	contractApi *api.Api
@@ -56,9 +64,65 @@ func (a *_api) Filterer() *api.ApiFilterer {
 	return &a.a.ApiFilterer
 }
 
+// Pack encodes contract method call data
+func (a *_api) Pack(method string, args ...interface{}) (
+	data []byte,
+	err error,
+) {
+	parsed, err := api.ApiMetaData.GetAbi()
+	if err != nil {
+		return
+	}
+	data, err = parsed.Pack(method, args...)
+
+	return
+}
+
+// RevertReason decodes revert data of contract call:
+// custom error of contract abi, require message or panic code
+func (a *_api) RevertReason(data []byte) (
+	reason string,
+	err error,
+) {
+	parsed, err := api.ApiMetaData.GetAbi()
+	if err != nil {
+		return
+	}
+
+	if len(data) >= 4 {
+		for name, e := range parsed.Errors {
+			if !bytes.Equal(data[:4], e.ID[:4]) {
+				continue
+			}
+			args, _err := e.Unpack(data)
+			if _err != nil {
+				err = _err
+
+				return
+			}
+			reason = fmt.Sprintf("%s%v", name, args)
+
+			return
+		}
+
+		if bytes.Equal(data[:4], panicSelector) && len(data) >= 36 {
+			code := new(big.Int).SetBytes(data[4:36])
+			reason = fmt.Sprintf("panic: 0x%x", code)
+
+			return
+		}
+	}
+
+	reason, err = abi.UnpackRevert(data)
+
+	return
+}
+
 type API interface {
 	Api() *api.Api
 	Transactor() *api.ApiTransactor
 	Caller() *api.ApiCaller
 	Filterer() *api.ApiFilterer
+	Pack(string, ...interface{}) ([]byte, error)
+	RevertReason([]byte) (string, error)
 }
@@ -0,0 +1,49 @@
+package contract
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+func TestRevertReason(t *testing.T) {
+	a := &_api{}
+
+	tests := []struct {
+		data     string
+		expected string
+	}{
+		{
+			// Error("No base token in pair")
+			data: "0x08c379a0" +
+				"0000000000000000000000000000000000000000000000000000000000000020" +
+				"0000000000000000000000000000000000000000000000000000000000000015" +
+				"4e6f206261736520746f6b656e20696e20706169720000000000000000000000",
+			expected: "No base token in pair",
+		},
+		{
+			// Panic(0x11), arithmetic overflow
+			data: "0x4e487b71" +
+				"0000000000000000000000000000000000000000000000000000000000000011",
+			expected: "panic: 0x11",
+		},
+	}
+
+	for _, test := range tests {
+		reason, err := a.RevertReason(hexutil.MustDecode(test.data))
+		if err != nil {
+			t.Errorf("decode %s: %s", test.data, err)
+
+			continue
+		}
+		if reason != test.expected {
+			t.Errorf("reason %q, expected %q", reason, test.expected)
+		}
+	}
+
+	_, err := a.RevertReason(common.Hex2Bytes("deadbeef"))
+	if err == nil {
+		t.Errorf("expected error on unknown revert data")
+	}
+}
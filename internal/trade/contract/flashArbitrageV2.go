@@ -3,6 +3,7 @@ package contract
 import (
 	c "context"
 	"fmt"
+	"strings"
 
 	"github.com/antonyuhnovets/flash-loan-arbitrage/internal/entities"
 	"github.com/antonyuhnovets/flash-loan-arbitrage/pkg/pairs"
@@ -185,10 +186,10 @@ func (fc *FlashArbContract) containPair(
 	ok = false
 
 	for n, pair := range fc.tradePairs {
-		if (pair.Pool0.Address == pool0 &&
-			pair.Pool1.Address == pool1) ||
-			(pair.Pool0.Address == pool1 &&
-				pair.Pool1.Address == pool0) {
+		if (strings.EqualFold(pair.Pool0.Address, pool0) &&
+			strings.EqualFold(pair.Pool1.Address, pool1)) ||
+			(strings.EqualFold(pair.Pool0.Address, pool1) &&
+				strings.EqualFold(pair.Pool1.Address, pool0)) {
 			index = n
 			ok = true
 
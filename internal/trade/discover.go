@@ -0,0 +1,295 @@
+package trade
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/antonyuhnovets/flash-loan-arbitrage/internal/entities"
+	"github.com/antonyuhnovets/flash-loan-arbitrage/pkg/discovery"
+	"github.com/antonyuhnovets/flash-loan-arbitrage/pkg/pairs"
+)
+
+const (
+	_defaultCheckpointTable = "checkpoints"
+	_defaultTokensTable     = "tokens"
+)
+
+var (
+	ErrNoScanner    = errors.New("pool discovery is not configured")
+	ErrNoStartBlock = errors.New("start block of factory without checkpoint is not set")
+)
+
+// Discovered is a result of scanning factory of one protocol
+type Discovered struct {
+	Protocol string `json:"protocol"`
+	Factory  string `json:"factory"`
+	From     uint64 `json:"from"`
+	To       uint64 `json:"to"`
+	Pools    int    `json:"pools"`   // new pools stored
+	Tokens   int    `json:"tokens"`  // new tokens stored
+	Skipped  int    `json:"skipped"` // pools of tokens failing erc-20 calls
+	Error    string `json:"error,omitempty"`
+}
+
+// Discover scans factories of registered protocols for created pools
+// up to block to, latest if 0. Every factory continues from its
+// checkpoint, which is moved after each stored chunk
+func (pc *ParseCase) Discover(
+	ctx context.Context,
+	to uint64,
+) (
+	out []Discovered,
+	err error,
+) {
+	if pc.scanner == nil {
+		err = ErrNoScanner
+
+		return
+	}
+
+	if to == 0 {
+		to, err = pc.scanner.Head(ctx)
+		if err != nil {
+			return
+		}
+	}
+
+	known, err := pc.knownAddresses(ctx)
+	if err != nil {
+		return
+	}
+
+	for _, proto := range pc.ListProtocols() {
+		if proto.Factory == "" {
+			continue
+		}
+
+		res, _err := pc.discover(ctx, proto, to, known)
+		if _err != nil {
+			if ctx.Err() != nil {
+				err = ctx.Err()
+
+				return
+			}
+			res.Error = _err.Error()
+		}
+		out = append(out, res)
+	}
+
+	return
+}
+
+func (pc *ParseCase) discover(
+	ctx context.Context,
+	proto entities.SwapProtocol,
+	to uint64,
+	known *knownAddresses,
+) (
+	res Discovered,
+	err error,
+) {
+	res = Discovered{
+		Protocol: proto.Name,
+		Factory:  proto.Factory,
+		From:     pc.startBlock,
+	}
+
+	cp, err := pc.Repository.GetCheckpoint(
+		ctx, _defaultCheckpointTable, proto.Factory,
+	)
+	if err != nil {
+		return
+	}
+	if cp.Block > 0 && cp.Block+1 > res.From {
+		res.From = cp.Block + 1
+	}
+	if res.From == 0 {
+		// scan from genesis costs millions of empty eth_getLogs
+		err = ErrNoStartBlock
+
+		return
+	}
+	res.To = res.From
+	if res.From > to {
+		return
+	}
+
+	err = pc.scanner.Scan(ctx, proto.Factory, res.From, to,
+		func(last uint64, created []discovery.Created) (
+			err error,
+		) {
+			pools, tokens, skipped, err := pc.storeCreated(ctx, proto, created, known)
+			if err != nil {
+				return
+			}
+			res.Pools += pools
+			res.Tokens += tokens
+			res.Skipped += skipped
+
+			err = pc.Repository.SetCheckpoint(
+				ctx,
+				_defaultCheckpointTable,
+				entities.Checkpoint{
+					Factory:   proto.Factory,
+					Block:     last,
+					UpdatedAt: time.Now().UTC(),
+				},
+			)
+			if err != nil {
+				return
+			}
+			res.To = last
+
+			return
+		},
+	)
+
+	return
+}
+
+// knownAddresses are pools & tokens in repository & tokens rejected
+// during scan, keyed by checksummed address, loaded once per scan
+type knownAddresses struct {
+	pools    map[string]bool
+	tokens   map[string]entities.Token
+	rejected map[string]bool
+}
+
+func (pc *ParseCase) knownAddresses(ctx context.Context) (
+	known *knownAddresses,
+	err error,
+) {
+	pools, err := pc.Repository.ListPools(ctx, _defaultPoolsTable)
+	if err != nil {
+		return
+	}
+	tokens, err := pc.Repository.ListTokens(ctx, _defaultTokensTable)
+	if err != nil {
+		return
+	}
+
+	known = &knownAddresses{
+		pools:    make(map[string]bool, len(pools)),
+		tokens:   make(map[string]entities.Token, len(tokens)),
+		rejected: make(map[string]bool),
+	}
+	for _, pool := range pools {
+		known.pools[entities.Checksum(pool.Address)] = true
+	}
+	for _, token := range tokens {
+		known.tokens[entities.Checksum(token.Address)] = token
+	}
+
+	return
+}
+
+// storeCreated saves pools unknown to repository with their tokens,
+// metadata of new tokens is fetched in one batch. Pools of tokens
+// failing erc-20 calls are skipped
+func (pc *ParseCase) storeCreated(
+	ctx context.Context,
+	proto entities.SwapProtocol,
+	created []discovery.Created,
+	known *knownAddresses,
+) (
+	pools, tokens, skipped int,
+	err error,
+) {
+	var fresh []string
+	queued := make(map[string]bool)
+
+	for _, c := range created {
+		if known.pools[entities.Checksum(c.Pool)] {
+			continue
+		}
+
+		for _, addr := range []string{c.Token0, c.Token1} {
+			addr = entities.Checksum(addr)
+			if _, ok := known.tokens[addr]; ok || known.rejected[addr] || queued[addr] {
+				continue
+			}
+			queued[addr] = true
+			fresh = append(fresh, addr)
+		}
+	}
+
+	tokens, err = pc.storeTokens(ctx, fresh, known)
+	if err != nil {
+		return
+	}
+
+	for _, c := range created {
+		addr := entities.Checksum(c.Pool)
+		if known.pools[addr] {
+			continue
+		}
+
+		t0, ok0 := known.tokens[entities.Checksum(c.Token0)]
+		t1, ok1 := known.tokens[entities.Checksum(c.Token1)]
+		if !ok0 || !ok1 {
+			skipped++
+
+			continue
+		}
+
+		pool := entities.Pool{
+			Address:  c.Pool,
+			Pair:     entities.TokenPair{Token0: t0, Token1: t1},
+			Protocol: proto,
+			FeeTier:  c.FeeTier,
+		}
+		pool.FeeBps = pairs.FeeBps(pool)
+
+		err = pc.Repository.AddPool(ctx, pool, _defaultPoolsTable)
+		if err != nil {
+			return
+		}
+		known.pools[addr] = true
+		pools++
+	}
+
+	return
+}
+
+// storeTokens saves tokens with metadata fetched in one batch,
+// tokens failing erc-20 calls are remembered as rejected
+func (pc *ParseCase) storeTokens(
+	ctx context.Context,
+	addrs []string,
+	known *knownAddresses,
+) (
+	stored int,
+	err error,
+) {
+	if len(addrs) == 0 {
+		return
+	}
+
+	list, errs, err := pc.enrichEach(ctx, addrs)
+	if err != nil {
+		return
+	}
+
+	for i, t := range list {
+		if TokenRejected(errs[i]) {
+			known.rejected[addrs[i]] = true
+
+			continue
+		}
+		if errs[i] != nil {
+			err = errs[i]
+
+			return
+		}
+
+		err = pc.Repository.AddToken(ctx, _defaultTokensTable, t)
+		if err != nil {
+			return
+		}
+		known.tokens[addrs[i]] = t
+		stored++
+	}
+
+	return
+}
@@ -0,0 +1,22 @@
+package trade
+
+import (
+	"context"
+
+	"github.com/antonyuhnovets/flash-loan-arbitrage/pkg/pairs"
+)
+
+// FeeMismatches lists stored pool pairs contract would price with
+// a wrong swap fee, pairs with reverts set fail on execution
+func (tc *TradeCase) FeeMismatches(ctx context.Context) (
+	out []pairs.FeeMismatch,
+	err error,
+) {
+	pools, err := tc.Repo.ListPools(ctx, _defaultPoolsTable)
+	if err != nil {
+		return
+	}
+	out = pairs.FeeMismatches(pools)
+
+	return
+}
@@ -12,9 +12,83 @@ type Repository interface {
 
 	TokenRepo
 
+	TxRepo
+
+	CheckpointRepo
+
+	RouteRepo
+
+	MigrationRepo
+
+	ProtocolRepo
+
 	GetStorage() Storage
 }
 
+// ProtocolRepo keeps protocols added over api
+type ProtocolRepo interface {
+	StoreProtocol(
+		c.Context, string, entities.SwapProtocol,
+	) error
+
+	DropProtocol(
+		c.Context, string, entities.SwapProtocol,
+	) error
+
+	StoredProtocols(
+		c.Context, string,
+	) ([]entities.SwapProtocol, error)
+}
+
+// MigrationRepo rewrites stored entries as a whole
+type MigrationRepo interface {
+	ReplaceTokens(
+		c.Context, string, []entities.Token,
+	) error
+
+	ReplacePools(
+		c.Context, string, []entities.Pool,
+	) error
+}
+
+type RouteRepo interface {
+	StoreRoutes(
+		c.Context, string, []entities.TradeRoute,
+	) error
+
+	ListRoutes(
+		c.Context, string,
+	) ([]entities.TradeRoute, error)
+}
+
+type CheckpointRepo interface {
+	GetCheckpoint(
+		c.Context, string, string,
+	) (entities.Checkpoint, error)
+
+	SetCheckpoint(
+		c.Context, string, entities.Checkpoint,
+	) error
+}
+
+type TxRepo interface {
+	AddTx(
+		c.Context, string, entities.Transaction,
+	) error
+
+	UpdateTx(
+		c.Context, string, entities.Transaction,
+	) error
+
+	GetTx(
+		c.Context, string, string,
+	) (entities.Transaction, error)
+
+	ListTxs(
+		c.Context, string,
+	) ([]entities.Transaction, error)
+}
+
 type TokenRepo interface {
 	StoreTokens(
 		c.Context, string, []entities.Token,
@@ -93,12 +167,28 @@ type TradeProvider interface {
 	ClientManager
 
 	ProviderStorage
+
+	WalletManager
 }
 
 type ClientManager interface {
 	GetClient(c.Context) interface{}
 }
 
+type WalletManager interface {
+	AddWallet(
+		c.Context, string, string,
+	) (entities.Wallet, error)
+
+	ListWallets(
+		c.Context,
+	) ([]entities.Wallet, error)
+
+	EnableWallet(
+		c.Context, string, bool,
+	) (entities.Wallet, error)
+}
+
 type ProviderStorage interface {
 	AddToken(
 		c.Context, entities.Token,
@@ -186,6 +276,12 @@ type ParseStore interface {
 
 	ListPools() []entities.Pool
 
+	Reject(
+		entities.PoolRejection,
+	)
+
+	ListRejected() []entities.PoolRejection
+
 	Clear()
 }
 
@@ -196,5 +292,5 @@ type ProtocolManager interface {
 
 	RemoveProtocol(entities.SwapProtocol) error
 
-	GetPoolAddresses(entities.TokenPair) (map[entities.SwapProtocol]string, error)
+	GetPoolAddresses(entities.TokenPair) ([]entities.Pool, error)
 }
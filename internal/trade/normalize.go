@@ -0,0 +1,151 @@
+package trade
+
+import (
+	"context"
+	"reflect"
+	"time"
+
+	"github.com/antonyuhnovets/flash-loan-arbitrage/internal/entities"
+)
+
+// normalize migration is recorded as a checkpoint of its key, block
+// of the checkpoint is the last version applied
+const (
+	_normalizeMigration = "migration:normalize"
+	_normalizeVersion   = 1
+)
+
+// Normalized is a result of stored entries migration
+type Normalized struct {
+	Tokens          int `json:"tokens"`          // tokens rewritten
+	Pools           int `json:"pools"`           // pools rewritten
+	DuplicateTokens int `json:"duplicateTokens"` // tokens dropped
+	DuplicatePools  int `json:"duplicatePools"`  // pools dropped
+}
+
+// Changed reports whether migration rewrote storage
+func (n Normalized) Changed() bool {
+	return n != Normalized{}
+}
+
+// NormalizeTokens checksums addresses & drops repeated ones, the first
+// entry of address is kept with name, wei & metadata of later ones if it
+// lacks them
+func NormalizeTokens(tokens []entities.Token) (
+	out []entities.Token,
+	changed, dropped int,
+) {
+	out = make([]entities.Token, 0, len(tokens))
+	seen := make(map[string]int, len(tokens))
+
+	for _, t := range tokens {
+		n := t.Normalize()
+		if n != t {
+			changed++
+		}
+
+		i, ok := seen[n.Address]
+		if !ok {
+			seen[n.Address] = len(out)
+			out = append(out, n)
+
+			continue
+		}
+
+		dropped++
+		if out[i].Name == "" {
+			out[i].Name = n.Name
+		}
+		if out[i].Wei.Sign() == 0 {
+			out[i].Wei = n.Wei
+		}
+		if out[i].Symbol == "" {
+			out[i].Symbol, out[i].Decimals = n.Symbol, n.Decimals
+		}
+	}
+
+	return
+}
+
+// NormalizePools checksums addresses, orders pair tokens & drops
+// repeated pool addresses, the first entry of address is kept
+func NormalizePools(pools []entities.Pool) (
+	out []entities.Pool,
+	changed, dropped int,
+) {
+	out = make([]entities.Pool, 0, len(pools))
+	seen := make(map[string]bool, len(pools))
+
+	for _, p := range pools {
+		n := p.Normalize()
+		if !reflect.DeepEqual(n, p) {
+			changed++
+		}
+
+		if seen[n.Address] {
+			dropped++
+
+			continue
+		}
+		seen[n.Address] = true
+		out = append(out, n)
+	}
+
+	return
+}
+
+// Normalize migrates stored tokens & pools to checksummed addresses &
+// canonical pairs, duplicates left by mixed case addresses are dropped.
+// Migration runs once, its version is stored as checkpoint after it
+func (pc *ParseCase) Normalize(
+	ctx context.Context,
+) (
+	n Normalized,
+	err error,
+) {
+	cp, err := pc.Repository.GetCheckpoint(
+		ctx, _defaultCheckpointTable, _normalizeMigration,
+	)
+	if err != nil {
+		return
+	}
+	if cp.Block >= _normalizeVersion {
+		return
+	}
+
+	tokens, err := pc.Repository.ListTokens(ctx, _defaultTokensTable)
+	if err != nil {
+		return
+	}
+	tokens, n.Tokens, n.DuplicateTokens = NormalizeTokens(tokens)
+	if n.Tokens+n.DuplicateTokens > 0 {
+		err = pc.Repository.ReplaceTokens(ctx, _defaultTokensTable, tokens)
+		if err != nil {
+			return
+		}
+	}
+
+	pools, err := pc.Repository.ListPools(ctx, _defaultPoolsTable)
+	if err != nil {
+		return
+	}
+	pools, n.Pools, n.DuplicatePools = NormalizePools(pools)
+	if n.Pools+n.DuplicatePools > 0 {
+		err = pc.Repository.ReplacePools(ctx, _defaultPoolsTable, pools)
+		if err != nil {
+			return
+		}
+	}
+
+	err = pc.Repository.SetCheckpoint(
+		ctx,
+		_defaultCheckpointTable,
+		entities.Checkpoint{
+			Factory:   _normalizeMigration,
+			Block:     _normalizeVersion,
+			UpdatedAt: time.Now().UTC(),
+		},
+	)
+
+	return
+}
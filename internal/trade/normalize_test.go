@@ -0,0 +1,133 @@
+package trade
+
+import (
+	"context"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/antonyuhnovets/flash-loan-arbitrage/internal/entities"
+)
+
+const (
+	testWETH = "0xC02aaA39b223FE8D0A0e5C4F27eAD9083C756Cc2"
+	testUSDC = "0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48"
+	testPool = "0xB4e16d0168e52d35CaCD2c6185b44281Ec28C9Dc"
+)
+
+func TestNormalizeTokens(t *testing.T) {
+	wei := entities.NewAmount(big.NewInt(1e18))
+	tokens := []entities.Token{
+		{Address: strings.ToLower(testWETH)},
+		{Address: testUSDC, Name: "USDC"},
+		{Address: testWETH, Name: "WETH", Wei: wei, Symbol: "WETH", Decimals: 18},
+	}
+
+	out, changed, dropped := NormalizeTokens(tokens)
+	if changed != 1 || dropped != 1 || len(out) != 2 {
+		t.Fatalf("changed %v, dropped %v, tokens %+v", changed, dropped, out)
+	}
+	expected := entities.Token{
+		Address: testWETH, Name: "WETH", Wei: wei, Symbol: "WETH", Decimals: 18,
+	}
+	if out[0] != expected {
+		t.Errorf("duplicate not merged %+v", out[0])
+	}
+
+	if _, changed, dropped = NormalizeTokens(out); changed+dropped != 0 {
+		t.Error("normalized tokens changed")
+	}
+}
+
+func TestNormalizePools(t *testing.T) {
+	pair := entities.TokenPair{
+		Token0: entities.Token{Address: testWETH},
+		Token1: entities.Token{Address: testUSDC},
+	}
+	pools := []entities.Pool{
+		{Address: strings.ToLower(testPool), Pair: pair, FeeBps: 30},
+		{Address: testPool, Pair: pair.Normalize(), FeeBps: 25},
+	}
+
+	out, changed, dropped := NormalizePools(pools)
+	if changed != 1 || dropped != 1 || len(out) != 1 {
+		t.Fatalf("changed %v, dropped %v, pools %+v", changed, dropped, out)
+	}
+	if out[0].Address != testPool || out[0].FeeBps != 30 {
+		t.Errorf("first pool not kept %+v", out[0])
+	}
+	if out[0].Pair.Token0.Address != testUSDC {
+		t.Errorf("pair not canonical %+v", out[0].Pair)
+	}
+}
+
+// normalizeRepo keeps tokens & checkpoints in memory, other methods
+// of repository are not used by migration
+type normalizeRepo struct {
+	Repository
+
+	tokens      []entities.Token
+	checkpoints []entities.Checkpoint
+	replaced    int
+}
+
+func (r *normalizeRepo) ListTokens(context.Context, string) ([]entities.Token, error) {
+	return r.tokens, nil
+}
+
+func (r *normalizeRepo) ReplaceTokens(_ context.Context, _ string, tokens []entities.Token) error {
+	r.tokens = tokens
+	r.replaced++
+
+	return nil
+}
+
+func (r *normalizeRepo) ListPools(context.Context, string) ([]entities.Pool, error) {
+	return nil, nil
+}
+
+func (r *normalizeRepo) GetCheckpoint(_ context.Context, _ string, key string) (
+	cp entities.Checkpoint,
+	err error,
+) {
+	cp.Factory = key
+	for _, c := range r.checkpoints {
+		if c.Factory == key {
+			cp = c
+		}
+	}
+
+	return
+}
+
+func (r *normalizeRepo) SetCheckpoint(_ context.Context, _ string, cp entities.Checkpoint) error {
+	r.checkpoints = append(r.checkpoints, cp)
+
+	return nil
+}
+
+func TestNormalizeOnce(t *testing.T) {
+	repo := &normalizeRepo{
+		tokens: []entities.Token{{Address: strings.ToLower(testWETH)}},
+	}
+	pc := NewParseCase(repo, nil)
+
+	n, err := pc.Normalize(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n.Tokens != 1 || repo.tokens[0].Address != testWETH {
+		t.Fatalf("normalized %+v, tokens %+v", n, repo.tokens)
+	}
+
+	// storage is not read again on later starts
+	repo.tokens = append(repo.tokens, entities.Token{Address: strings.ToLower(testUSDC)})
+
+	n, err = pc.Normalize(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n.Changed() || repo.replaced != 1 {
+		t.Errorf("migration ran again: %+v, replaced %v times", n, repo.replaced)
+	}
+}
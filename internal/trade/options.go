@@ -0,0 +1,78 @@
+package trade
+
+import (
+	"math/big"
+
+	"github.com/antonyuhnovets/flash-loan-arbitrage/pkg/bundle"
+	"github.com/antonyuhnovets/flash-loan-arbitrage/pkg/discovery"
+	"github.com/antonyuhnovets/flash-loan-arbitrage/pkg/erc20"
+	"github.com/antonyuhnovets/flash-loan-arbitrage/pkg/reserves"
+	"github.com/antonyuhnovets/flash-loan-arbitrage/pkg/routes"
+)
+
+// Option -.
+type Option func(*TradeCase)
+
+// WETH sets wrapped native token used to price gas in base tokens
+func WETH(address string) Option {
+	return func(tc *TradeCase) {
+		tc.weth = address
+	}
+}
+
+// MinNetProfit sets floor of profit left after gas, in base token wei
+func MinNetProfit(floor *big.Int) Option {
+	return func(tc *TradeCase) {
+		if floor != nil {
+			tc.minNetProfit = floor
+		}
+	}
+}
+
+// Relay sets private relay used to send arbitrage as bundles
+// retried for window blocks
+func Relay(r *bundle.Relay, window int) Option {
+	return func(tc *TradeCase) {
+		tc.relay = r
+		tc.relayWindow = window
+	}
+}
+
+// Routes sets finder of multi-hop routes
+func Routes(f *routes.Finder) Option {
+	return func(tc *TradeCase) {
+		if f != nil {
+			tc.finder = f
+		}
+	}
+}
+
+// ParseOption -.
+type ParseOption func(*ParseCase)
+
+// Discovery sets scanner of factory logs, factories without
+// checkpoint are scanned from start block
+func Discovery(s *discovery.Scanner, start uint64) ParseOption {
+	return func(pc *ParseCase) {
+		pc.scanner = s
+		pc.startBlock = start
+	}
+}
+
+// Validation checks parsed pools on chain, pools with base token
+// liquidity below min are rejected, zero min disables the threshold
+func Validation(l *reserves.Loader, base string, min *big.Int) ParseOption {
+	return func(pc *ParseCase) {
+		pc.validator = l
+		pc.validationBase = base
+		pc.minLiquidity = min
+	}
+}
+
+// Metadata reads symbol, name & decimals of added tokens from chain,
+// tokens failing erc-20 calls are rejected
+func Metadata(f *erc20.Fetcher) ParseOption {
+	return func(pc *ParseCase) {
+		pc.metadata = f
+	}
+}
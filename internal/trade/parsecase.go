@@ -2,24 +2,47 @@ package trade
 
 import (
 	"context"
+	"fmt"
+	"math/big"
 
 	"github.com/antonyuhnovets/flash-loan-arbitrage/internal/entities"
+	"github.com/antonyuhnovets/flash-loan-arbitrage/pkg/discovery"
+	"github.com/antonyuhnovets/flash-loan-arbitrage/pkg/erc20"
 	"github.com/antonyuhnovets/flash-loan-arbitrage/pkg/pairs"
+	"github.com/antonyuhnovets/flash-loan-arbitrage/pkg/reserves"
 )
 
+// protocols added over api are kept to be loaded on start
+const _defaultProtocolTable = "protocols"
+
 type ParseCase struct {
 	Parser
 	Repository
+
+	scanner    *discovery.Scanner
+	startBlock uint64
+
+	validator      *reserves.Loader
+	validationBase string
+	minLiquidity   *big.Int
+
+	metadata *erc20.Fetcher
 }
 
 func NewParseCase(
 	repo Repository,
 	parse Parser,
+	opts ...ParseOption,
 ) (
 	pc ParseCase,
 ) {
 	pc = ParseCase{
-		parse, repo,
+		Parser:     parse,
+		Repository: repo,
+	}
+
+	for _, opt := range opts {
+		opt(&pc)
 	}
 
 	return
@@ -32,6 +55,16 @@ func (pc *ParseCase) AddProtocol(
 	err error,
 ) {
 	err = pc.Parser.AddProtocol(sp)
+	if err != nil {
+		return
+	}
+
+	err = pc.StoreProtocol(ctx, _defaultProtocolTable, sp)
+	if err != nil {
+		// keep parser in line with what is loaded on start
+		pc.Parser.RemoveProtocol(sp)
+		err = fmt.Errorf("store protocol %s: %w", sp.Name, err)
+	}
 
 	return
 }
@@ -43,10 +76,54 @@ func (pc *ParseCase) RmProtocol(
 	err error,
 ) {
 	err = pc.RemoveProtocol(sp)
+	if err != nil {
+		return
+	}
+
+	err = pc.DropProtocol(ctx, _defaultProtocolTable, sp)
 
 	return
 }
 
+// LoadProtocols adds stored protocols to parser, protocols that are
+// already added are skipped
+func (pc *ParseCase) LoadProtocols(
+	ctx context.Context,
+) (
+	err error,
+) {
+	sps, err := pc.StoredProtocols(ctx, _defaultProtocolTable)
+	if err != nil {
+		return
+	}
+
+	added := pc.Parser.ListProtocols()
+	for _, sp := range sps {
+		if containProtocol(added, sp) {
+			continue
+		}
+
+		err = pc.Parser.AddProtocol(sp)
+		if err != nil {
+			err = fmt.Errorf("load protocol %s: %w", sp.Name, err)
+
+			return
+		}
+	}
+
+	return
+}
+
+func containProtocol(sps []entities.SwapProtocol, sp entities.SwapProtocol) bool {
+	for _, p := range sps {
+		if p.Same(sp) {
+			return true
+		}
+	}
+
+	return false
+}
+
 func (pc *ParseCase) JustParse(
 	ctx context.Context,
 ) (
@@ -97,6 +174,11 @@ func (pc *ParseCase) ParsePairs(
 ) {
 
 	err = pc.Parser.Parse(pairs)
+	if err != nil {
+		return
+	}
+
+	err = pc.Validate(ctx)
 
 	return
 
@@ -138,3 +220,31 @@ func (pc *ParseCase) Clear(
 ) {
 	pc.Parser.Clear()
 }
+
+// Validate rejects parsed pools which are not deployed, don't trade
+// tokens of their pair or lack liquidity, no-op without validator
+func (pc *ParseCase) Validate(
+	ctx context.Context,
+) (
+	err error,
+) {
+	if pc.validator == nil {
+		return
+	}
+
+	_, rejected, err := pc.validator.Validate(
+		ctx,
+		pc.Parser.ListPools(),
+		pc.validationBase,
+		pc.minLiquidity,
+	)
+	if err != nil {
+		return
+	}
+
+	for _, r := range rejected {
+		pc.Parser.Reject(r)
+	}
+
+	return
+}
@@ -0,0 +1,300 @@
+package trade
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/antonyuhnovets/flash-loan-arbitrage/internal/entities"
+	eth "github.com/antonyuhnovets/flash-loan-arbitrage/pkg/ethereum"
+	"github.com/antonyuhnovets/flash-loan-arbitrage/pkg/pairs"
+	"github.com/antonyuhnovets/flash-loan-arbitrage/pkg/reserves"
+)
+
+var ErrNotProfitable = errors.New("trade is not profitable")
+
+// Estimate is a profit of arbitrage left after paying gas
+type Estimate struct {
+	Pool0       string           `json:"pool0"`
+	Pool1       string           `json:"pool1"`
+	BaseToken   string           `json:"baseToken"`
+	Profit      *entities.Amount `json:"profit"`      // gross, in base token
+	Gas         uint64           `json:"gas"`         // estimated gas units
+	GasPrice    *entities.Amount `json:"gasPrice"`    // base fee + tip, in wei
+	GasCost     *entities.Amount `json:"gasCost"`     // in wei
+	GasCostBase *entities.Amount `json:"gasCostBase"` // in base token
+	NetProfit   *entities.Amount `json:"netProfit"`   // in base token, may be negative
+	Profitable  bool             `json:"profitable"`
+	Reason      string           `json:"reason,omitempty"`
+}
+
+// EstimateNetProfit calculates contract profit of pools & subtracts gas
+// cost of flashArbitrage converted to base token
+func (tc *TradeCase) EstimateNetProfit(
+	ctx context.Context,
+	pool0, pool1 string,
+) (
+	est Estimate,
+	err error,
+) {
+	est, err = tc.estimateNetProfit(ctx, pool0, pool1, nil)
+
+	return
+}
+
+// estimateNetProfit prices gas with reserves of prices, e.g. of block
+// trader already loaded, stored WETH pools are read if nil
+func (tc *TradeCase) estimateNetProfit(
+	ctx context.Context,
+	pool0, pool1 string,
+	prices *priceBook,
+) (
+	est Estimate,
+	err error,
+) {
+	est = Estimate{Pool0: pool0, Pool1: pool1}
+
+	res, err := tc.Contract.Api().Caller().GetProfit(
+		eth.CallOpts(ctx),
+		eth.ToAddress(pool0),
+		eth.ToAddress(pool1),
+	)
+	if err != nil {
+		return
+	}
+	est.Profit = entities.NewAmount(res.Profit)
+	est.BaseToken = eth.FromAddress(res.BaseToken)
+
+	if res.Profit.Sign() <= 0 {
+		est.Reason = "no profit before gas"
+
+		return
+	}
+
+	auth, err := tc.ethClient(ctx)
+	if err != nil {
+		return
+	}
+
+	data, err := tc.Contract.Api().Pack(
+		"flashArbitrage",
+		eth.ToAddress(pool0),
+		eth.ToAddress(pool1),
+	)
+	if err != nil {
+		return
+	}
+
+	est.Gas, err = auth.EstimateGas(
+		ctx,
+		eth.ToAddress(tc.Contract.Address()),
+		data,
+	)
+	if err != nil {
+		est.Reason = fmt.Sprintf("gas estimation failed: %s", err)
+		err = nil
+
+		return
+	}
+
+	gasPrice, err := auth.GasPrice(ctx)
+	if err != nil {
+		return
+	}
+	gasCost := new(big.Int).Mul(
+		new(big.Int).SetUint64(est.Gas),
+		gasPrice,
+	)
+	est.GasPrice = entities.NewAmount(gasPrice)
+	est.GasCost = entities.NewAmount(gasCost)
+
+	var gasCostBase *big.Int
+	if prices != nil {
+		gasCostBase, err = prices.inToken(est.BaseToken, gasCost)
+	} else {
+		gasCostBase, err = tc.GasCostIn(ctx, est.BaseToken, gasCost)
+	}
+	if err != nil {
+		est.Reason = fmt.Sprintf("gas pricing failed: %s", err)
+		err = nil
+
+		return
+	}
+	est.GasCostBase = entities.NewAmount(gasCostBase)
+
+	net := new(big.Int).Sub(res.Profit, gasCostBase)
+	est.NetProfit = entities.NewAmount(net)
+
+	if net.Cmp(tc.minNetProfit) < 0 {
+		est.Reason = fmt.Sprintf(
+			"net profit %s below floor %s",
+			net, tc.minNetProfit,
+		)
+
+		return
+	}
+	est.Profitable = true
+
+	return
+}
+
+// GasCostIn converts wei amount to token through the deepest stored
+// WETH/token pool
+func (tc *TradeCase) GasCostIn(
+	ctx context.Context,
+	token string,
+	cost *big.Int,
+) (
+	out *big.Int,
+	err error,
+) {
+	if tc.weth == "" {
+		err = fmt.Errorf("WETH address not set")
+
+		return
+	}
+
+	if eth.ToAddress(token) == eth.ToAddress(tc.weth) {
+		out = new(big.Int).Set(cost)
+
+		return
+	}
+
+	pools, err := tc.Repo.ListPools(ctx, _defaultPoolsTable)
+	if err != nil {
+		return
+	}
+
+	route := wethPools(pools, tc.weth, token)
+	if len(route) == 0 {
+		err = fmt.Errorf("no WETH pool for token %s", token)
+
+		return
+	}
+
+	snap, err := tc.PoolReserves(ctx, route, nil)
+	if err != nil {
+		return
+	}
+
+	out, err = priceBook{weth: tc.weth, snap: snap}.inToken(token, cost)
+
+	return
+}
+
+// priceBook prices tokens in WETH at spot price of the deepest
+// WETH pool of token in reserves of one block
+type priceBook struct {
+	weth string
+	snap *reserves.Snapshot
+}
+
+// inToken converts wei amount to token
+func (pb priceBook) inToken(token string, wei *big.Int) (
+	out *big.Int,
+	err error,
+) {
+	reserveWETH, reserveToken, err := pb.wethReserves(token)
+	if err != nil {
+		return
+	}
+
+	out = new(big.Int).Mul(wei, reserveToken)
+	out.Quo(out, reserveWETH)
+
+	return
+}
+
+// inWETH converts token amount to wei
+func (pb priceBook) inWETH(token string, amount *big.Int) (
+	out *big.Int,
+	err error,
+) {
+	reserveWETH, reserveToken, err := pb.wethReserves(token)
+	if err != nil {
+		return
+	}
+
+	out = new(big.Int).Mul(amount, reserveWETH)
+	out.Quo(out, reserveToken)
+
+	return
+}
+
+// wethReserves returns reserves of the deepest WETH/token pool,
+// WETH is priced 1:1
+func (pb priceBook) wethReserves(token string) (
+	reserveWETH, reserveToken *big.Int,
+	err error,
+) {
+	if pb.weth == "" {
+		err = fmt.Errorf("WETH address not set")
+
+		return
+	}
+
+	weth, tok := eth.ToAddress(pb.weth), eth.ToAddress(token)
+	if weth == tok {
+		reserveWETH, reserveToken = big.NewInt(1), big.NewInt(1)
+
+		return
+	}
+
+	if pb.snap != nil {
+		for _, r := range pb.snap.List() {
+			t0, t1 := eth.ToAddress(r.Token0), eth.ToAddress(r.Token1)
+
+			var rw, rt *big.Int
+			switch {
+			case t0 == weth && t1 == tok:
+				rw, rt = r.Reserve0, r.Reserve1
+			case t1 == weth && t0 == tok:
+				rw, rt = r.Reserve1, r.Reserve0
+			default:
+				continue
+			}
+			if rw.Sign() == 0 || rt.Sign() == 0 {
+				continue
+			}
+			if reserveWETH == nil || rw.Cmp(reserveWETH) > 0 {
+				reserveWETH, reserveToken = rw, rt
+			}
+		}
+	}
+
+	if reserveWETH == nil {
+		err = fmt.Errorf("no WETH liquidity for token %s", token)
+	}
+
+	return
+}
+
+// wethPools keeps uniswap-v2 pools of token & WETH, reserves of v3
+// pools are not loaded by PoolReserves
+func wethPools(
+	pools []entities.Pool,
+	weth, token string,
+) []entities.Pool {
+	return pairs.ExecutablePools(poolsWithTokens(pools, weth, token))
+}
+
+func poolsWithTokens(
+	pools []entities.Pool,
+	tokenA, tokenB string,
+) (
+	out []entities.Pool,
+) {
+	a, b := eth.ToAddress(tokenA), eth.ToAddress(tokenB)
+
+	for _, pool := range pools {
+		t0 := eth.ToAddress(pool.Pair.Token0.Address)
+		t1 := eth.ToAddress(pool.Pair.Token1.Address)
+
+		if (t0 == a && t1 == b) || (t0 == b && t1 == a) {
+			out = append(out, pool)
+		}
+	}
+
+	return
+}
@@ -0,0 +1,34 @@
+package trade
+
+import (
+	"testing"
+
+	"github.com/antonyuhnovets/flash-loan-arbitrage/internal/entities"
+)
+
+func TestWETHPools(t *testing.T) {
+	pair := entities.TokenPair{
+		Token0: entities.Token{Address: testUSDC},
+		Token1: entities.Token{Address: testWETH},
+	}
+	other := entities.TokenPair{
+		Token0: entities.Token{Address: testUSDC},
+		Token1: entities.Token{Address: "0xdAC17F958D2ee523a2206206994597C13D831ec7"},
+	}
+
+	pools := []entities.Pool{
+		{Address: "0x88e6A0c2dDD26FEEb64F039a2c41296FcB3f5640", Pair: pair, FeeTier: 500},
+		{Address: testPool, Pair: pair},
+		{
+			Address:  "0x8ad599c3A0ff1De082011EFDDc58f1908eb6e6D8",
+			Pair:     pair,
+			Protocol: entities.SwapProtocol{Family: entities.FamilyUniV3},
+		},
+		{Address: "0x3041CbD36888bECc7bbCBc0045E3B1f144466f5f", Pair: other},
+	}
+
+	out := wethPools(pools, testWETH, testUSDC)
+	if len(out) != 1 || out[0].Address != testPool {
+		t.Errorf("pools %+v", out)
+	}
+}
@@ -2,47 +2,50 @@ package provider
 
 import (
 	c "context"
+	"errors"
 	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
 
 	"github.com/antonyuhnovets/flash-loan-arbitrage/internal/entities"
 
 	eth "github.com/antonyuhnovets/flash-loan-arbitrage/pkg/ethereum"
+	"github.com/antonyuhnovets/flash-loan-arbitrage/pkg/signer"
 )
 
+var ErrWalletSource = errors.New("wallet needs either keystore or address")
+
 type TradeProvider struct {
 	Client  *eth.Client
-	Wallets []*eth.Wallet
 	Tokens  []entities.Token `json:"tokens"`
+	Signers *signer.Source   `json:"-"` // opens signers of added wallets
 }
 
+// NewTradeProvider connects node, signer is primary wallet
+// which sends owner only calls
 func NewTradeProvider(
 	ctx c.Context,
-	url, pk string,
-	tokens ...entities.Token,
+	url string,
+	signer eth.Signer,
+	opts ...eth.Option,
 ) (
 	provider *TradeProvider,
 	err error,
 ) {
-	cl, err := eth.NewClient(url)
-	if err != nil {
-		return
-	}
-
-	wall := eth.NewWallet()
-	err = wall.Setup(pk)
+	cl, err := eth.NewClient(url, opts...)
 	if err != nil {
 		return
 	}
 
-	err = cl.Setup(ctx, wall)
+	err = cl.Setup(ctx, eth.NewWallet(signer))
 	if err != nil {
 		return
 	}
 
 	provider = &TradeProvider{
-		Client:  cl,
-		Wallets: []*eth.Wallet{wall},
-		Tokens:  tokens,
+		Client: cl,
+		Tokens: make([]entities.Token, 0),
 	}
 
 	return
@@ -57,43 +60,141 @@ func (tp *TradeProvider) GetClient(ctx c.Context) (
 }
 
 func (tp *TradeProvider) Ballance(ctx c.Context) (
-	ball int,
+	ball *entities.Amount,
 	err error,
 ) {
-	ball, err = tp.Client.GetBallance(ctx)
+	b, err := tp.Client.GetBallance(ctx)
+	if err != nil {
+		return
+	}
+	ball = entities.NewAmount(b)
 
 	return
 }
 
-func (tp *TradeProvider) SwitchWallet(addr string) (
+// AddWallet puts wallet to pool of trade signers, it's unlocked from
+// keystore file of name in configured keystore directory or held by
+// remote signer at address. Raw keys are never accepted here
+func (tp *TradeProvider) AddWallet(
+	ctx c.Context,
+	keystore, address string,
+) (
+	wallet entities.Wallet,
 	err error,
 ) {
-	for _, wall := range tp.Wallets {
-		if wall.Address.String() == addr {
-			tp.Client.UseWallet(wall)
+	var s eth.Signer
+
+	switch {
+	case keystore != "" && address == "":
+		s, err = tp.Signers.KeystoreIn(keystore)
+	case address != "" && keystore == "":
+		if !common.IsHexAddress(address) {
+			err = fmt.Errorf("invalid wallet address %q", address)
 
 			return
 		}
+		s, err = tp.Signers.Remote(ctx, eth.ToAddress(address))
+	default:
+		err = ErrWalletSource
+	}
+	if err != nil {
+		return
 	}
-	err = fmt.Errorf("no wallet with address %s", addr)
+
+	wallet, err = tp.AddSigner(ctx, s)
 
 	return
 }
 
-func (tp *TradeProvider) AddWallet(pk string) (
+// AddSigner puts wallet of signer to pool of trade signers
+func (tp *TradeProvider) AddSigner(
+	ctx c.Context,
+	s eth.Signer,
+) (
+	wallet entities.Wallet,
 	err error,
 ) {
-	wall := eth.NewWallet()
-	err = wall.Setup(pk)
+	wall := eth.NewWallet(s)
+	pool := tp.Client.Wallets()
+
+	err = pool.Add(wall)
 	if err != nil {
 		return
 	}
+	_ = pool.Refresh(ctx)
 
-	tp.Wallets = append(tp.Wallets, wall)
+	wallet, err = tp.wallet(wall.Address)
 
 	return
 }
 
+// ListWallets returns pooled wallets with balances & nonces read from chain
+func (tp *TradeProvider) ListWallets(ctx c.Context) (
+	wallets []entities.Wallet,
+	err error,
+) {
+	err = tp.Client.Wallets().Refresh(ctx)
+	if err != nil {
+		return
+	}
+
+	states := tp.Client.Wallets().States()
+
+	wallets = make([]entities.Wallet, len(states))
+	for i, s := range states {
+		wallets[i] = tp.fromState(s)
+	}
+
+	return
+}
+
+// EnableWallet includes wallet in trade signers selection or excludes it,
+// owner only calls are still sent from primary wallet
+func (tp *TradeProvider) EnableWallet(
+	ctx c.Context,
+	address string,
+	enabled bool,
+) (
+	wallet entities.Wallet,
+	err error,
+) {
+	addr := eth.ToAddress(address)
+
+	err = tp.Client.Wallets().SetEnabled(addr, enabled)
+	if err != nil {
+		return
+	}
+
+	wallet, err = tp.wallet(addr)
+
+	return
+}
+
+func (tp *TradeProvider) wallet(addr common.Address) (
+	wallet entities.Wallet,
+	err error,
+) {
+	s, err := tp.Client.Wallets().State(addr)
+	if err != nil {
+		return
+	}
+	wallet = tp.fromState(s)
+
+	return
+}
+
+func (tp *TradeProvider) fromState(s eth.WalletState) entities.Wallet {
+	return entities.Wallet{
+		Address:   s.Address.Hex(),
+		Primary:   tp.Client.Wallet != nil && tp.Client.Wallet.Address == s.Address,
+		Enabled:   s.Enabled,
+		Pending:   s.Pending,
+		Nonce:     s.Nonce,
+		Balance:   entities.NewAmount(s.Balance),
+		UpdatedAt: s.UpdatedAt,
+	}
+}
+
 func (tp *TradeProvider) AddToken(
 	ctx c.Context,
 	token entities.Token,
@@ -205,7 +306,7 @@ func (tp *TradeProvider) containToken(
 	ok = false
 
 	for n, t := range tp.Tokens {
-		if t.Address == address {
+		if strings.EqualFold(t.Address, address) {
 			index = n
 			ok = true
 
@@ -0,0 +1,173 @@
+package trade
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/antonyuhnovets/flash-loan-arbitrage/internal/entities"
+	eth "github.com/antonyuhnovets/flash-loan-arbitrage/pkg/ethereum"
+	"github.com/antonyuhnovets/flash-loan-arbitrage/pkg/pairs"
+	"github.com/antonyuhnovets/flash-loan-arbitrage/pkg/reserves"
+	"github.com/antonyuhnovets/flash-loan-arbitrage/pkg/simulator"
+)
+
+// QuotePair quotes round trip of amountIn base token through two
+// stored pools of any family on the latest block, v3 pools are
+// priced with tick math. Pairs with v3 pools are not executable
+// by contract, quote is informational only
+func (tc *TradeCase) QuotePair(
+	ctx context.Context,
+	pool0, pool1 string,
+	amountIn *big.Int,
+) (
+	quote simulator.Quote,
+	err error,
+) {
+	q0, q1, base, quoteToken, err := tc.pairQuoters(ctx, pool0, pool1)
+	if err != nil {
+		return
+	}
+
+	quote, err = simulator.BestQuote(q0, q1, base, quoteToken, amountIn)
+
+	return
+}
+
+// pairQuoters loads two stored pools of the same tokens on the
+// latest block & finds base token of the pair
+func (tc *TradeCase) pairQuoters(
+	ctx context.Context,
+	pool0, pool1 string,
+) (
+	q0, q1 simulator.Quoter,
+	base, quoteToken string,
+	err error,
+) {
+	pools, err := tc.Repo.ListPools(ctx, _defaultPoolsTable)
+	if err != nil {
+		return
+	}
+
+	p0, err := findPool(pools, pool0)
+	if err != nil {
+		return
+	}
+	p1, err := findPool(pools, pool1)
+	if err != nil {
+		return
+	}
+	if !pairs.CheckPairTokens(entities.TradePair{Pool0: p0, Pool1: p1}, p0.Pair) {
+		err = simulator.ErrNotSameTokenPair
+
+		return
+	}
+
+	baseTokens, err := tc.BaseTokens(ctx)
+	if err != nil {
+		return
+	}
+
+	base, quoteToken = p0.Pair.Token0.Address, p0.Pair.Token1.Address
+	switch {
+	case containsAddress(baseTokens, base):
+	case containsAddress(baseTokens, quoteToken):
+		base, quoteToken = quoteToken, base
+	default:
+		err = simulator.ErrNoBaseToken
+
+		return
+	}
+
+	auth, err := tc.ethClient(ctx)
+	if err != nil {
+		return
+	}
+	loader := reserves.NewLoader(auth.RPC(), reserves.DefaultBatchSize)
+
+	block, err := loader.BlockNumber(ctx)
+	if err != nil {
+		return
+	}
+	number := new(big.Int).SetUint64(block)
+
+	q0, err = poolQuoter(ctx, loader, p0, number)
+	if err != nil {
+		return
+	}
+	q1, err = poolQuoter(ctx, loader, p1, number)
+
+	return
+}
+
+// poolQuoter loads state of pool according to its family
+func poolQuoter(
+	ctx context.Context,
+	loader *reserves.Loader,
+	pool entities.Pool,
+	block *big.Int,
+) (
+	q simulator.Quoter,
+	err error,
+) {
+	if pairs.IsV3(pool) {
+		snap, _err := loader.LoadV3(
+			ctx, []entities.Pool{pool}, block, reserves.DefaultTickWords,
+		)
+		if _err != nil {
+			err = _err
+
+			return
+		}
+		v3, ok := snap.Get(pool.Address)
+		if !ok {
+			err = fmt.Errorf("load v3 pool %s: %v", pool.Address, snap.Failed)
+
+			return
+		}
+		q = v3
+
+		return
+	}
+
+	snap, err := loader.Load(ctx, []entities.Pool{pool}, block)
+	if err != nil {
+		return
+	}
+	r, ok := snap.Get(pool.Address)
+	if !ok {
+		err = fmt.Errorf("load pool %s: %v", pool.Address, snap.Failed)
+
+		return
+	}
+	q = r
+
+	return
+}
+
+func findPool(pools []entities.Pool, addr string) (
+	pool entities.Pool,
+	err error,
+) {
+	for _, p := range pools {
+		if strings.EqualFold(p.Address, addr) {
+			pool = p
+
+			return
+		}
+	}
+	err = fmt.Errorf("pool %s not found", addr)
+
+	return
+}
+
+func containsAddress(list []string, addr string) bool {
+	for _, a := range list {
+		if eth.ToAddress(a) == eth.ToAddress(addr) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,95 @@
+package trade
+
+import (
+	"context"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/antonyuhnovets/flash-loan-arbitrage/internal/entities"
+)
+
+// SpeedUpTx resends pending tx with the same nonce & fees bumped by percent
+func (tc *TradeCase) SpeedUpTx(
+	ctx context.Context,
+	hash string,
+	percent uint64,
+) (
+	rec entities.Transaction,
+	err error,
+) {
+	auth, err := tc.ethClient(ctx)
+	if err != nil {
+		return
+	}
+
+	t, err := auth.SpeedUp(ctx, hash, percent)
+	if err != nil {
+		return
+	}
+
+	rec, err = tc.replaced(ctx, hash, "", t)
+
+	return
+}
+
+// CancelTx replaces pending tx with zero-value self-transfer
+func (tc *TradeCase) CancelTx(
+	ctx context.Context,
+	hash string,
+	percent uint64,
+) (
+	rec entities.Transaction,
+	err error,
+) {
+	auth, err := tc.ethClient(ctx)
+	if err != nil {
+		return
+	}
+
+	t, err := auth.Cancel(ctx, hash, percent)
+	if err != nil {
+		return
+	}
+
+	rec, err = tc.replaced(ctx, hash, "cancel", t)
+
+	return
+}
+
+// replaced tracks replacement & marks tracked original as replaced,
+// method of original is kept if not given
+func (tc *TradeCase) replaced(
+	ctx context.Context,
+	hash, method string,
+	t *types.Transaction,
+) (
+	rec entities.Transaction,
+	err error,
+) {
+	old, _err := tc.Repo.GetTx(ctx, _defaultTxTable, hash)
+	known := _err == nil
+
+	if method == "" {
+		method = old.Method
+	}
+
+	rec, err = tc.txRecord(ctx, method, t)
+	if err != nil {
+		return
+	}
+	rec.Bumps = old.Bumps + 1
+
+	err = tc.Repo.AddTx(ctx, _defaultTxTable, rec)
+	if err != nil || !known {
+		return
+	}
+
+	old.Status = entities.TxReplaced
+	old.ReplacedBy = rec.Hash
+	old.UpdatedAt = time.Now().UTC()
+
+	err = tc.Repo.UpdateTx(ctx, _defaultTxTable, old)
+
+	return
+}
@@ -1,16 +1,22 @@
 package repo
 
 import (
+	"bytes"
 	c "context"
 	"encoding/json"
 	"fmt"
+	"strings"
+	"sync"
 
 	"github.com/antonyuhnovets/flash-loan-arbitrage/internal/entities"
 	"github.com/antonyuhnovets/flash-loan-arbitrage/internal/trade"
 	fs "github.com/antonyuhnovets/flash-loan-arbitrage/pkg/filestorage"
 )
 
+// Storage keeps lists in json files. Every write reads or rewrites
+// the whole file, so writes hold the lock & files are replaced at once
 type Storage struct {
+	mu  sync.RWMutex
 	fst *fs.FileStorage
 }
 
@@ -25,7 +31,7 @@ func NewStorage(files map[string]string) (
 		return
 	}
 
-	st = &Storage{s}
+	st = &Storage{fst: s}
 
 	return
 }
@@ -42,7 +48,8 @@ func (s *Storage) GetByTokens(
 	pools []entities.Pool,
 	err error,
 ) {
-	// var res []entities.TradePool
+	s.mu.RLock()
+	defer s.mu.RUnlock()
 
 	err = s.fst.Read(
 		ctx,
@@ -63,7 +70,22 @@ func (s *Storage) AddPool(
 ) (
 	err error,
 ) {
-	b, err := json.Marshal(pool)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	err = s.addPool(ctx, where, pool)
+
+	return
+}
+
+func (s *Storage) addPool(
+	ctx c.Context,
+	where string,
+	pool entities.Pool,
+) (
+	err error,
+) {
+	b, err := json.Marshal(pool.Normalize())
 	if err != nil {
 		return
 	}
@@ -99,11 +121,14 @@ func (s *Storage) StorePools(
 ) (
 	err error,
 ) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
 	for _, pool := range pools {
-		err = s.AddPool(
+		err = s.addPool(
 			ctx,
-			pool,
 			where,
+			pool,
 		)
 		if err != nil {
 			return
@@ -120,6 +145,9 @@ func (s *Storage) ListPools(
 	pools []entities.Pool,
 	err error,
 ) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
 	err = s.fst.Read(
 		ctx,
 		where,
@@ -136,12 +164,22 @@ func (s *Storage) RemovePool(
 ) (
 	err error,
 ) {
-	b, err := json.Marshal(pool)
-	if err != nil {
-		return
-	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	err = s.removePool(ctx, where, pool)
+
+	return
+}
 
-	err = s.fst.Remove(ctx, where, b)
+func (s *Storage) removePool(
+	ctx c.Context,
+	where string,
+	pool entities.Pool,
+) (
+	err error,
+) {
+	err = s.remove(ctx, where, pool.Normalize())
 
 	return
 }
@@ -154,14 +192,17 @@ func (s *Storage) RemovePools(
 	out []entities.Pool,
 	err error,
 ) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
 	for _, pool := range pools {
-		err = s.RemovePool(ctx, where, pool)
+		err = s.removePool(ctx, where, pool)
 		if err != nil {
 			return
 		}
 	}
 
-	out, err = s.ListPools(ctx, where)
+	err = s.fst.Read(ctx, where, &out)
 
 	return
 }
@@ -173,7 +214,22 @@ func (s *Storage) AddToken(
 ) (
 	err error,
 ) {
-	b, err := json.Marshal(token)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	err = s.addToken(ctx, where, token)
+
+	return
+}
+
+func (s *Storage) addToken(
+	ctx c.Context,
+	where string,
+	token entities.Token,
+) (
+	err error,
+) {
+	b, err := json.Marshal(token.Normalize())
 	if err != nil {
 		return
 	}
@@ -202,12 +258,22 @@ func (s *Storage) RemoveToken(
 ) (
 	err error,
 ) {
-	b, err := json.Marshal(token)
-	if err != nil {
-		return
-	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	err = s.removeToken(ctx, where, token)
+
+	return
+}
 
-	err = s.fst.Remove(ctx, where, b)
+func (s *Storage) removeToken(
+	ctx c.Context,
+	where string,
+	token entities.Token,
+) (
+	err error,
+) {
+	err = s.remove(ctx, where, token.Normalize())
 
 	return
 }
@@ -220,14 +286,17 @@ func (s *Storage) RemoveTokens(
 	out []entities.Token,
 	err error,
 ) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
 	for _, token := range tokens {
-		err = s.RemoveToken(ctx, where, token)
+		err = s.removeToken(ctx, where, token)
 		if err != nil {
 			return
 		}
 	}
 
-	out, err = s.ListTokens(ctx, where)
+	err = s.fst.Read(ctx, where, &out)
 
 	return
 }
@@ -239,8 +308,11 @@ func (s *Storage) StoreTokens(
 ) (
 	err error,
 ) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
 	for _, token := range tokens {
-		err = s.AddToken(
+		err = s.addToken(
 			ctx,
 			where,
 			token,
@@ -260,7 +332,10 @@ func (s *Storage) ListTokens(
 	tokens []entities.Token,
 	err error,
 ) {
-	err = s.fst.Read(ctx, where, tokens)
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	err = s.fst.Read(ctx, where, &tokens)
 
 	return
 }
@@ -281,7 +356,7 @@ func (s *Storage) GetTokenByAddress(
 	}
 
 	for _, t := range tokens {
-		if t.Address == address {
+		if strings.EqualFold(t.Address, strings.TrimSpace(address)) {
 			token = t
 			return
 		}
@@ -295,10 +370,450 @@ func (s *Storage) GetTokenByAddress(
 	return
 }
 
+// ReplacePools rewrites file with pools
+func (s *Storage) ReplacePools(
+	ctx c.Context,
+	where string,
+	pools []entities.Pool,
+) (
+	err error,
+) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	items := make([]interface{}, len(pools))
+	for i, pool := range pools {
+		items[i] = pool.Normalize()
+	}
+
+	err = s.rewrite(ctx, where, items)
+
+	return
+}
+
+// ReplaceTokens rewrites file with tokens
+func (s *Storage) ReplaceTokens(
+	ctx c.Context,
+	where string,
+	tokens []entities.Token,
+) (
+	err error,
+) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	items := make([]interface{}, len(tokens))
+	for i, token := range tokens {
+		items[i] = token.Normalize()
+	}
+
+	err = s.rewrite(ctx, where, items)
+
+	return
+}
+
+// remove rewrites file without item, it's found by its json
+func (s *Storage) remove(
+	ctx c.Context,
+	where string,
+	item interface{},
+) (
+	err error,
+) {
+	b, err := json.Marshal(item)
+	if err != nil {
+		return
+	}
+
+	var items []json.RawMessage
+
+	err = s.fst.Read(ctx, where, &items)
+	if err != nil {
+		return
+	}
+
+	found := false
+	kept := make([]interface{}, 0, len(items))
+	for _, it := range items {
+		if !found && bytes.Equal(it, b) {
+			found = true
+
+			continue
+		}
+		kept = append(kept, it)
+	}
+	if !found {
+		err = fmt.Errorf("item %s not found", b)
+
+		return
+	}
+
+	err = s.rewrite(ctx, where, kept)
+
+	return
+}
+
+// rewrite replaces file with items, one per line as appending
+// writes them
+func (s *Storage) rewrite(
+	ctx c.Context,
+	where string,
+	items []interface{},
+) (
+	err error,
+) {
+	lines := make([]string, len(items))
+	for i, item := range items {
+		b, _err := json.Marshal(item)
+		if _err != nil {
+			err = _err
+
+			return
+		}
+		lines[i] = string(b)
+	}
+
+	content := "[\n]"
+	if len(lines) > 0 {
+		content = "[\n" + strings.Join(lines, ",\n") + "\n]"
+	}
+
+	err = s.fst.Replace(ctx, where, []byte(content))
+
+	return
+}
+
 func (s *Storage) ClearAll(ctx c.Context) (
 	err error,
 ) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
 	err = s.fst.ClearAll(ctx)
 
 	return
 }
+
+func (s *Storage) AddTx(
+	ctx c.Context,
+	where string,
+	tx entities.Transaction,
+) (
+	err error,
+) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b, err := json.Marshal(tx)
+	if err != nil {
+		return
+	}
+
+	err = s.fst.ContinueFile(
+		ctx,
+		where,
+		[]byte(string(b[0:])+"\n"),
+	)
+	if err != nil {
+		return
+	}
+	err = s.fst.Store(
+		ctx,
+		where,
+		[]byte("]"),
+	)
+
+	return
+}
+
+// UpdateTx rewrites file with transaction replaced by hash
+func (s *Storage) UpdateTx(
+	ctx c.Context,
+	where string,
+	tx entities.Transaction,
+) (
+	err error,
+) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var txs []entities.Transaction
+
+	err = s.fst.Read(ctx, where, &txs)
+	if err != nil {
+		return
+	}
+
+	found := false
+	for i, t := range txs {
+		if strings.EqualFold(t.Hash, tx.Hash) {
+			txs[i] = tx
+			found = true
+		}
+	}
+	if !found {
+		err = fmt.Errorf("transaction %s not found", tx.Hash)
+
+		return
+	}
+
+	items := make([]interface{}, len(txs))
+	for i, t := range txs {
+		items[i] = t
+	}
+
+	err = s.rewrite(ctx, where, items)
+
+	return
+}
+
+func (s *Storage) GetTx(
+	ctx c.Context,
+	where, hash string,
+) (
+	tx entities.Transaction,
+	err error,
+) {
+	txs, err := s.ListTxs(ctx, where)
+	if err != nil {
+		return
+	}
+
+	for _, t := range txs {
+		if strings.EqualFold(t.Hash, hash) {
+			tx = t
+
+			return
+		}
+	}
+
+	err = fmt.Errorf("transaction %s not found", hash)
+
+	return
+}
+
+func (s *Storage) ListTxs(
+	ctx c.Context,
+	where string,
+) (
+	txs []entities.Transaction,
+	err error,
+) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	err = s.fst.Read(ctx, where, &txs)
+
+	return
+}
+
+// GetCheckpoint returns checkpoint of factory, zero block if none
+func (s *Storage) GetCheckpoint(
+	ctx c.Context,
+	where, factory string,
+) (
+	cp entities.Checkpoint,
+	err error,
+) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var cps []entities.Checkpoint
+
+	err = s.fst.Read(ctx, where, &cps)
+	if err != nil {
+		return
+	}
+
+	cp.Factory = factory
+	for _, p := range cps {
+		if strings.EqualFold(p.Factory, factory) {
+			cp = p
+
+			return
+		}
+	}
+
+	return
+}
+
+// SetCheckpoint rewrites file with checkpoint of factory replaced
+func (s *Storage) SetCheckpoint(
+	ctx c.Context,
+	where string,
+	cp entities.Checkpoint,
+) (
+	err error,
+) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var cps []entities.Checkpoint
+
+	err = s.fst.Read(ctx, where, &cps)
+	if err != nil {
+		return
+	}
+
+	found := false
+	for i, p := range cps {
+		if strings.EqualFold(p.Factory, cp.Factory) {
+			cps[i] = cp
+			found = true
+		}
+	}
+	if !found {
+		cps = append(cps, cp)
+	}
+
+	b, err := json.Marshal(cps)
+	if err != nil {
+		return
+	}
+
+	err = s.fst.Replace(ctx, where, b)
+
+	return
+}
+
+// StoreRoutes rewrites file with the latest found routes
+func (s *Storage) StoreRoutes(
+	ctx c.Context,
+	where string,
+	routes []entities.TradeRoute,
+) (
+	err error,
+) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if routes == nil {
+		routes = make([]entities.TradeRoute, 0)
+	}
+
+	b, err := json.Marshal(routes)
+	if err != nil {
+		return
+	}
+
+	err = s.fst.Replace(ctx, where, b)
+
+	return
+}
+
+func (s *Storage) ListRoutes(
+	ctx c.Context,
+	where string,
+) (
+	routes []entities.TradeRoute,
+	err error,
+) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	err = s.fst.Read(ctx, where, &routes)
+
+	return
+}
+
+// StoreProtocol rewrites file with protocol, protocol of the same
+// name or factory is replaced
+func (s *Storage) StoreProtocol(
+	ctx c.Context,
+	where string,
+	sp entities.SwapProtocol,
+) (
+	err error,
+) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sps, err := s.dropProtocol(ctx, where, sp)
+	if err != nil {
+		return
+	}
+
+	err = s.storeProtocols(ctx, where, append(sps, sp.Normalize()))
+
+	return
+}
+
+// DropProtocol rewrites file without protocol of the same name or
+// factory, it's not an error if there is none
+func (s *Storage) DropProtocol(
+	ctx c.Context,
+	where string,
+	sp entities.SwapProtocol,
+) (
+	err error,
+) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sps, err := s.dropProtocol(ctx, where, sp)
+	if err != nil {
+		return
+	}
+
+	err = s.storeProtocols(ctx, where, sps)
+
+	return
+}
+
+func (s *Storage) StoredProtocols(
+	ctx c.Context,
+	where string,
+) (
+	sps []entities.SwapProtocol,
+	err error,
+) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	err = s.fst.Read(ctx, where, &sps)
+
+	return
+}
+
+// dropProtocol reads stored protocols except of sp
+func (s *Storage) dropProtocol(
+	ctx c.Context,
+	where string,
+	sp entities.SwapProtocol,
+) (
+	out []entities.SwapProtocol,
+	err error,
+) {
+	var sps []entities.SwapProtocol
+
+	err = s.fst.Read(ctx, where, &sps)
+	if err != nil {
+		return
+	}
+
+	out = make([]entities.SwapProtocol, 0, len(sps))
+	for _, p := range sps {
+		if !sp.Same(p) {
+			out = append(out, p)
+		}
+	}
+
+	return
+}
+
+func (s *Storage) storeProtocols(
+	ctx c.Context,
+	where string,
+	sps []entities.SwapProtocol,
+) (
+	err error,
+) {
+	b, err := json.Marshal(sps)
+	if err != nil {
+		return
+	}
+
+	err = s.fst.Replace(ctx, where, b)
+
+	return
+}
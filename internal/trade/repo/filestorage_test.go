@@ -0,0 +1,133 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/antonyuhnovets/flash-loan-arbitrage/internal/entities"
+)
+
+func TestStorageConcurrentTxs(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "transactions.json")
+
+	s, err := NewStorage(map[string]string{"transactions": path})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	first := entities.Transaction{Hash: "0x00", Status: entities.TxPending}
+	if err = s.AddTx(ctx, "transactions", first); err != nil {
+		t.Fatal(err)
+	}
+
+	// tracker rewrites file while new txs are appended
+	const n = 50
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+
+		for i := 1; i <= n; i++ {
+			tx := entities.Transaction{Hash: fmt.Sprintf("0x%02x", i)}
+			if err := s.AddTx(ctx, "transactions", tx); err != nil {
+				t.Error(err)
+
+				return
+			}
+		}
+	}()
+	go func() {
+		defer wg.Done()
+
+		for i := 0; i < n; i++ {
+			first.Bumps = i
+			if err := s.UpdateTx(ctx, "transactions", first); err != nil {
+				t.Error(err)
+
+				return
+			}
+		}
+	}()
+	wg.Wait()
+
+	txs, err := s.ListTxs(ctx, "transactions")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(txs) != n+1 {
+		t.Fatalf("%v txs stored, expected %v", len(txs), n+1)
+	}
+	if txs[0].Bumps != n-1 {
+		t.Errorf("bumps %v, expected %v", txs[0].Bumps, n-1)
+	}
+
+	// first item is removed from rewritten file
+	tokens := []entities.Token{{Address: "0x01"}, {Address: "0x02"}}
+	s.fst.UseFile("tokens", filepath.Join(dir, "tokens.json"))
+	if err = s.ReplaceTokens(ctx, "tokens", tokens); err != nil {
+		t.Fatal(err)
+	}
+	out, err := s.RemoveTokens(ctx, "tokens", tokens[:1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(out) != 1 || out[0].Address != "0x02" {
+		t.Errorf("tokens %+v left", out)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 {
+		t.Errorf("temporary files left: %v", entries)
+	}
+}
+
+func TestStorageProtocols(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "protocols.json")
+
+	s, err := NewStorage(map[string]string{"protocols": path})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	sushi := entities.SwapProtocol{
+		Name:    "Sushiswap-V2",
+		Factory: "0xc35dadb65012ec5796536bd9864ed8773abc74c4",
+	}
+	pancake := entities.SwapProtocol{
+		Name:    "Pancakeswap-V2",
+		Factory: "0x1097053Fd2ea711dad45caCcc45EfF7548fCB362",
+	}
+	for _, sp := range []entities.SwapProtocol{sushi, pancake} {
+		if err = s.StoreProtocol(ctx, "protocols", sp); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// protocol of the same factory is replaced
+	sushi.FeeBps = 25
+	if err = s.StoreProtocol(ctx, "protocols", sushi); err != nil {
+		t.Fatal(err)
+	}
+	if err = s.DropProtocol(ctx, "protocols", entities.SwapProtocol{Name: "Pancakeswap-V2"}); err != nil {
+		t.Fatal(err)
+	}
+
+	sps, err := s.StoredProtocols(ctx, "protocols")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sps) != 1 || sps[0].FeeBps != 25 || sps[0].Factory != "0xc35DADB65012eC5796536bD9864eD8773aBc74C4" {
+		t.Errorf("stored %+v", sps)
+	}
+}
@@ -3,6 +3,7 @@ package repo
 import (
 	c "context"
 	"fmt"
+	"strings"
 
 	"github.com/antonyuhnovets/flash-loan-arbitrage/config"
 	"github.com/antonyuhnovets/flash-loan-arbitrage/internal/entities"
@@ -10,6 +11,20 @@ import (
 	"github.com/antonyuhnovets/flash-loan-arbitrage/pkg/postgres"
 )
 
+const (
+	_protocolTable = "protocols"
+	_pairTable     = "token_pairs" // pairs of pools, default table of gorm
+)
+
+// entities migrated on connect
+var _tables = []interface{}{
+	&entities.Token{},
+	&entities.Pool{},
+	&entities.Transaction{},
+	&entities.Checkpoint{},
+	&entities.TradeRoute{},
+}
+
 type PostgresRepo struct {
 	ps *postgres.Storage
 	ss postgres.Serializers
@@ -24,11 +39,23 @@ func New(conf config.Database) (
 		conf.Host, conf.Username, conf.Password, conf.Name, conf.Port,
 	)
 
-	conn, err := postgres.Connect(
-		dsn,
-		&entities.Token{},
-		&entities.Pool{},
-	)
+	conn, err := postgres.Connect(dsn, _tables...)
+	if err != nil {
+		return
+	}
+
+	pr, err = newRepo(conn)
+
+	return
+}
+
+func newRepo(conn *postgres.Storage) (
+	pr *PostgresRepo,
+	err error,
+) {
+	// protocols of pools are kept in swap_protocols, protocols added
+	// over api get a table of their own
+	err = conn.Migrate(_protocolTable, &entities.SwapProtocol{})
 	if err != nil {
 		return
 	}
@@ -52,11 +79,10 @@ func (pr *PostgresRepo) ListPools(
 	pools []entities.Pool,
 	err error,
 ) {
-	err = pr.GetStorage().Read(ctx, table, &pools)
-	if err != nil {
-
-		return
-	}
+	err = pr.ps.ReadPreloaded(
+		ctx, table, &pools,
+		"Pair.Token0", "Pair.Token1", "Protocol",
+	)
 
 	return
 }
@@ -81,6 +107,8 @@ func (pr *PostgresRepo) AddPool(
 ) (
 	err error,
 ) {
+	pool = pool.Normalize()
+
 	err = pr.GetStorage().Store(ctx, table, &pool)
 
 	return
@@ -148,6 +176,7 @@ func (pr *PostgresRepo) AddToken(
 ) (
 	err error,
 ) {
+	token = token.Normalize()
 
 	err = pr.GetStorage().Store(ctx, table, &token)
 
@@ -180,7 +209,7 @@ func (pr *PostgresRepo) GetTokenByAddress(
 	}
 
 	for _, t := range tokens {
-		if t.Address == address {
+		if strings.EqualFold(t.Address, strings.TrimSpace(address)) {
 			token = t
 			return
 		}
@@ -217,3 +246,377 @@ func (pr *PostgresRepo) RemoveTokens(
 
 	return
 }
+
+func (pr *PostgresRepo) AddTx(
+	ctx c.Context, table string, tx entities.Transaction,
+) (
+	err error,
+) {
+	err = pr.GetStorage().Store(ctx, table, &tx)
+
+	return
+}
+
+func (pr *PostgresRepo) UpdateTx(
+	ctx c.Context, table string, tx entities.Transaction,
+) (
+	err error,
+) {
+	old, err := pr.GetTx(ctx, table, tx.Hash)
+	if err != nil {
+		return
+	}
+	tx.ID = old.ID
+
+	err = pr.ps.Update(ctx, table, &tx)
+
+	return
+}
+
+func (pr *PostgresRepo) GetTx(
+	ctx c.Context, table string, hash string,
+) (
+	tx entities.Transaction,
+	err error,
+) {
+	txs, err := pr.ListTxs(ctx, table)
+	if err != nil {
+		return
+	}
+
+	for _, t := range txs {
+		if strings.EqualFold(t.Hash, hash) {
+			tx = t
+
+			return
+		}
+	}
+
+	err = fmt.Errorf("transaction %s not found", hash)
+
+	return
+}
+
+func (pr *PostgresRepo) ListTxs(
+	ctx c.Context, table string,
+) (
+	txs []entities.Transaction,
+	err error,
+) {
+	err = pr.GetStorage().Read(ctx, table, &txs)
+
+	return
+}
+
+// GetCheckpoint returns checkpoint of factory, zero block if none
+func (pr *PostgresRepo) GetCheckpoint(
+	ctx c.Context, table string, factory string,
+) (
+	cp entities.Checkpoint,
+	err error,
+) {
+	var cps []entities.Checkpoint
+
+	err = pr.GetStorage().Read(ctx, table, &cps)
+	if err != nil {
+		return
+	}
+
+	cp.Factory = factory
+	for _, p := range cps {
+		if strings.EqualFold(p.Factory, factory) {
+			cp = p
+
+			return
+		}
+	}
+
+	return
+}
+
+func (pr *PostgresRepo) SetCheckpoint(
+	ctx c.Context, table string, cp entities.Checkpoint,
+) (
+	err error,
+) {
+	old, err := pr.GetCheckpoint(ctx, table, cp.Factory)
+	if err != nil {
+		return
+	}
+
+	if old.ID == 0 {
+		err = pr.GetStorage().Store(ctx, table, &cp)
+
+		return
+	}
+	cp.ID = old.ID
+
+	err = pr.ps.Update(ctx, table, &cp)
+
+	return
+}
+
+// StoreRoutes replaces stored routes with the latest found in one
+// transaction
+func (pr *PostgresRepo) StoreRoutes(
+	ctx c.Context, table string, routes []entities.TradeRoute,
+) (
+	err error,
+) {
+	err = pr.atomic(ctx, func(tr *PostgresRepo) (
+		err error,
+	) {
+		old, err := tr.ListRoutes(ctx, table)
+		if err != nil {
+			return
+		}
+		for _, route := range old {
+			route := route
+
+			err = tr.GetStorage().Remove(ctx, table, &route)
+			if err != nil {
+				return
+			}
+		}
+
+		for _, route := range routes {
+			route := route
+
+			err = tr.GetStorage().Store(ctx, table, &route)
+			if err != nil {
+				return
+			}
+		}
+
+		return
+	})
+
+	return
+}
+
+func (pr *PostgresRepo) ListRoutes(
+	ctx c.Context, table string,
+) (
+	routes []entities.TradeRoute,
+	err error,
+) {
+	err = pr.GetStorage().Read(ctx, table, &routes)
+
+	return
+}
+
+// ReplacePools rewrites stored pools in place in one transaction,
+// pools missing in the list are dropped as duplicates. Pairs keep their
+// rows, only token order of a normalized pair is updated
+func (pr *PostgresRepo) ReplacePools(
+	ctx c.Context, table string, pools []entities.Pool,
+) (
+	err error,
+) {
+	err = pr.atomic(ctx, func(tr *PostgresRepo) (
+		err error,
+	) {
+		old, err := tr.ListPools(ctx, table)
+		if err != nil {
+			return
+		}
+
+		kept := make(map[int]bool, len(pools))
+		for _, pool := range pools {
+			if pool.ID == 0 {
+				err = tr.AddPool(ctx, pool, table)
+				if err != nil {
+					return
+				}
+
+				continue
+			}
+			kept[pool.ID] = true
+
+			err = tr.ps.UpdateWhere(ctx, table, map[string]interface{}{
+				"address":  pool.Address,
+				"fee_tier": pool.FeeTier,
+				"fee_bps":  pool.FeeBps,
+			}, "id = ?", pool.ID)
+			if err != nil {
+				return
+			}
+
+			t0, t1 := pool.Pair.Token0.ID, pool.Pair.Token1.ID
+			if pool.PairID == 0 || t0 == 0 || t1 == 0 {
+				continue
+			}
+			err = tr.ps.UpdateWhere(ctx, _pairTable, map[string]interface{}{
+				"token0_id": t0,
+				"token1_id": t1,
+			}, "id = ?", pool.PairID)
+			if err != nil {
+				return
+			}
+		}
+
+		for _, pool := range old {
+			if kept[pool.ID] {
+				continue
+			}
+
+			err = tr.RemovePool(ctx, table, pool)
+			if err != nil {
+				return
+			}
+		}
+
+		return
+	})
+
+	return
+}
+
+// ReplaceTokens rewrites stored tokens in place in one transaction.
+// Tokens missing in the list are duplicates, pairs referencing them
+// are moved to the kept token of the same address before they are
+// dropped
+func (pr *PostgresRepo) ReplaceTokens(
+	ctx c.Context, table string, tokens []entities.Token,
+) (
+	err error,
+) {
+	err = pr.atomic(ctx, func(tr *PostgresRepo) (
+		err error,
+	) {
+		old, err := tr.ListTokens(ctx, table)
+		if err != nil {
+			return
+		}
+
+		kept := make(map[int]bool, len(tokens))
+		byAddress := make(map[string]int, len(tokens))
+		for _, token := range tokens {
+			if token.ID == 0 {
+				err = tr.AddToken(ctx, table, token)
+				if err != nil {
+					return
+				}
+
+				continue
+			}
+			kept[token.ID] = true
+			byAddress[entities.Checksum(token.Address)] = token.ID
+
+			err = tr.ps.UpdateWhere(ctx, table, map[string]interface{}{
+				"address":  token.Address,
+				"name":     token.Name,
+				"wei":      token.Wei,
+				"symbol":   token.Symbol,
+				"decimals": token.Decimals,
+			}, "id = ?", token.ID)
+			if err != nil {
+				return
+			}
+		}
+
+		for _, token := range old {
+			if kept[token.ID] {
+				continue
+			}
+
+			if id, ok := byAddress[entities.Checksum(token.Address)]; ok {
+				for _, column := range []string{"token0_id", "token1_id"} {
+					err = tr.ps.UpdateWhere(ctx, _pairTable, map[string]interface{}{
+						column: id,
+					}, column+" = ?", token.ID)
+					if err != nil {
+						return
+					}
+				}
+			}
+
+			token := token
+
+			err = tr.GetStorage().Remove(ctx, table, &token)
+			if err != nil {
+				return
+			}
+		}
+
+		return
+	})
+
+	return
+}
+
+// atomic runs fn on repository of a db transaction, nothing is
+// stored if fn fails
+func (pr *PostgresRepo) atomic(
+	ctx c.Context, fn func(*PostgresRepo) error,
+) (
+	err error,
+) {
+	err = pr.ps.Transaction(ctx, func(tx *postgres.Storage) error {
+		return fn(&PostgresRepo{ps: tx, ss: pr.ss})
+	})
+
+	return
+}
+
+// StoreProtocol stores protocol in one transaction, protocol of the
+// same name or factory is replaced
+func (pr *PostgresRepo) StoreProtocol(
+	ctx c.Context, table string, sp entities.SwapProtocol,
+) (
+	err error,
+) {
+	sp = sp.Normalize()
+	sp.ID = 0
+
+	err = pr.atomic(ctx, func(tr *PostgresRepo) (
+		err error,
+	) {
+		err = tr.DropProtocol(ctx, table, sp)
+		if err != nil {
+			return
+		}
+
+		err = tr.GetStorage().Store(ctx, table, &sp)
+
+		return
+	})
+
+	return
+}
+
+// DropProtocol removes protocols of the same name or factory
+func (pr *PostgresRepo) DropProtocol(
+	ctx c.Context, table string, sp entities.SwapProtocol,
+) (
+	err error,
+) {
+	sps, err := pr.StoredProtocols(ctx, table)
+	if err != nil {
+		return
+	}
+	for _, p := range sps {
+		if !sp.Same(p) {
+			continue
+		}
+		p := p
+
+		err = pr.GetStorage().Remove(ctx, table, &p)
+		if err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+func (pr *PostgresRepo) StoredProtocols(
+	ctx c.Context, table string,
+) (
+	sps []entities.SwapProtocol,
+	err error,
+) {
+	err = pr.GetStorage().Read(ctx, table, &sps)
+
+	return
+}
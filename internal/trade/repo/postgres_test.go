@@ -0,0 +1,108 @@
+package repo
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"gorm.io/driver/sqlite"
+
+	"github.com/antonyuhnovets/flash-loan-arbitrage/internal/entities"
+	"github.com/antonyuhnovets/flash-loan-arbitrage/internal/trade"
+	"github.com/antonyuhnovets/flash-loan-arbitrage/pkg/postgres"
+)
+
+const (
+	testWETH = "0xC02aaA39b223FE8D0A0e5C4F27eAD9083C756Cc2"
+	testUSDC = "0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48"
+	testPool = "0xB4e16d0168e52d35CaCD2c6185b44281Ec28C9Dc"
+)
+
+// newTestRepo is a repository on in-memory sqlite, sql of the
+// repository is the same as of postgres
+func newTestRepo(t *testing.T) *PostgresRepo {
+	conn, err := postgres.Open(sqlite.Open(":memory:"), _tables...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pr, err := newRepo(conn)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return pr
+}
+
+func TestPostgresNormalizeKeepsPairs(t *testing.T) {
+	pr := newTestRepo(t)
+	ctx := context.Background()
+
+	// stored before addresses were checksummed, WETH twice
+	weth := entities.Token{Address: strings.ToLower(testWETH), Name: "WETH"}
+	usdc := entities.Token{Address: testUSDC, Name: "USDC"}
+	wethDup := entities.Token{Address: testWETH}
+	for _, token := range []*entities.Token{&weth, &usdc, &wethDup} {
+		if err := pr.GetStorage().Store(ctx, "tokens", token); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	pools := []entities.Pool{
+		{
+			Address: strings.ToLower(testPool),
+			Pair:    entities.TokenPair{Token0: weth, Token1: usdc},
+		},
+		{
+			Address: "0x397FF1542f962076d0BFE58eA045FfA2d347ACa0",
+			Pair:    entities.TokenPair{Token0: usdc, Token1: wethDup},
+			FeeBps:  30,
+		},
+	}
+	for i := range pools {
+		if err := pr.GetStorage().Store(ctx, "pools", &pools[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	pc := trade.NewParseCase(pr, nil)
+	n, err := pc.Normalize(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n.DuplicateTokens != 1 || n.Pools == 0 {
+		t.Errorf("normalized %+v", n)
+	}
+
+	tokens, err := pr.ListTokens(ctx, "tokens")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tokens) != 2 {
+		t.Fatalf("tokens %+v", tokens)
+	}
+
+	out, err := pr.ListPools(ctx, "pools")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(out) != 2 {
+		t.Fatalf("pools %+v", out)
+	}
+	for i, pool := range out {
+		if pool.ID != pools[i].ID || pool.PairID != pools[i].PairID {
+			t.Errorf("pool %v moved: %+v", i, pool)
+		}
+		// pair links survive, token order is canonical
+		if pool.Pair.Token0.ID != usdc.ID || pool.Pair.Token1.ID != weth.ID {
+			t.Errorf("pool %s pair tokens %v, %v", pool.Address,
+				pool.Pair.Token0.ID, pool.Pair.Token1.ID)
+		}
+		if pool.Pair.Token1.Address != testWETH || pool.Pair.Token1.Name != "WETH" {
+			t.Errorf("pool %s token1 %+v", pool.Address, pool.Pair.Token1)
+		}
+	}
+	if out[0].Address != testPool || out[1].FeeBps != 30 {
+		t.Errorf("pools %+v", out)
+	}
+}
@@ -2,6 +2,7 @@ package repo
 
 import (
 	"context"
+	"math/big"
 	"os"
 	"testing"
 
@@ -49,7 +50,7 @@ var storeOneTests = []storeTest{
 		unit: Token{
 			Name:    "testOneTokenName",
 			Address: "testOneTokenAddress",
-			Wei:     10000000000,
+			Wei:     NewAmount(big.NewInt(10000000000)),
 		},
 		expected: nil,
 	},
@@ -116,12 +117,12 @@ var storeManyTests = []storeTest{
 				ID:      1,
 				Name:    "testManyTokenName0",
 				Address: "testManyTokenAddress0",
-				Wei:     10000000000,
+				Wei:     NewAmount(big.NewInt(10000000000)),
 			}, {
 				ID:      2,
 				Name:    "testManyTokenName1",
 				Address: "testManyTokenAddress1",
-				Wei:     10000000000,
+				Wei:     NewAmount(big.NewInt(10000000000)),
 			},
 		},
 		expected: nil,
@@ -0,0 +1,70 @@
+package trade
+
+import (
+	"context"
+	"time"
+
+	"github.com/antonyuhnovets/flash-loan-arbitrage/internal/entities"
+	"github.com/antonyuhnovets/flash-loan-arbitrage/pkg/pairs"
+)
+
+const _defaultRoutesTable = "routes"
+
+// FindRoutes searches cycles of 3 and more swaps through base tokens
+// over stored uniswap-v2 like pools on the latest block, found routes
+// replace stored ones. Routes are candidates, contract trades pairs only
+func (tc *TradeCase) FindRoutes(ctx context.Context) (
+	out []entities.TradeRoute,
+	err error,
+) {
+	pools, err := tc.Repo.ListPools(ctx, _defaultPoolsTable)
+	if err != nil {
+		return
+	}
+
+	var v2 []entities.Pool
+	for _, pool := range pools {
+		if !pairs.IsV3(pool) {
+			v2 = append(v2, pool)
+		}
+	}
+
+	baseTokens, err := tc.BaseTokens(ctx)
+	if err != nil {
+		return
+	}
+
+	snap, err := tc.PoolReserves(ctx, v2, nil)
+	if err != nil {
+		return
+	}
+
+	now := time.Now().UTC()
+	for _, r := range tc.finder.Find(snap.List(), baseTokens) {
+		out = append(out, entities.TradeRoute{
+			BaseToken: r.BaseToken,
+			Tokens:    r.Tokens,
+			Pools:     r.Pools,
+			AmountIn:  entities.NewAmount(r.AmountIn),
+			AmountOut: entities.NewAmount(r.AmountOut),
+			Profit:    entities.NewAmount(r.Profit),
+
+			PriceImpact:          r.PriceImpact,
+			BreakEvenSlippageBps: r.BreakEvenSlippageBps,
+
+			Block:   snap.Block,
+			FoundAt: now,
+		})
+	}
+
+	err = tc.Repo.StoreRoutes(ctx, _defaultRoutesTable, out)
+
+	return
+}
+
+// ListRoutes returns routes stored by the last search
+func (tc *TradeCase) ListRoutes(ctx context.Context) (
+	[]entities.TradeRoute, error,
+) {
+	return tc.Repo.ListRoutes(ctx, _defaultRoutesTable)
+}
@@ -0,0 +1,97 @@
+package trade
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/antonyuhnovets/flash-loan-arbitrage/internal/entities"
+	eth "github.com/antonyuhnovets/flash-loan-arbitrage/pkg/ethereum"
+)
+
+// Simulation is a dry run of flashArbitrage made as eth_call from owner
+type Simulation struct {
+	Pool0     string           `json:"pool0"`
+	Pool1     string           `json:"pool1"`
+	State     string           `json:"state"` // latest | pending
+	From      string           `json:"from"`
+	Success   bool             `json:"success"`
+	Profit    *entities.Amount `json:"profit,omitempty"` // in base token
+	BaseToken string           `json:"baseToken,omitempty"`
+	Revert    string           `json:"revert,omitempty"`
+}
+
+// Simulate calls flashArbitrage from contract owner without spending gas,
+// profit is taken from getProfit on the same state
+func (tc *TradeCase) Simulate(
+	ctx context.Context,
+	pool0, pool1 string,
+	pending bool,
+) (
+	sim Simulation,
+	err error,
+) {
+	sim = Simulation{Pool0: pool0, Pool1: pool1, State: "latest"}
+	if pending {
+		sim.State = "pending"
+	}
+
+	owner, err := tc.Contract.Api().Caller().Owner(eth.CallOpts(ctx))
+	if err != nil {
+		return
+	}
+	sim.From = eth.FromAddress(owner)
+
+	data, err := tc.Contract.Api().Pack(
+		"flashArbitrage",
+		eth.ToAddress(pool0),
+		eth.ToAddress(pool1),
+	)
+	if err != nil {
+		return
+	}
+
+	auth, err := tc.ethClient(ctx)
+	if err != nil {
+		return
+	}
+
+	_, err = auth.Call(
+		ctx, owner, eth.ToAddress(tc.Contract.Address()), data, pending,
+	)
+	if err != nil {
+		revert, ok := eth.RevertData(err)
+		if !ok {
+			// node reverted without data
+			if strings.Contains(err.Error(), "execution reverted") {
+				sim.Revert = err.Error()
+				err = nil
+			}
+
+			return
+		}
+		err = nil
+
+		sim.Revert, err = tc.Contract.Api().RevertReason(revert)
+		if err != nil {
+			sim.Revert = fmt.Sprintf("undecoded revert %x", revert)
+			err = nil
+		}
+
+		return
+	}
+
+	res, err := tc.Contract.Api().Caller().GetProfit(
+		eth.CallOpts(ctx, pending),
+		eth.ToAddress(pool0),
+		eth.ToAddress(pool1),
+	)
+	if err != nil {
+		return
+	}
+	sim.Success = true
+	sim.Profit = entities.NewAmount(res.Profit)
+	sim.BaseToken = eth.FromAddress(res.BaseToken)
+
+	return
+}
@@ -0,0 +1,127 @@
+package trade
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/antonyuhnovets/flash-loan-arbitrage/pkg/routes"
+	"github.com/antonyuhnovets/flash-loan-arbitrage/pkg/simulator"
+)
+
+// search range of pairs with v3 pools, 10^12 tokens of 18 decimals
+var maxSearchAmount = new(big.Int).Exp(big.NewInt(10), big.NewInt(30), nil)
+
+// ProfitCheck is net profit of contract trade with optimal size
+// of the pair computed from reserves & fees of its pools
+type ProfitCheck struct {
+	Estimate
+	Sizing      *routes.Sizing `json:"sizing,omitempty"`
+	SizingError string         `json:"sizingError,omitempty"`
+}
+
+// CheckProfit estimates contract trade & sizes the pair on Go side
+func (tc *TradeCase) CheckProfit(
+	ctx context.Context,
+	pool0, pool1 string,
+) (
+	pc ProfitCheck,
+	err error,
+) {
+	pc.Estimate, err = tc.EstimateNetProfit(ctx, pool0, pool1)
+	if err != nil {
+		return
+	}
+
+	s, _err := tc.SizePair(ctx, pool0, pool1)
+	if _err != nil {
+		pc.SizingError = _err.Error()
+
+		return
+	}
+	pc.Sizing = &s
+
+	return
+}
+
+// SizePair finds base token input maximising profit of round trip
+// through two stored pools in the better direction. Uniswap-v2 like
+// pools are sized in closed form, pairs with v3 pool by golden section
+func (tc *TradeCase) SizePair(
+	ctx context.Context,
+	pool0, pool1 string,
+) (
+	s routes.Sizing,
+	err error,
+) {
+	q0, q1, base, quote, err := tc.pairQuoters(ctx, pool0, pool1)
+	if err != nil {
+		return
+	}
+
+	s0, err0 := sizeRoundTrip(q0, q1, base, quote)
+	s1, err1 := sizeRoundTrip(q1, q0, base, quote)
+
+	switch {
+	case err0 != nil && err1 != nil:
+		err = err0
+	case err0 != nil:
+		s = s1
+	case err1 != nil:
+		s = s0
+	case s1.Profit.Cmp(s0.Profit) > 0:
+		s = s1
+	default:
+		s = s0
+	}
+
+	return
+}
+
+// sizeRoundTrip buys quote token on buy pool & sells it on sell pool
+func sizeRoundTrip(
+	buy, sell simulator.Quoter,
+	base, quote string,
+) (
+	s routes.Sizing,
+	err error,
+) {
+	r0, ok0 := buy.(simulator.Reserves)
+	r1, ok1 := sell.(simulator.Reserves)
+
+	if ok0 && ok1 {
+		e0, _err := routes.NewEdge(r0, base)
+		if _err != nil {
+			err = _err
+
+			return
+		}
+		e1, _err := routes.NewEdge(r1, quote)
+		if _err != nil {
+			err = _err
+
+			return
+		}
+		s, err = routes.OptimalInput([]routes.Edge{e0, e1})
+
+		return
+	}
+
+	s, err = routes.OptimizeSwap(
+		func(in *big.Int) (
+			out *big.Int,
+			err error,
+		) {
+			mid, err := buy.AmountOut(base, in)
+			if err != nil {
+				return
+			}
+			out, err = sell.AmountOut(quote, mid)
+
+			return
+		},
+		maxSearchAmount,
+		routes.DefaultIterations,
+	)
+
+	return
+}
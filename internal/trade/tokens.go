@@ -0,0 +1,132 @@
+package trade
+
+import (
+	"context"
+	"errors"
+
+	"github.com/antonyuhnovets/flash-loan-arbitrage/internal/entities"
+	"github.com/antonyuhnovets/flash-loan-arbitrage/pkg/erc20"
+)
+
+// TokenRejected reports whether token failed on chain checks,
+// it has no code or doesn't answer erc-20 calls
+func TokenRejected(err error) bool {
+	return errors.Is(err, erc20.ErrNotContract) ||
+		errors.Is(err, erc20.ErrNotERC20)
+}
+
+// Enrich sets symbol, name & decimals of tokens from chain, tokens
+// are returned as given without metadata fetcher. Metadata of stored
+// tokens is used without calls
+func (pc *ParseCase) Enrich(
+	ctx context.Context,
+	tokens []entities.Token,
+) (
+	out []entities.Token,
+	err error,
+) {
+	if pc.metadata != nil && len(tokens) > 0 {
+		err = pc.cacheStored(ctx)
+		if err != nil {
+			return
+		}
+	}
+
+	out, err = pc.enrich(ctx, tokens)
+
+	return
+}
+
+func (pc *ParseCase) cacheStored(ctx context.Context) (
+	err error,
+) {
+	stored, err := pc.Repository.ListTokens(ctx, _defaultTokensTable)
+	if err != nil {
+		return
+	}
+
+	for _, t := range stored {
+		if t.Symbol == "" || !entities.ValidAddress(t.Address) {
+			continue
+		}
+		pc.metadata.Cache(erc20.Metadata{
+			Address:  t.Address,
+			Symbol:   t.Symbol,
+			Name:     t.Name,
+			Decimals: t.Decimals,
+		})
+	}
+
+	return
+}
+
+func (pc *ParseCase) enrich(
+	ctx context.Context,
+	tokens []entities.Token,
+) (
+	out []entities.Token,
+	err error,
+) {
+	out = make([]entities.Token, len(tokens))
+	copy(out, tokens)
+
+	if pc.metadata == nil {
+		return
+	}
+
+	addrs := make([]string, len(out))
+	for i, t := range out {
+		addrs[i] = t.Address
+	}
+
+	md, err := pc.metadata.Fetch(ctx, addrs...)
+	if err != nil {
+		return
+	}
+
+	for i := range out {
+		out[i].Address = md[i].Address
+		out[i].Symbol = md[i].Symbol
+		out[i].Name = md[i].Name
+		out[i].Decimals = md[i].Decimals
+	}
+
+	return
+}
+
+// enrichEach fetches metadata of tokens in one batch, errs holds
+// error of token at the same index
+func (pc *ParseCase) enrichEach(
+	ctx context.Context,
+	addrs []string,
+) (
+	out []entities.Token,
+	errs []error,
+	err error,
+) {
+	out = make([]entities.Token, len(addrs))
+	errs = make([]error, len(addrs))
+	for i, addr := range addrs {
+		out[i].Address = addr
+	}
+
+	if pc.metadata == nil {
+		return
+	}
+
+	md, errs, err := pc.metadata.FetchEach(ctx, addrs...)
+	if err != nil {
+		return
+	}
+
+	for i := range out {
+		if errs[i] != nil {
+			continue
+		}
+		out[i].Symbol = md[i].Symbol
+		out[i].Name = md[i].Name
+		out[i].Decimals = md[i].Decimals
+	}
+
+	return
+}
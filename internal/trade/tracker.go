@@ -0,0 +1,524 @@
+package trade
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/antonyuhnovets/flash-loan-arbitrage/internal/entities"
+	eth "github.com/antonyuhnovets/flash-loan-arbitrage/pkg/ethereum"
+	"github.com/antonyuhnovets/flash-loan-arbitrage/pkg/logger"
+)
+
+const (
+	_defaultTxTable       = "transactions"
+	_defaultTrackInterval = 4 * time.Second
+	_defaultDropAfter     = 10 * time.Minute
+)
+
+type TrackerConfig struct {
+	Interval    time.Duration
+	DropAfter   time.Duration // unknown to node for this long means dropped
+	BumpAfter   uint64        // blocks without inclusion before speed up, 0 disables
+	BumpPercent uint64
+	MaxBumps    int
+}
+
+// Tracker follows sent transactions until they are mined,
+// reverted or dropped & persists the outcome
+type Tracker struct {
+	tc     *TradeCase
+	conf   TrackerConfig
+	l      logger.Interface
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func NewTracker(
+	tc *TradeCase,
+	conf TrackerConfig,
+	l logger.Interface,
+) (
+	t *Tracker,
+) {
+	if conf.Interval <= 0 {
+		conf.Interval = _defaultTrackInterval
+	}
+	if conf.DropAfter <= 0 {
+		conf.DropAfter = _defaultDropAfter
+	}
+	if conf.BumpPercent < eth.MinBumpPercent {
+		conf.BumpPercent = eth.MinBumpPercent
+	}
+
+	t = &Tracker{
+		tc:   tc,
+		conf: conf,
+		l:    l,
+	}
+
+	return
+}
+
+// Start runs tracking loop until context is done or Shutdown called
+func (t *Tracker) Start(ctx context.Context) {
+	ctx, t.cancel = context.WithCancel(ctx)
+
+	t.wg.Add(1)
+	go func() {
+		defer t.wg.Done()
+
+		t.run(ctx)
+	}()
+}
+
+// Shutdown stops loop and waits for current check
+func (t *Tracker) Shutdown() {
+	if t.cancel != nil {
+		t.cancel()
+	}
+	t.wg.Wait()
+}
+
+func (t *Tracker) run(ctx context.Context) {
+	ticker := time.NewTicker(t.conf.Interval)
+	defer ticker.Stop()
+
+	for {
+		err := t.CheckPending(ctx)
+		if err != nil && ctx.Err() == nil {
+			t.l.Error(fmt.Errorf("tracker - check: %w", err))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// CheckPending refreshes every pending transaction in storage
+func (t *Tracker) CheckPending(ctx context.Context) (
+	err error,
+) {
+	txs, err := t.tc.Repo.ListTxs(ctx, _defaultTxTable)
+	if err != nil {
+		return
+	}
+
+	auth, err := t.tc.ethClient(ctx)
+	if err != nil {
+		return
+	}
+
+	head, err := auth.Client.BlockNumber(ctx)
+	if err != nil {
+		return
+	}
+
+	for _, rec := range txs {
+		if rec.Status != entities.TxPending {
+			continue
+		}
+
+		out, _err := t.Check(ctx, rec)
+		if _err != nil {
+			t.l.Error(fmt.Errorf("tracker - tx %s: %w", rec.Hash, _err))
+
+			continue
+		}
+		if out.Status == entities.TxPending {
+			t.bump(ctx, head, out)
+
+			continue
+		}
+
+		err = t.update(ctx, out)
+		if err != nil {
+			return
+		}
+
+		// replacement lost the nonce, original may be mined
+		if out.Status == entities.TxDropped && out.Bumps > 0 {
+			err = t.resolveReplaced(ctx, txs, out.Hash)
+			if err != nil {
+				return
+			}
+		}
+	}
+
+	return
+}
+
+// resolveReplaced finds mined one among txs replaced by hash
+func (t *Tracker) resolveReplaced(
+	ctx context.Context,
+	txs []entities.Transaction,
+	hash string,
+) (
+	err error,
+) {
+	for _, rec := range txs {
+		if rec.Status != entities.TxReplaced || rec.ReplacedBy != hash {
+			continue
+		}
+
+		out, _err := t.Check(ctx, rec)
+		if _err != nil {
+			t.l.Error(fmt.Errorf("tracker - tx %s: %w", rec.Hash, _err))
+
+			continue
+		}
+
+		switch out.Status {
+		case entities.TxMined, entities.TxReverted:
+			err = t.update(ctx, out)
+		case entities.TxDropped:
+			if rec.Bumps > 0 {
+				err = t.resolveReplaced(ctx, txs, rec.Hash)
+			}
+		}
+		if err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+func (t *Tracker) update(
+	ctx context.Context,
+	out entities.Transaction,
+) (
+	err error,
+) {
+	err = t.tc.Repo.UpdateTx(ctx, _defaultTxTable, out)
+	if err != nil {
+		return
+	}
+
+	t.l.Info(
+		"tracker - tx %s %s: %s %s",
+		out.Hash, out.Method, out.Status, out.RevertReason,
+	)
+
+	return
+}
+
+// bump speeds up pending tx not included for BumpAfter blocks
+func (t *Tracker) bump(
+	ctx context.Context,
+	head uint64,
+	rec entities.Transaction,
+) {
+	if t.conf.BumpAfter == 0 || rec.SentBlock == 0 ||
+		head < rec.SentBlock+t.conf.BumpAfter ||
+		rec.Bumps >= t.conf.MaxBumps {
+		return
+	}
+
+	out, err := t.tc.SpeedUpTx(ctx, rec.Hash, t.conf.BumpPercent)
+	if err != nil {
+		t.l.Error(fmt.Errorf("tracker - bump tx %s: %w", rec.Hash, err))
+
+		return
+	}
+
+	t.l.Info(
+		"tracker - tx %s pending since block %v, replaced by %s",
+		rec.Hash, rec.SentBlock, out.Hash,
+	)
+}
+
+// Check returns transaction with status known by node
+func (t *Tracker) Check(
+	ctx context.Context,
+	rec entities.Transaction,
+) (
+	out entities.Transaction,
+	err error,
+) {
+	out = rec
+
+	auth, err := t.tc.ethClient(ctx)
+	if err != nil {
+		return
+	}
+	hash := eth.ToHash(rec.Hash)
+
+	// read nonce before receipt, so mined nonce without receipt
+	// surely means tx was replaced
+	mined, err := auth.Client.NonceAt(ctx, eth.ToAddress(rec.From), nil)
+	if err != nil {
+		return
+	}
+
+	receipt, err := auth.Client.TransactionReceipt(ctx, hash)
+	if err == nil {
+		out, err = t.tc.receiptStatus(ctx, rec, receipt)
+		t.confirm(ctx, auth, rec, false)
+
+		return
+	}
+	if !errors.Is(err, ethereum.NotFound) {
+		return
+	}
+
+	_, _, err = auth.Client.TransactionByHash(ctx, hash)
+	if err == nil {
+		// still in mempool
+		return
+	}
+	if !errors.Is(err, ethereum.NotFound) {
+		return
+	}
+	err = nil
+
+	if mined <= rec.Nonce && time.Since(rec.SentAt) < t.conf.DropAfter {
+		// not propagated yet
+		return
+	}
+
+	out.Status = entities.TxDropped
+	out.UpdatedAt = time.Now().UTC()
+	t.confirm(ctx, auth, rec, true)
+
+	return
+}
+
+// confirm lets nonce manager of sender forget tracked tx. Nonce of
+// dropped tx is filled by self-transfer while higher nonces of sender
+// are in flight, otherwise it's released to be reused by the next tx
+func (t *Tracker) confirm(
+	ctx context.Context,
+	auth *eth.Client,
+	rec entities.Transaction,
+	dropped bool,
+) {
+	from := eth.ToAddress(rec.From)
+
+	nonces := auth.Wallets().Nonces(from)
+	if nonces == nil {
+		return
+	}
+
+	if !dropped {
+		nonces.Confirm(rec.Nonce)
+
+		return
+	}
+
+	if t.fillGap(ctx, auth, rec, nonces.InFlight()) {
+		return
+	}
+
+	nonces.Release(rec.Nonce)
+
+	err := nonces.Resync(ctx)
+	if err != nil {
+		t.l.Error(fmt.Errorf("tracker - resync nonce: %w", err))
+	}
+}
+
+// fillGap sends self-transfer with nonce of dropped tx if a higher
+// nonce of the same sender waits behind it
+func (t *Tracker) fillGap(
+	ctx context.Context,
+	auth *eth.Client,
+	rec entities.Transaction,
+	inflight map[uint64]common.Hash,
+) (
+	filled bool,
+) {
+	gap := false
+	for nonce := range inflight {
+		if nonce > rec.Nonce {
+			gap = true
+
+			break
+		}
+	}
+	if !gap {
+		return
+	}
+
+	tx, err := auth.FillNonce(ctx, eth.ToAddress(rec.From), rec.Nonce)
+	if err != nil {
+		t.l.Error(fmt.Errorf("tracker - fill nonce %v: %w", rec.Nonce, err))
+
+		return
+	}
+
+	_, err = t.tc.track(ctx, "fillNonce", tx)
+	if err != nil {
+		t.l.Error(fmt.Errorf("tracker - track tx %s: %w", tx.Hash().Hex(), err))
+	}
+
+	t.l.Info(
+		"tracker - tx %s dropped, nonce %v filled by %s",
+		rec.Hash, rec.Nonce, tx.Hash().Hex(),
+	)
+	filled = true
+
+	return
+}
+
+func (tc *TradeCase) receiptStatus(
+	ctx context.Context,
+	rec entities.Transaction,
+	receipt *types.Receipt,
+) (
+	out entities.Transaction,
+	err error,
+) {
+	out = rec
+	out.UpdatedAt = time.Now().UTC()
+	out.Block = receipt.BlockNumber.Uint64()
+	out.GasUsed = receipt.GasUsed
+	out.EffectiveGasPrice = entities.NewAmount(receipt.EffectiveGasPrice)
+
+	if receipt.Status == types.ReceiptStatusSuccessful {
+		out.Status = entities.TxMined
+
+		return
+	}
+	out.Status = entities.TxReverted
+	out.RevertReason = tc.revertReason(ctx, receipt)
+
+	return
+}
+
+// revertReason replays reverted tx & decodes reason with contract abi
+func (tc *TradeCase) revertReason(
+	ctx context.Context,
+	receipt *types.Receipt,
+) string {
+	auth, err := tc.ethClient(ctx)
+	if err != nil {
+		return fmt.Sprintf("unknown: %s", err)
+	}
+
+	tx, _, err := auth.Client.TransactionByHash(ctx, receipt.TxHash)
+	if err != nil {
+		return fmt.Sprintf("unknown: %s", err)
+	}
+
+	data, err := auth.ReplayTx(ctx, tx, receipt.BlockNumber)
+	if err != nil {
+		return fmt.Sprintf("unknown: %s", err)
+	}
+	if len(data) == 0 {
+		return "unknown: replay succeeded"
+	}
+
+	reason, err := tc.Contract.Api().RevertReason(data)
+	if err != nil {
+		return hexutil.Encode(data)
+	}
+
+	return reason
+}
+
+// track stores sent transaction as pending to be followed by tracker
+func (tc *TradeCase) track(
+	ctx context.Context,
+	method string,
+	t *types.Transaction,
+) (
+	rec entities.Transaction,
+	err error,
+) {
+	rec, err = tc.txRecord(ctx, method, t)
+	if err != nil {
+		return
+	}
+
+	err = tc.Repo.AddTx(ctx, _defaultTxTable, rec)
+
+	return
+}
+
+func (tc *TradeCase) txRecord(
+	ctx context.Context,
+	method string,
+	t *types.Transaction,
+) (
+	rec entities.Transaction,
+	err error,
+) {
+	from, err := eth.Sender(t)
+	if err != nil {
+		return
+	}
+
+	now := time.Now().UTC()
+	rec = entities.Transaction{
+		Hash:      t.Hash().Hex(),
+		Method:    method,
+		From:      from.Hex(),
+		Nonce:     t.Nonce(),
+		Status:    entities.TxPending,
+		SentAt:    now,
+		UpdatedAt: now,
+	}
+	if t.To() != nil {
+		rec.To = t.To().Hex()
+	}
+
+	// block is only needed to count blocks for fee bumping
+	auth, err := tc.ethClient(ctx)
+	if err != nil {
+		return
+	}
+
+	block, _err := auth.Client.BlockNumber(ctx)
+	if _err == nil {
+		rec.SentBlock = block
+	}
+
+	return
+}
+
+// GetTx returns tracked transaction by hash
+func (tc *TradeCase) GetTx(
+	ctx context.Context,
+	hash string,
+) (
+	tx entities.Transaction,
+	err error,
+) {
+	tx, err = tc.Repo.GetTx(ctx, _defaultTxTable, hash)
+
+	return
+}
+
+// ListTxs returns tracked transactions, all if status is empty
+func (tc *TradeCase) ListTxs(
+	ctx context.Context,
+	status string,
+) (
+	txs []entities.Transaction,
+	err error,
+) {
+	all, err := tc.Repo.ListTxs(ctx, _defaultTxTable)
+	if err != nil {
+		return
+	}
+
+	txs = make([]entities.Transaction, 0, len(all))
+	for _, tx := range all {
+		if status == "" || tx.Status == status {
+			txs = append(txs, tx)
+		}
+	}
+
+	return
+}
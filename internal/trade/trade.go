@@ -2,11 +2,20 @@ package trade
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/antonyuhnovets/flash-loan-arbitrage/internal/entities"
 	eth "github.com/antonyuhnovets/flash-loan-arbitrage/pkg/ethereum"
 )
 
+// ErrNotOwnerSender is returned when tx to replace by owner only call
+// is sent by a pooled wallet, contract would revert replacement
+var ErrNotOwnerSender = errors.New("tx is not sent by contract owner")
+
 func (tc *TradeCase) AddBaseToken(
 	ctx context.Context,
 	address string,
@@ -25,31 +34,24 @@ func (tc *TradeCase) AddBaseToken(
 		err = fmt.Errorf("token %v already added", address)
 		return
 	}
-	fmt.Println("sending tx")
-
-	auth := tc.Provider.GetClient(ctx).(*eth.Client)
 
-	b, err := auth.GetNextTransaction(ctx)
+	auth, err := tc.ethClient(ctx)
 	if err != nil {
-
 		return
 	}
 
-	t, err := tc.Contract.Api().Transactor().AddBaseToken(
-		b, eth.ToAddress(address),
-	)
+	t, err := auth.Transact(ctx, func(b *bind.TransactOpts) (
+		*types.Transaction, error,
+	) {
+		return tc.Contract.Api().Transactor().AddBaseToken(
+			b, eth.ToAddress(address),
+		)
+	})
 	if err != nil {
-		fmt.Println(err)
-
 		return
 	}
 
-	tx, isPending, err := auth.Client.TransactionByHash(ctx, t.Hash())
-	if err != nil {
-		return
-	}
-
-	fmt.Println(isPending)
+	tx, err = tc.track(ctx, "addBaseToken", t)
 
 	return
 }
@@ -74,32 +76,23 @@ func (tc *TradeCase) RmBaseToken(
 		return
 	}
 
-	fmt.Println("sending tx")
-
-	auth := tc.Provider.GetClient(ctx).(*eth.Client)
-
-	b, err := auth.GetNextTransaction(ctx)
-	if err != nil {
-		fmt.Println(err)
-
-		return
-	}
-
-	t, err := tc.Contract.Api().Transactor().RemoveBaseToken(
-		b, eth.ToAddress(address),
-	)
+	auth, err := tc.ethClient(ctx)
 	if err != nil {
-		fmt.Println(err)
-
 		return
 	}
 
-	tx, isPending, err := auth.Client.TransactionByHash(ctx, t.Hash())
+	t, err := auth.Transact(ctx, func(b *bind.TransactOpts) (
+		*types.Transaction, error,
+	) {
+		return tc.Contract.Api().Transactor().RemoveBaseToken(
+			b, eth.ToAddress(address),
+		)
+	})
 	if err != nil {
 		return
 	}
 
-	fmt.Println(isPending)
+	tx, err = tc.track(ctx, "removeBaseToken", t)
 
 	return
 }
@@ -110,42 +103,52 @@ func (tc *TradeCase) Withdraw(
 	tx interface{},
 	err error,
 ) {
-	fmt.Println("sending tx")
-	auth := tc.Provider.GetClient(ctx).(*eth.Client)
+	auth, err := tc.ethClient(ctx)
+	if err != nil {
+		return
+	}
 
 	// bal, err := auth.Client.BalanceAt(
 	// 	ctx, eth.ToAddress(tc.Contract.Address()), nil)
 	// if err != nil {
 	// 	return
 	// }
-	b, err := auth.GetNextTransaction(ctx)
-	if err != nil {
-		fmt.Println(err)
+	t, err := auth.Transact(ctx, func(b *bind.TransactOpts) (
+		*types.Transaction, error,
+	) {
+		// b.Value = bal.Sub
 
+		return tc.Contract.Api().Transactor().Withdraw(b)
+	})
+	if err != nil {
 		return
 	}
 
-	// b.Value = bal.Sub
-
-	t, err := tc.Contract.Api().Transactor().Withdraw(b)
-	if err != nil {
-		fmt.Println(err)
+	tx, err = tc.track(ctx, "withdraw", t)
 
-		return
-	}
+	return
+}
 
-	tx, isPending, err := auth.Client.TransactionByHash(ctx, t.Hash())
+func (tc *TradeCase) BaseTokens(ctx context.Context) (
+	tokens []string,
+	err error,
+) {
+	addrs, err := tc.Contract.Api().Caller().GetBaseTokens(
+		eth.CallOpts(ctx),
+	)
 	if err != nil {
 		return
 	}
 
-	fmt.Println(isPending)
+	for _, addr := range addrs {
+		tokens = append(tokens, eth.FromAddress(addr))
+	}
 
 	return
 }
 
 func (tc *TradeCase) GetProfit(ctx context.Context, pool0, pool1 string) (
-	profit int,
+	profit *entities.Amount,
 	baseToken string,
 	err error,
 ) {
@@ -158,38 +161,54 @@ func (tc *TradeCase) GetProfit(ctx context.Context, pool0, pool1 string) (
 		return
 	}
 
-	profit = eth.FromBigInt(res.Profit)
+	profit = entities.NewAmount(res.Profit)
 	baseToken = eth.FromAddress(res.BaseToken)
 
 	return
 }
 
+// Arbitrage sends flashArbitrage tx if net profit is above floor
 func (tc *TradeCase) Arbitrage(ctx context.Context, pool0, pool1 string) (
 	tx interface{},
 	err error,
 ) {
-	fmt.Println("sending tx")
-
-	auth := tc.Provider.GetClient(ctx).(*eth.Client)
-
-	b, err := auth.GetNextTransaction(ctx)
+	est, err := tc.EstimateNetProfit(ctx, pool0, pool1)
 	if err != nil {
 		return
 	}
+	if !est.Profitable {
+		err = fmt.Errorf("%w: %s", ErrNotProfitable, est.Reason)
 
-	t, err := tc.Contract.Api().Transactor().FlashArbitrage(
-		b, eth.ToAddress(pool0), eth.ToAddress(pool1),
-	)
+		return
+	}
+
+	tx, err = tc.arbitrage(ctx, pool0, pool1)
+
+	return
+}
+
+func (tc *TradeCase) arbitrage(ctx context.Context, pool0, pool1 string) (
+	tx interface{},
+	err error,
+) {
+	// anyone may call flashArbitrage, so it's sent from pooled wallet
+	auth, err := tc.ethClient(ctx)
 	if err != nil {
 		return
 	}
 
-	tx, isPending, err := auth.Client.TransactionByHash(ctx, t.Hash())
+	t, err := auth.TransactPooled(ctx, func(b *bind.TransactOpts) (
+		*types.Transaction, error,
+	) {
+		return tc.Contract.Api().Transactor().FlashArbitrage(
+			b, eth.ToAddress(pool0), eth.ToAddress(pool1),
+		)
+	})
 	if err != nil {
 		return
 	}
 
-	fmt.Println(isPending)
+	tx, err = tc.track(ctx, "flashArbitrage", t)
 
 	return
 }
@@ -201,13 +220,23 @@ func (tc *TradeCase) ReplaceTxWithAddBaseToken(
 	tx interface{},
 	err error,
 ) {
-	auth := tc.Provider.GetClient(ctx).(*eth.Client)
-	b, err := auth.ReplaceTx(ctx, hash)
+	auth, err := tc.ethClient(ctx)
+	if err != nil {
+		return
+	}
+	b, err := tc.ownerReplaceOpts(ctx, auth, hash)
 	if err != nil {
 		return
 	}
 
-	tx, err = tc.Contract.Api().Transactor().AddBaseToken(b, eth.ToAddress(token))
+	t, err := tc.Contract.Api().Transactor().AddBaseToken(b, eth.ToAddress(token))
+	if err != nil {
+		return
+	}
+
+	auth.Sent(t)
+
+	tx, err = tc.replaced(ctx, hash, "addBaseToken", t)
 
 	return
 }
@@ -219,13 +248,55 @@ func (tc *TradeCase) ReplaceTxWithRmBaseToken(
 	tx interface{},
 	err error,
 ) {
-	auth := tc.Provider.GetClient(ctx).(*eth.Client)
-	b, err := auth.ReplaceTx(ctx, hash)
+	auth, err := tc.ethClient(ctx)
+	if err != nil {
+		return
+	}
+	b, err := tc.ownerReplaceOpts(ctx, auth, hash)
 	if err != nil {
 		return
 	}
 
-	tx, err = tc.Contract.Api().Transactor().RemoveBaseToken(b, eth.ToAddress(token))
+	t, err := tc.Contract.Api().Transactor().RemoveBaseToken(b, eth.ToAddress(token))
+	if err != nil {
+		return
+	}
+
+	auth.Sent(t)
+
+	tx, err = tc.replaced(ctx, hash, "removeBaseToken", t)
+
+	return
+}
+
+// ownerReplaceOpts returns options replacing pending tx, which has to
+// be sent by owner of contract for owner only call to succeed
+func (tc *TradeCase) ownerReplaceOpts(
+	ctx context.Context,
+	auth *eth.Client,
+	hash string,
+) (
+	opts *bind.TransactOpts,
+	err error,
+) {
+	owner, err := tc.Contract.Api().Caller().Owner(eth.CallOpts(ctx))
+	if err != nil {
+		return
+	}
+
+	opts, err = auth.ReplaceTx(ctx, hash)
+	if err != nil {
+		return
+	}
+	if opts.From != owner {
+		err = fmt.Errorf(
+			"%w: tx %s sent by %s, owner %s",
+			ErrNotOwnerSender, hash, opts.From.Hex(), owner.Hex(),
+		)
+		opts = nil
+
+		return
+	}
 
 	return
 }
@@ -2,28 +2,51 @@ package trade
 
 import (
 	"context"
+	"errors"
+	"math/big"
+	"sort"
 
 	"github.com/antonyuhnovets/flash-loan-arbitrage/internal/entities"
+	"github.com/antonyuhnovets/flash-loan-arbitrage/pkg/bundle"
+	eth "github.com/antonyuhnovets/flash-loan-arbitrage/pkg/ethereum"
 	"github.com/antonyuhnovets/flash-loan-arbitrage/pkg/pairs"
+	"github.com/antonyuhnovets/flash-loan-arbitrage/pkg/reserves"
+	"github.com/antonyuhnovets/flash-loan-arbitrage/pkg/routes"
+	"github.com/antonyuhnovets/flash-loan-arbitrage/pkg/simulator"
 )
 
 type TradeCase struct {
 	Repo     Repository
 	Provider TradeProvider
 	Contract SmartContract
+
+	weth         string
+	minNetProfit *big.Int
+	relay        *bundle.Relay
+	relayWindow  int
+	finder       *routes.Finder
 }
 
+var errUnsupportedClient = errors.New("provider client is not an ethereum client")
+
 func New(
 	r Repository,
 	p TradeProvider,
 	c SmartContract,
+	opts ...Option,
 ) (
 	tc *TradeCase,
 ) {
 	tc = &TradeCase{
-		Repo:     r,
-		Provider: p,
-		Contract: c,
+		Repo:         r,
+		Provider:     p,
+		Contract:     c,
+		minNetProfit: big.NewInt(0),
+		finder:       routes.NewFinder(),
+	}
+
+	for _, opt := range opts {
+		opt(tc)
 	}
 
 	return
@@ -71,6 +94,7 @@ func (tc *TradeCase) SetTokens(
 func (tc *TradeCase) SetProfitablePairs(
 	ctx context.Context, where string,
 ) (
+	rejected []Estimate,
 	err error,
 ) {
 	pools, err := tc.Repo.ListPools(
@@ -81,8 +105,9 @@ func (tc *TradeCase) SetProfitablePairs(
 		return
 	}
 
+	// contract trades uniswap-v2 like pairs only
 	tradeMap, err := pairs.GetTradeMap(
-		pools,
+		pairs.ExecutablePools(pools),
 	)
 	if err != nil {
 		return
@@ -95,7 +120,7 @@ func (tc *TradeCase) SetProfitablePairs(
 		return
 	}
 
-	prof, ok, err := tc.GetProfitable(
+	prof, rejected, ok, err := tc.GetProfitable(
 		ctx,
 		tradePairs,
 	)
@@ -114,27 +139,141 @@ func (tc *TradeCase) SetProfitablePairs(
 	return
 }
 
+// LoadReserves fetches reserves of all stored pools pinned to one block,
+// latest block is used if block is nil
+func (tc *TradeCase) LoadReserves(
+	ctx context.Context,
+	where string,
+	block *big.Int,
+) (
+	snap *reserves.Snapshot,
+	err error,
+) {
+	pools, err := tc.Repo.ListPools(
+		ctx,
+		where,
+	)
+	if err != nil {
+		return
+	}
+
+	snap, err = tc.PoolReserves(ctx, pools, block)
+
+	return
+}
+
+// ethClient returns ethereum client of provider
+func (tc *TradeCase) ethClient(ctx context.Context) (
+	auth *eth.Client,
+	err error,
+) {
+	auth, ok := tc.Provider.GetClient(ctx).(*eth.Client)
+	if !ok {
+		err = errUnsupportedClient
+	}
+
+	return
+}
+
+// PoolReserves fetches reserves of given pools pinned to one block
+func (tc *TradeCase) PoolReserves(
+	ctx context.Context,
+	pools []entities.Pool,
+	block *big.Int,
+) (
+	snap *reserves.Snapshot,
+	err error,
+) {
+	auth, err := tc.ethClient(ctx)
+	if err != nil {
+		return
+	}
+
+	loader := reserves.NewLoader(
+		auth.RPC(),
+		reserves.DefaultBatchSize,
+	)
+	snap, err = loader.Load(ctx, pools, block)
+
+	return
+}
+
+// Opportunity is a trade pair with simulated contract result
+type Opportunity struct {
+	Pair       entities.TradePair `json:"pair"`
+	Result     simulator.Result   `json:"result"`
+	ProfitWETH *big.Int           `json:"profitWeth"` // profit priced in WETH
+}
+
+// RankPairs simulates profit of every pair on snapshot reserves and
+// sorts profitable pairs by profit priced in WETH, profits of base
+// tokens differ in decimals & value. Pairs whose base token has no
+// WETH pool in snapshot can't be ranked & are skipped
+func (tc *TradeCase) RankPairs(
+	snap *reserves.Snapshot,
+	from []entities.TradePair,
+	baseTokens []string,
+) (
+	out []Opportunity,
+) {
+	prices := priceBook{weth: tc.weth, snap: snap}
+
+	for _, pair := range from {
+		if !pairs.Executable(pair) {
+			continue
+		}
+
+		r0, ok0 := snap.Get(pair.Pool0.Address)
+		r1, ok1 := snap.Get(pair.Pool1.Address)
+		if !ok0 || !ok1 {
+			continue
+		}
+
+		res, err := simulator.GetProfit(r0, r1, baseTokens)
+		if err != nil || res.Profit.Sign() <= 0 {
+			continue
+		}
+
+		profit, err := prices.inWETH(res.BaseToken, res.Profit)
+		if err != nil {
+			continue
+		}
+
+		out = append(out, Opportunity{pair, res, profit})
+	}
+
+	sort.SliceStable(out, func(i, j int) bool {
+		return out[i].ProfitWETH.Cmp(out[j].ProfitWETH) > 0
+	})
+
+	return
+}
+
+// GetProfitable keeps pairs with net profit above floor,
+// rejected pairs are returned with reason
 func (tc *TradeCase) GetProfitable(ctx context.Context, from []entities.TradePair) (
 	out []entities.TradePair,
+	rejected []Estimate,
 	ok bool,
 	err error,
 ) {
 	ok = false
 	for _, pair := range from {
-		prof, _, _err := tc.GetProfit(
+		est, _err := tc.EstimateNetProfit(
 			ctx,
 			pair.Pool0.Address,
 			pair.Pool1.Address,
 		)
-		if err != nil {
-			err = _err
-
-			return
+		if _err != nil {
+			est.Reason = _err.Error()
 		}
-		if prof > 0 {
-			out = append(out, pair)
-			ok = true
+		if !est.Profitable {
+			rejected = append(rejected, est)
+
+			continue
 		}
+		out = append(out, pair)
+		ok = true
 	}
 	return
 }
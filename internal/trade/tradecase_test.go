@@ -0,0 +1,96 @@
+package trade
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/antonyuhnovets/flash-loan-arbitrage/internal/entities"
+	"github.com/antonyuhnovets/flash-loan-arbitrage/pkg/reserves"
+	"github.com/antonyuhnovets/flash-loan-arbitrage/pkg/simulator"
+)
+
+// otherProvider serves a client that is not an ethereum one
+type otherProvider struct {
+	TradeProvider
+}
+
+func (otherProvider) GetClient(context.Context) interface{} {
+	return struct{}{}
+}
+
+func TestUnsupportedClient(t *testing.T) {
+	tc := New(nil, otherProvider{}, nil)
+
+	if _, err := tc.Withdraw(context.Background()); !errors.Is(err, errUnsupportedClient) {
+		t.Errorf("withdraw: %v", err)
+	}
+	if _, err := tc.PoolReserves(context.Background(), nil, nil); !errors.Is(err, errUnsupportedClient) {
+		t.Errorf("pool reserves: %v", err)
+	}
+}
+
+func TestRankPairsInWETH(t *testing.T) {
+	const (
+		weth  = "0x0000000000000000000000000000000000000b01"
+		other = "0x0000000000000000000000000000000000000b02"
+		quote = "0x0000000000000000000000000000000000000b03"
+		usd   = "0x0000000000000000000000000000000000000b04" // 1 WETH is 1e12 units
+	)
+	ether := func(n int64) *big.Int {
+		return new(big.Int).Mul(big.NewInt(n), big.NewInt(1e18))
+	}
+
+	snap := &reserves.Snapshot{Reserves: make(map[common.Address]simulator.Reserves)}
+	pool := func(addr, token0, token1 string, r0, r1 *big.Int) entities.Pool {
+		snap.Reserves[common.HexToAddress(addr)] = simulator.Reserves{
+			Pool: addr, Token0: token0, Token1: token1, Reserve0: r0, Reserve1: r1,
+		}
+
+		return entities.Pool{
+			Address: addr,
+			Pair: entities.TokenPair{
+				Token0: entities.Token{Address: token0},
+				Token1: entities.Token{Address: token1},
+			},
+		}
+	}
+
+	// about 0.02 WETH of profit
+	inWETH := entities.TradePair{
+		Pool0: pool("0x0000000000000000000000000000000000000a01", weth, other, ether(1000), ether(2000000)),
+		Pool1: pool("0x0000000000000000000000000000000000000a02", weth, other, ether(1010), ether(1980000)),
+	}
+	// about 1e18 units of cheap token, larger as raw number
+	inUSD := entities.TradePair{
+		Pool0: pool("0x0000000000000000000000000000000000000a03", quote, usd, ether(5000000), ether(2500)),
+		Pool1: pool("0x0000000000000000000000000000000000000a04", quote, usd, ether(4700000), ether(2500)),
+	}
+	pool("0x0000000000000000000000000000000000000a05", weth, usd, ether(1), ether(1_000_000_000_000))
+
+	tc := New(nil, nil, nil, WETH(weth))
+
+	opps := tc.RankPairs(snap, []entities.TradePair{inUSD, inWETH}, []string{weth, usd})
+	if len(opps) != 2 {
+		t.Fatalf("opportunities %+v", opps)
+	}
+	if opps[0].Pair.Pool0.Address != inWETH.Pool0.Address {
+		t.Errorf("ranked by raw profit: %v of %s first",
+			opps[0].Result.Profit, opps[0].Result.BaseToken)
+	}
+	if opps[0].Result.Profit.Cmp(opps[1].Result.Profit) >= 0 {
+		t.Errorf("raw profits %v, %v", opps[0].Result.Profit, opps[1].Result.Profit)
+	}
+	if opps[0].ProfitWETH.Cmp(opps[0].Result.Profit) != 0 {
+		t.Errorf("WETH profit %v, expected %v", opps[0].ProfitWETH, opps[0].Result.Profit)
+	}
+
+	// base token without WETH pool can't be ranked
+	delete(snap.Reserves, common.HexToAddress("0x0000000000000000000000000000000000000a05"))
+	if opps = tc.RankPairs(snap, []entities.TradePair{inUSD}, []string{usd}); len(opps) != 0 {
+		t.Errorf("unpriced opportunities %+v", opps)
+	}
+}
@@ -0,0 +1,330 @@
+package trade
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/antonyuhnovets/flash-loan-arbitrage/pkg/logger"
+	"github.com/antonyuhnovets/flash-loan-arbitrage/pkg/pairs"
+)
+
+const (
+	_defaultTraderInterval = 12 * time.Second
+	_defaultPoolsTable     = "pools"
+)
+
+type TraderConfig struct {
+	Subscribe bool
+	Interval  time.Duration
+	MinProfit *big.Int // in WETH wei
+	MaxTrades int      // per block, 0 is unlimited
+	DryRun    bool
+	Private   bool // send trades as relay bundles
+}
+
+// Trader runs arbitrage on every new block
+type Trader struct {
+	tc     *TradeCase
+	conf   TraderConfig
+	l      logger.Interface
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+	notify chan error
+}
+
+func NewTrader(
+	tc *TradeCase,
+	conf TraderConfig,
+	l logger.Interface,
+) (
+	t *Trader,
+) {
+	if conf.Interval <= 0 {
+		conf.Interval = _defaultTraderInterval
+	}
+	if conf.MinProfit == nil {
+		conf.MinProfit = big.NewInt(0)
+	}
+
+	t = &Trader{
+		tc:     tc,
+		conf:   conf,
+		l:      l,
+		notify: make(chan error, 1),
+	}
+
+	return
+}
+
+// Start runs trading loop until context is done or Shutdown called
+func (t *Trader) Start(ctx context.Context) {
+	ctx, t.cancel = context.WithCancel(ctx)
+
+	t.wg.Add(1)
+	go func() {
+		defer t.wg.Done()
+
+		err := t.run(ctx)
+		if err != nil && ctx.Err() == nil {
+			t.notify <- err
+		}
+		close(t.notify)
+	}()
+}
+
+// Notify -.
+func (t *Trader) Notify() <-chan error {
+	return t.notify
+}
+
+// Shutdown stops loop and waits for current block processing
+func (t *Trader) Shutdown() {
+	if t.cancel != nil {
+		t.cancel()
+	}
+	t.wg.Wait()
+}
+
+func (t *Trader) run(ctx context.Context) (
+	err error,
+) {
+	if t.conf.Subscribe {
+		err = t.subscribe(ctx)
+		if err == nil || ctx.Err() != nil {
+			return
+		}
+		t.l.Warn(
+			"trader - subscribe: %s, fallback to polling", err,
+		)
+	}
+
+	err = t.poll(ctx)
+
+	return
+}
+
+func (t *Trader) subscribe(ctx context.Context) (
+	err error,
+) {
+	auth, err := t.tc.ethClient(ctx)
+	if err != nil {
+		return
+	}
+
+	heads := make(chan *types.Header)
+
+	sub, err := auth.Client.SubscribeNewHead(ctx, heads)
+	if err != nil {
+		return
+	}
+	defer sub.Unsubscribe()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case err = <-sub.Err():
+			return
+		case head := <-heads:
+			t.processBlock(ctx, head.Number)
+		}
+	}
+}
+
+func (t *Trader) poll(ctx context.Context) (
+	err error,
+) {
+	auth, err := t.tc.ethClient(ctx)
+	if err != nil {
+		return
+	}
+
+	ticker := time.NewTicker(t.conf.Interval)
+	defer ticker.Stop()
+
+	var last uint64
+
+	for {
+		number, _err := auth.Client.BlockNumber(ctx)
+		if _err != nil && ctx.Err() == nil {
+			t.l.Error(fmt.Errorf("trader - poll: %w", _err))
+		} else if _err == nil && number > last {
+			last = number
+			t.processBlock(ctx, new(big.Int).SetUint64(number))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (t *Trader) processBlock(ctx context.Context, block *big.Int) {
+	opps, err := t.OnBlock(ctx, block)
+	if err != nil {
+		t.l.Error(fmt.Errorf("trader - block %s: %w", block, err))
+
+		return
+	}
+
+	t.l.Info("trader - block %s: %v trades", block, len(opps))
+}
+
+// OnBlock refreshes reserves, ranks pairs and trades the best of them,
+// returned opportunities are the traded ones
+func (t *Trader) OnBlock(
+	ctx context.Context,
+	block *big.Int,
+) (
+	opps []Opportunity,
+	err error,
+) {
+	pools, err := t.tc.Repo.ListPools(ctx, _defaultPoolsTable)
+	if err != nil {
+		return
+	}
+	pools = pairs.ExecutablePools(pools)
+
+	snap, err := t.tc.PoolReserves(ctx, pools, block)
+	if err != nil {
+		return
+	}
+
+	tradeMap, err := pairs.GetTradeMap(pools)
+	if err != nil {
+		return
+	}
+
+	tradePairs, err := pairs.GetTradePairs(tradeMap)
+	if err != nil {
+		return
+	}
+
+	baseTokens, err := t.tc.BaseTokens(ctx)
+	if err != nil {
+		return
+	}
+
+	// gas is priced with reserves of the block, not reloaded per pair
+	prices := &priceBook{weth: t.tc.weth, snap: snap}
+
+	// pairs rejected by estimation or simulation don't count as trades,
+	// lower ranked ones are tried until the limit is sent
+	for _, opp := range t.tc.RankPairs(snap, tradePairs, baseTokens) {
+		if opp.ProfitWETH.Cmp(t.conf.MinProfit) < 0 {
+			break
+		}
+		if !t.execute(ctx, opp, prices) {
+			continue
+		}
+		opps = append(opps, opp)
+
+		if t.conf.MaxTrades > 0 && len(opps) >= t.conf.MaxTrades {
+			break
+		}
+	}
+
+	return
+}
+
+// execute checks net profit & simulates trade before sending it,
+// it reports whether trade was sent
+func (t *Trader) execute(
+	ctx context.Context,
+	opp Opportunity,
+	prices *priceBook,
+) (
+	sent bool,
+) {
+	msg := fmt.Sprintf(
+		"pools %s/%s profit %s of %s",
+		opp.Pair.Pool0.Address,
+		opp.Pair.Pool1.Address,
+		opp.Result.Profit,
+		opp.Result.BaseToken,
+	)
+
+	est, err := t.tc.estimateNetProfit(
+		ctx,
+		opp.Pair.Pool0.Address,
+		opp.Pair.Pool1.Address,
+		prices,
+	)
+	if err != nil {
+		t.l.Error(fmt.Errorf("trader - estimate - %s: %w", msg, err))
+
+		return
+	}
+	if !est.Profitable {
+		t.l.Info("trader - rejected - %s: %s", msg, est.Reason)
+
+		return
+	}
+	msg = fmt.Sprintf("%s, net %s", msg, est.NetProfit)
+
+	sim, err := t.tc.Simulate(
+		ctx,
+		opp.Pair.Pool0.Address,
+		opp.Pair.Pool1.Address,
+		true,
+	)
+	if err != nil {
+		t.l.Error(fmt.Errorf("trader - simulate - %s: %w", msg, err))
+
+		return
+	}
+	if !sim.Success {
+		t.l.Info("trader - rejected - %s: simulation reverted: %s", msg, sim.Revert)
+
+		return
+	}
+
+	// dry run counts passed trades as sent ones
+	if t.conf.DryRun {
+		t.l.Info("trader - dry run - %s", msg)
+		sent = true
+
+		return
+	}
+
+	if t.conf.Private {
+		res, err := t.tc.arbitrageBundle(
+			ctx,
+			opp.Pair.Pool0.Address,
+			opp.Pair.Pool1.Address,
+		)
+		if err != nil {
+			t.l.Error(fmt.Errorf("trader - bundle - %s: %w", msg, err))
+
+			return
+		}
+
+		t.l.Info("trader - bundle - %s: %+v", msg, res)
+		sent = true
+
+		return
+	}
+
+	tx, err := t.tc.arbitrage(
+		ctx,
+		opp.Pair.Pool0.Address,
+		opp.Pair.Pool1.Address,
+	)
+	if err != nil {
+		t.l.Error(fmt.Errorf("trader - arbitrage - %s: %w", msg, err))
+
+		return
+	}
+
+	t.l.Info("trader - arbitrage - %s: %v", msg, tx)
+	sent = true
+
+	return
+}
@@ -0,0 +1,121 @@
+package bundle
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// ReceivedBundle is a bundle accepted by local relay
+type ReceivedBundle struct {
+	Signer common.Address
+	Block  uint64
+	Txs    []*types.Transaction
+}
+
+// LocalRelay is a stand-in of bundle relay for tests & local runs,
+// it checks signature header & records bundles without mining them
+type LocalRelay struct {
+	mu      sync.Mutex
+	bundles []ReceivedBundle
+	reject  map[uint64]string
+}
+
+func NewLocalRelay() *LocalRelay {
+	return &LocalRelay{
+		reject: make(map[uint64]string),
+	}
+}
+
+// Reject makes relay answer with error for bundles targeting block
+func (lr *LocalRelay) Reject(block uint64, msg string) {
+	lr.mu.Lock()
+	defer lr.mu.Unlock()
+
+	lr.reject[block] = msg
+}
+
+// Bundles returns accepted bundles in order of arrival
+func (lr *LocalRelay) Bundles() []ReceivedBundle {
+	lr.mu.Lock()
+	defer lr.mu.Unlock()
+
+	return append([]ReceivedBundle(nil), lr.bundles...)
+}
+
+func (lr *LocalRelay) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	signer, err := Verify(body, r.Header.Get(SignatureHeader))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusForbidden)
+
+		return
+	}
+
+	var req struct {
+		ID     int              `json:"id"`
+		Method string           `json:"method"`
+		Params []sendBundleArgs `json:"params"`
+	}
+	err = json.Unmarshal(body, &req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	res := rpcResponse{Version: "2.0", ID: req.ID}
+	defer func() {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(res)
+	}()
+
+	if req.Method != "eth_sendBundle" || len(req.Params) != 1 {
+		res.Error = &rpcError{Code: -32601, Message: "method not found"}
+
+		return
+	}
+	args := req.Params[0]
+
+	lr.mu.Lock()
+	defer lr.mu.Unlock()
+
+	if msg, ok := lr.reject[uint64(args.BlockNumber)]; ok {
+		res.Error = &rpcError{Code: -32000, Message: msg}
+
+		return
+	}
+
+	bundle := ReceivedBundle{Signer: signer, Block: uint64(args.BlockNumber)}
+	hashes := make([]byte, 0, len(args.Txs)*common.HashLength)
+
+	for _, raw := range args.Txs {
+		tx := new(types.Transaction)
+
+		err = tx.UnmarshalBinary(raw)
+		if err != nil {
+			res.Error = &rpcError{Code: -32602, Message: err.Error()}
+
+			return
+		}
+		bundle.Txs = append(bundle.Txs, tx)
+		hashes = append(hashes, tx.Hash().Bytes()...)
+	}
+	lr.bundles = append(lr.bundles, bundle)
+
+	res.Result, _ = json.Marshal(map[string]string{
+		"bundleHash": hexutil.Encode(crypto.Keccak256(hashes)),
+	})
+}
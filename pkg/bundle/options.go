@@ -0,0 +1,27 @@
+package bundle
+
+import (
+	"net/http"
+	"time"
+)
+
+// Option -.
+type Option func(*Relay)
+
+// HTTPClient sets client used to call relay
+func HTTPClient(cl *http.Client) Option {
+	return func(r *Relay) {
+		if cl != nil {
+			r.http = cl
+		}
+	}
+}
+
+// PollInterval sets how often chain head is checked for target block
+func PollInterval(d time.Duration) Option {
+	return func(r *Relay) {
+		if d > 0 {
+			r.poll = d
+		}
+	}
+}
@@ -0,0 +1,329 @@
+package bundle
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+const (
+	// DefaultWindow is number of blocks bundle is retried for
+	DefaultWindow = 3
+
+	// SignatureHeader carries relay identity: address:signature of body
+	SignatureHeader = "X-Flashbots-Signature"
+
+	_defaultPollInterval = time.Second
+	_defaultTimeout      = 10 * time.Second
+)
+
+var ErrNotIncluded = errors.New("bundle not included in target blocks")
+
+// Chain is a node api used to wait for target blocks
+type Chain interface {
+	BlockNumber(context.Context) (uint64, error)
+	TransactionReceipt(context.Context, common.Hash) (*types.Receipt, error)
+}
+
+// Relay sends signed transactions as private bundles
+type Relay struct {
+	url  string
+	key  *ecdsa.PrivateKey
+	http *http.Client
+	poll time.Duration
+}
+
+func NewRelay(url string, key *ecdsa.PrivateKey, opts ...Option) *Relay {
+	r := &Relay{
+		url:  url,
+		key:  key,
+		http: &http.Client{Timeout: _defaultTimeout},
+		poll: _defaultPollInterval,
+	}
+
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	return r
+}
+
+// BlockResult is outcome of bundle targeting one block
+type BlockResult struct {
+	Block      uint64 `json:"block"`
+	BundleHash string `json:"bundleHash,omitempty"`
+	Included   bool   `json:"included"`
+	Error      string `json:"error,omitempty"`
+}
+
+type Result struct {
+	TxHashes []string      `json:"txHashes"`
+	Blocks   []BlockResult `json:"blocks"`
+	Included bool          `json:"included"`
+}
+
+type sendBundleArgs struct {
+	Txs         []hexutil.Bytes `json:"txs"`
+	BlockNumber hexutil.Uint64  `json:"blockNumber"`
+}
+
+type rpcRequest struct {
+	Version string        `json:"jsonrpc"`
+	ID      int           `json:"id"`
+	Method  string        `json:"method"`
+	Params  []interface{} `json:"params"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+type rpcResponse struct {
+	Version string          `json:"jsonrpc"`
+	ID      int             `json:"id"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+// SendBundle submits txs to be included in given block only
+func (r *Relay) SendBundle(
+	ctx context.Context,
+	txs []*types.Transaction,
+	block uint64,
+) (
+	bundleHash string,
+	err error,
+) {
+	args := sendBundleArgs{BlockNumber: hexutil.Uint64(block)}
+
+	for _, tx := range txs {
+		raw, _err := tx.MarshalBinary()
+		if _err != nil {
+			err = _err
+
+			return
+		}
+		args.Txs = append(args.Txs, raw)
+	}
+
+	body, err := json.Marshal(rpcRequest{
+		Version: "2.0",
+		ID:      1,
+		Method:  "eth_sendBundle",
+		Params:  []interface{}{args},
+	})
+	if err != nil {
+		return
+	}
+
+	sig, err := Sign(body, r.key)
+	if err != nil {
+		return
+	}
+
+	req, err := http.NewRequestWithContext(
+		ctx, http.MethodPost, r.url, bytes.NewReader(body),
+	)
+	if err != nil {
+		return
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(SignatureHeader, sig)
+
+	resp, err := r.http.Do(req)
+	if err != nil {
+		return
+	}
+	defer resp.Body.Close()
+
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return
+	}
+	if resp.StatusCode != http.StatusOK {
+		err = fmt.Errorf("relay status %v: %s", resp.StatusCode, b)
+
+		return
+	}
+
+	var res rpcResponse
+	err = json.Unmarshal(b, &res)
+	if err != nil {
+		return
+	}
+	if res.Error != nil {
+		err = fmt.Errorf("relay error %v: %s", res.Error.Code, res.Error.Message)
+
+		return
+	}
+
+	var out struct {
+		BundleHash string `json:"bundleHash"`
+	}
+	err = json.Unmarshal(res.Result, &out)
+	bundleHash = out.BundleHash
+
+	return
+}
+
+// Submit sends bundle for each block of window starting from first,
+// one block at a time until the last tx of bundle is included
+func (r *Relay) Submit(
+	ctx context.Context,
+	chain Chain,
+	txs []*types.Transaction,
+	first uint64,
+	window int,
+) (
+	res Result,
+	err error,
+) {
+	if len(txs) == 0 {
+		err = fmt.Errorf("empty bundle")
+
+		return
+	}
+	if window <= 0 {
+		window = DefaultWindow
+	}
+
+	for _, tx := range txs {
+		res.TxHashes = append(res.TxHashes, tx.Hash().Hex())
+	}
+	last := txs[len(txs)-1].Hash()
+
+	for block := first; block < first+uint64(window); block++ {
+		br := BlockResult{Block: block}
+
+		hash, _err := r.SendBundle(ctx, txs, block)
+		if _err != nil {
+			br.Error = _err.Error()
+		}
+		br.BundleHash = hash
+
+		err = r.waitBlock(ctx, chain, block)
+		if err != nil {
+			return
+		}
+
+		receipt, _err := chain.TransactionReceipt(ctx, last)
+		if _err == nil {
+			br.Included = receipt.BlockNumber.Uint64() == block
+			res.Included = true
+		} else if !errors.Is(_err, ethereum.NotFound) {
+			err = _err
+
+			return
+		}
+		res.Blocks = append(res.Blocks, br)
+
+		if res.Included {
+			return
+		}
+	}
+
+	err = ErrNotIncluded
+
+	return
+}
+
+func (r *Relay) waitBlock(
+	ctx context.Context,
+	chain Chain,
+	block uint64,
+) (
+	err error,
+) {
+	ticker := time.NewTicker(r.poll)
+	defer ticker.Stop()
+
+	for {
+		var head uint64
+
+		head, err = chain.BlockNumber(ctx)
+		if err != nil || head >= block {
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+			err = ctx.Err()
+
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Sign returns relay signature header value of request body:
+// address of key & its signature of the hex encoded body hash
+func Sign(body []byte, key *ecdsa.PrivateKey) (
+	header string,
+	err error,
+) {
+	hash := accounts.TextHash(
+		[]byte(hexutil.Encode(crypto.Keccak256(body))),
+	)
+
+	sig, err := crypto.Sign(hash, key)
+	if err != nil {
+		return
+	}
+
+	header = fmt.Sprintf(
+		"%s:%s",
+		crypto.PubkeyToAddress(key.PublicKey).Hex(),
+		hexutil.Encode(sig),
+	)
+
+	return
+}
+
+// Verify recovers signer of body from relay signature header
+func Verify(body []byte, header string) (
+	signer common.Address,
+	err error,
+) {
+	addr, sigHex, ok := strings.Cut(header, ":")
+	if !ok {
+		err = fmt.Errorf("malformed signature header")
+
+		return
+	}
+
+	sig, err := hexutil.Decode(sigHex)
+	if err != nil {
+		return
+	}
+
+	hash := accounts.TextHash(
+		[]byte(hexutil.Encode(crypto.Keccak256(body))),
+	)
+
+	pub, err := crypto.SigToPub(hash, sig)
+	if err != nil {
+		return
+	}
+
+	signer = crypto.PubkeyToAddress(*pub)
+	if signer != common.HexToAddress(addr) {
+		err = fmt.Errorf("signature of %s does not match %s", signer, addr)
+	}
+
+	return
+}
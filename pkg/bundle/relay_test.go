@@ -0,0 +1,221 @@
+package bundle
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// stubChain advances one block per head request &
+// mines txs at configured blocks
+type stubChain struct {
+	mu    sync.Mutex
+	head  uint64
+	mined map[common.Hash]uint64
+}
+
+func (sc *stubChain) BlockNumber(context.Context) (uint64, error) {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+
+	sc.head++
+
+	return sc.head, nil
+}
+
+func (sc *stubChain) TransactionReceipt(
+	_ context.Context,
+	hash common.Hash,
+) (
+	*types.Receipt,
+	error,
+) {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+
+	block, ok := sc.mined[hash]
+	if !ok || block > sc.head {
+		return nil, ethereum.NotFound
+	}
+
+	return &types.Receipt{
+		TxHash:      hash,
+		BlockNumber: new(big.Int).SetUint64(block),
+		Status:      types.ReceiptStatusSuccessful,
+	}, nil
+}
+
+func newTestTx(t *testing.T) *types.Transaction {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	to := common.HexToAddress("0x0000000000000000000000000000000000000001")
+	tx, err := types.SignNewTx(
+		key,
+		types.LatestSignerForChainID(big.NewInt(1)),
+		&types.DynamicFeeTx{
+			ChainID:   big.NewInt(1),
+			Nonce:     7,
+			GasTipCap: big.NewInt(1),
+			GasFeeCap: big.NewInt(2),
+			Gas:       21000,
+			To:        &to,
+			Value:     big.NewInt(0),
+		},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return tx
+}
+
+func newTestRelay(t *testing.T) (*Relay, *LocalRelay, common.Address) {
+	local := NewLocalRelay()
+	srv := httptest.NewServer(local)
+	t.Cleanup(srv.Close)
+
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := NewRelay(srv.URL, key, PollInterval(time.Millisecond))
+
+	return r, local, crypto.PubkeyToAddress(key.PublicKey)
+}
+
+func TestSignVerify(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	body := []byte(`{"jsonrpc":"2.0","id":1,"method":"eth_sendBundle"}`)
+
+	header, err := Sign(body, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	signer, err := Verify(body, header)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if signer != crypto.PubkeyToAddress(key.PublicKey) {
+		t.Errorf("recovered %s", signer)
+	}
+
+	_, err = Verify(append(body, ' '), header)
+	if err == nil {
+		t.Errorf("signature of other body accepted")
+	}
+}
+
+func TestSendBundle(t *testing.T) {
+	r, local, signer := newTestRelay(t)
+	tx := newTestTx(t)
+
+	hash, err := r.SendBundle(context.Background(), []*types.Transaction{tx}, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hash == "" {
+		t.Errorf("empty bundle hash")
+	}
+
+	bundles := local.Bundles()
+	if len(bundles) != 1 {
+		t.Fatalf("relay received %v bundles", len(bundles))
+	}
+	if bundles[0].Signer != signer || bundles[0].Block != 100 {
+		t.Errorf("bundle %+v", bundles[0])
+	}
+	if len(bundles[0].Txs) != 1 || bundles[0].Txs[0].Hash() != tx.Hash() {
+		t.Errorf("relay received other txs")
+	}
+}
+
+func TestSubmitWindow(t *testing.T) {
+	r, local, _ := newTestRelay(t)
+	tx := newTestTx(t)
+
+	local.Reject(11, "simulation failed")
+	chain := &stubChain{
+		head:  9,
+		mined: map[common.Hash]uint64{tx.Hash(): 12},
+	}
+
+	res, err := r.Submit(context.Background(), chain, []*types.Transaction{tx}, 10, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !res.Included || len(res.Blocks) != 3 {
+		t.Fatalf("result %+v", res)
+	}
+
+	for _, br := range res.Blocks {
+		switch br.Block {
+		case 10:
+			if br.Included || br.Error != "" || br.BundleHash == "" {
+				t.Errorf("block 10 result %+v", br)
+			}
+		case 11:
+			if br.Included || !strings.Contains(br.Error, "simulation failed") {
+				t.Errorf("block 11 result %+v", br)
+			}
+		case 12:
+			if !br.Included {
+				t.Errorf("block 12 result %+v", br)
+			}
+		}
+	}
+
+	// rejected block is not recorded by relay
+	if len(local.Bundles()) != 2 {
+		t.Errorf("relay received %v bundles, expected 2", len(local.Bundles()))
+	}
+}
+
+func TestSubmitNotIncluded(t *testing.T) {
+	r, local, _ := newTestRelay(t)
+	tx := newTestTx(t)
+
+	chain := &stubChain{head: 20, mined: map[common.Hash]uint64{}}
+
+	res, err := r.Submit(context.Background(), chain, []*types.Transaction{tx}, 21, 2)
+	if !errors.Is(err, ErrNotIncluded) {
+		t.Fatalf("expected not included error, got %v", err)
+	}
+	if res.Included || len(res.Blocks) != 2 || len(local.Bundles()) != 2 {
+		t.Errorf("result %+v", res)
+	}
+}
+
+func TestRelayRejectsUnsigned(t *testing.T) {
+	_, err := Verify([]byte("{}"), "0x00:0x00")
+	if err == nil {
+		t.Errorf("malformed signature accepted")
+	}
+
+	srv := httptest.NewServer(NewLocalRelay())
+	defer srv.Close()
+
+	resp, err := srv.Client().Post(srv.URL, "application/json", strings.NewReader("{}"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != 403 {
+		t.Errorf("unsigned request status %v, expected 403", resp.StatusCode)
+	}
+}
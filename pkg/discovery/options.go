@@ -0,0 +1,13 @@
+package discovery
+
+// Option -.
+type Option func(*Scanner)
+
+// Chunk sets number of blocks requested at once
+func Chunk(blocks uint64) Option {
+	return func(s *Scanner) {
+		if blocks > 0 {
+			s.chunk = blocks
+		}
+	}
+}
@@ -0,0 +1,166 @@
+package discovery
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// DefaultChunk is number of blocks requested in one eth_getLogs
+const DefaultChunk = 5000
+
+var (
+	// PairCreated(address indexed token0, address indexed token1, address pair, uint)
+	PairCreatedTopic = crypto.Keccak256Hash(
+		[]byte("PairCreated(address,address,address,uint256)"),
+	)
+
+	// PoolCreated(address indexed token0, address indexed token1,
+	// uint24 indexed fee, int24 tickSpacing, address pool)
+	PoolCreatedTopic = crypto.Keccak256Hash(
+		[]byte("PoolCreated(address,address,uint24,int24,address)"),
+	)
+)
+
+// Chain is a node api used to read factory logs
+type Chain interface {
+	BlockNumber(context.Context) (uint64, error)
+	FilterLogs(context.Context, ethereum.FilterQuery) ([]types.Log, error)
+}
+
+// Created is a pool deployed by factory
+type Created struct {
+	Factory string
+	Pool    string
+	Token0  string
+	Token1  string
+	FeeTier uint32 // uniswap-v3 pools only
+	Block   uint64
+}
+
+// ChunkFunc receives pools created up to last block of scanned chunk
+type ChunkFunc func(last uint64, pools []Created) error
+
+// Scanner reads pool creation events of factories block range by chunks
+type Scanner struct {
+	chain Chain
+	chunk uint64
+}
+
+func NewScanner(chain Chain, opts ...Option) *Scanner {
+	s := &Scanner{
+		chain: chain,
+		chunk: DefaultChunk,
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s
+}
+
+// Head returns latest block number
+func (s *Scanner) Head(ctx context.Context) (uint64, error) {
+	return s.chain.BlockNumber(ctx)
+}
+
+// Scan reads PairCreated & PoolCreated logs of factory from..to,
+// fn is called after every chunk so caller can store pools and
+// checkpoint the last block. Chunk is halved when node refuses range
+func (s *Scanner) Scan(
+	ctx context.Context,
+	factory string,
+	from, to uint64,
+	fn ChunkFunc,
+) (
+	err error,
+) {
+	chunk := s.chunk
+	addr := common.HexToAddress(factory)
+
+	for start := from; start <= to; {
+		end := start + chunk - 1
+		if end > to {
+			end = to
+		}
+
+		logs, _err := s.chain.FilterLogs(ctx, ethereum.FilterQuery{
+			FromBlock: new(big.Int).SetUint64(start),
+			ToBlock:   new(big.Int).SetUint64(end),
+			Addresses: []common.Address{addr},
+			Topics: [][]common.Hash{
+				{PairCreatedTopic, PoolCreatedTopic},
+			},
+		})
+		if _err != nil {
+			if ctx.Err() != nil || chunk == 1 {
+				err = fmt.Errorf("logs %v-%v: %w", start, end, _err)
+
+				return
+			}
+			chunk /= 2
+
+			continue
+		}
+
+		var pools []Created
+
+		for _, l := range logs {
+			pool, ok := Decode(l)
+			if ok {
+				pools = append(pools, pool)
+			}
+		}
+
+		err = fn(end, pools)
+		if err != nil {
+			return
+		}
+
+		start = end + 1
+	}
+
+	return
+}
+
+// Decode parses pool creation log of uniswap-v2 or v3 factory
+func Decode(l types.Log) (
+	pool Created,
+	ok bool,
+) {
+	if len(l.Topics) < 3 || l.Removed {
+		return
+	}
+
+	pool = Created{
+		Factory: l.Address.Hex(),
+		Token0:  common.BytesToAddress(l.Topics[1].Bytes()).Hex(),
+		Token1:  common.BytesToAddress(l.Topics[2].Bytes()).Hex(),
+		Block:   l.BlockNumber,
+	}
+
+	switch l.Topics[0] {
+	case PairCreatedTopic:
+		if len(l.Data) < 32 {
+			return
+		}
+		pool.Pool = common.BytesToAddress(l.Data[:32]).Hex()
+	case PoolCreatedTopic:
+		if len(l.Topics) < 4 || len(l.Data) < 64 {
+			return
+		}
+		pool.FeeTier = uint32(new(big.Int).SetBytes(l.Topics[3].Bytes()).Uint64())
+		pool.Pool = common.BytesToAddress(l.Data[32:64]).Hex()
+	default:
+		return
+	}
+	ok = true
+
+	return
+}
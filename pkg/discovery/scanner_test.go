@@ -0,0 +1,181 @@
+package discovery
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+var (
+	testFactory = common.HexToAddress("0x0000000000000000000000000000000000000f01")
+	testToken0  = common.HexToAddress("0x0000000000000000000000000000000000000b01")
+	testToken1  = common.HexToAddress("0x0000000000000000000000000000000000000b02")
+)
+
+// stubChain serves logs & refuses ranges wider than maxRange
+type stubChain struct {
+	head     uint64
+	logs     []types.Log
+	maxRange uint64
+	queries  int
+}
+
+func (s *stubChain) BlockNumber(context.Context) (uint64, error) {
+	return s.head, nil
+}
+
+func (s *stubChain) FilterLogs(
+	_ context.Context,
+	q ethereum.FilterQuery,
+) (
+	out []types.Log,
+	err error,
+) {
+	s.queries++
+
+	from, to := q.FromBlock.Uint64(), q.ToBlock.Uint64()
+	if s.maxRange > 0 && to-from+1 > s.maxRange {
+		err = fmt.Errorf("query returned more than 10000 results")
+
+		return
+	}
+
+	for _, l := range s.logs {
+		if l.BlockNumber >= from && l.BlockNumber <= to && l.Address == q.Addresses[0] {
+			out = append(out, l)
+		}
+	}
+
+	return
+}
+
+func pairCreated(block uint64, pool common.Address) types.Log {
+	return types.Log{
+		Address:     testFactory,
+		BlockNumber: block,
+		Topics: []common.Hash{
+			PairCreatedTopic,
+			common.BytesToHash(testToken0.Bytes()),
+			common.BytesToHash(testToken1.Bytes()),
+		},
+		Data: append(
+			common.LeftPadBytes(pool.Bytes(), 32),
+			common.LeftPadBytes(big.NewInt(1).Bytes(), 32)...,
+		),
+	}
+}
+
+func poolCreated(block uint64, pool common.Address, fee int64) types.Log {
+	return types.Log{
+		Address:     testFactory,
+		BlockNumber: block,
+		Topics: []common.Hash{
+			PoolCreatedTopic,
+			common.BytesToHash(testToken0.Bytes()),
+			common.BytesToHash(testToken1.Bytes()),
+			common.BigToHash(big.NewInt(fee)),
+		},
+		Data: append(
+			common.LeftPadBytes(big.NewInt(60).Bytes(), 32),
+			common.LeftPadBytes(pool.Bytes(), 32)...,
+		),
+	}
+}
+
+func TestDecode(t *testing.T) {
+	pool := common.HexToAddress("0x0000000000000000000000000000000000000a01")
+
+	v2, ok := Decode(pairCreated(10, pool))
+	if !ok || v2.Pool != pool.Hex() || v2.Token0 != testToken0.Hex() ||
+		v2.Token1 != testToken1.Hex() || v2.FeeTier != 0 {
+		t.Errorf("pair created decoded as %+v", v2)
+	}
+
+	v3, ok := Decode(poolCreated(11, pool, 500))
+	if !ok || v3.Pool != pool.Hex() || v3.FeeTier != 500 || v3.Block != 11 {
+		t.Errorf("pool created decoded as %+v", v3)
+	}
+
+	if _, ok = Decode(types.Log{Topics: []common.Hash{{}, {}, {}}}); ok {
+		t.Error("unknown event decoded")
+	}
+}
+
+func TestScanChunksAndResume(t *testing.T) {
+	chain := &stubChain{
+		head:     100,
+		maxRange: 25,
+		logs: []types.Log{
+			pairCreated(5, common.HexToAddress("0xa1")),
+			pairCreated(40, common.HexToAddress("0xa2")),
+			poolCreated(90, common.HexToAddress("0xa3"), 3000),
+		},
+	}
+	s := NewScanner(chain, Chunk(100))
+
+	var (
+		found      []Created
+		checkpoint uint64
+	)
+
+	fail := fmt.Errorf("stop")
+	err := s.Scan(context.Background(), testFactory.Hex(), 0, 100,
+		func(last uint64, pools []Created) error {
+			found = append(found, pools...)
+			checkpoint = last
+
+			// interrupted after the second pool
+			if len(found) == 2 {
+				return fail
+			}
+
+			return nil
+		},
+	)
+	if err != fail {
+		t.Fatalf("expected interruption, got %v", err)
+	}
+	if checkpoint < 40 || checkpoint >= 90 {
+		t.Fatalf("checkpoint %v", checkpoint)
+	}
+
+	err = s.Scan(context.Background(), testFactory.Hex(), checkpoint+1, 100,
+		func(last uint64, pools []Created) error {
+			found = append(found, pools...)
+			checkpoint = last
+
+			return nil
+		},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if checkpoint != 100 {
+		t.Errorf("last checkpoint %v", checkpoint)
+	}
+	if len(found) != 3 || found[2].FeeTier != 3000 {
+		t.Errorf("found %+v", found)
+	}
+}
+
+func TestScanRangeRefused(t *testing.T) {
+	chain := &stubChain{head: 10, maxRange: 1}
+	s := NewScanner(chain, Chunk(8))
+
+	err := s.Scan(context.Background(), testFactory.Hex(), 0, 10,
+		func(uint64, []Created) error { return nil },
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 8 -> 4 -> 2 -> 1 then single block queries
+	if chain.queries != 3+11 {
+		t.Errorf("%v queries", chain.queries)
+	}
+}
@@ -0,0 +1,341 @@
+package erc20
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// DefaultBatchSize is max number of calls in one json-rpc batch
+const DefaultBatchSize = 300
+
+var (
+	ErrNotContract = errors.New("no contract at address")
+	ErrNotERC20    = errors.New("not an erc-20 token")
+)
+
+var (
+	symbolSelector   = selector("symbol()")
+	nameSelector     = selector("name()")
+	decimalsSelector = selector("decimals()")
+)
+
+// Caller is json-rpc client able to send batch requests
+type Caller interface {
+	BatchCallContext(
+		ctx context.Context, b []rpc.BatchElem,
+	) error
+}
+
+// Metadata of erc-20 token
+type Metadata struct {
+	Address  string `json:"address"`
+	Symbol   string `json:"symbol"`
+	Name     string `json:"name"`
+	Decimals uint8  `json:"decimals"`
+}
+
+// Fetcher reads token metadata from chain, fetched tokens are
+// cached as metadata of deployed token never changes
+type Fetcher struct {
+	rpc       Caller
+	batchSize int
+
+	mu    sync.RWMutex
+	cache map[common.Address]Metadata
+}
+
+func NewFetcher(cl Caller, batchSize int) *Fetcher {
+	if batchSize <= 0 {
+		batchSize = DefaultBatchSize
+	}
+
+	return &Fetcher{
+		rpc:       cl,
+		batchSize: batchSize,
+		cache:     make(map[common.Address]Metadata),
+	}
+}
+
+// Cache adds known metadata, e.g. of persisted tokens
+func (f *Fetcher) Cache(md ...Metadata) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for _, m := range md {
+		f.cache[common.HexToAddress(m.Address)] = m
+	}
+}
+
+func (f *Fetcher) cached(addr common.Address) (
+	md Metadata,
+	ok bool,
+) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	md, ok = f.cache[addr]
+
+	return
+}
+
+// Fetch returns metadata of tokens in order of addresses. Addresses
+// without code fail with ErrNotContract, ones reverting or returning
+// malformed symbol or decimals fail with ErrNotERC20. Missing name
+// falls back to symbol
+func (f *Fetcher) Fetch(
+	ctx context.Context,
+	addresses ...string,
+) (
+	out []Metadata,
+	err error,
+) {
+	out, errs, err := f.FetchEach(ctx, addresses...)
+	if err != nil {
+		return
+	}
+
+	for _, _err := range errs {
+		if _err != nil {
+			err = _err
+
+			return
+		}
+	}
+
+	return
+}
+
+// FetchEach is Fetch in one batch where a rejected token fails alone,
+// errs holds error of token at the same index. Err is returned for
+// invalid addresses & failed batches
+func (f *Fetcher) FetchEach(
+	ctx context.Context,
+	addresses ...string,
+) (
+	out []Metadata,
+	errs []error,
+	err error,
+) {
+	out = make([]Metadata, len(addresses))
+	errs = make([]error, len(addresses))
+
+	var (
+		missing []int
+		calls   []rpc.BatchElem
+	)
+	const n = 4
+
+	results := make([]hexutil.Bytes, len(addresses)*n)
+	for i, address := range addresses {
+		if !common.IsHexAddress(address) {
+			err = fmt.Errorf("invalid token address %q", address)
+
+			return
+		}
+		addr := common.HexToAddress(address)
+
+		if md, ok := f.cached(addr); ok {
+			out[i] = md
+
+			continue
+		}
+		missing = append(missing, i)
+
+		calls = append(calls, rpc.BatchElem{
+			Method: "eth_getCode",
+			Args:   []interface{}{addr, "latest"},
+			Result: &results[i*n],
+		})
+		for j, sel := range [][]byte{symbolSelector, nameSelector, decimalsSelector} {
+			calls = append(calls, rpc.BatchElem{
+				Method: "eth_call",
+				Args:   []interface{}{callArg(addr, sel), "latest"},
+				Result: &results[i*n+j+1],
+			})
+		}
+	}
+
+	err = f.batch(ctx, calls)
+	if err != nil {
+		return
+	}
+
+	for k, i := range missing {
+		addr := common.HexToAddress(addresses[i])
+
+		md, _err := decode(
+			addr,
+			calls[k*n:k*n+n],
+			results[i*n:i*n+n],
+		)
+		if _err != nil {
+			errs[i] = fmt.Errorf("token %s: %w", addr, _err)
+
+			continue
+		}
+		f.Cache(md)
+		out[i] = md
+	}
+
+	return
+}
+
+func decode(
+	addr common.Address,
+	calls []rpc.BatchElem,
+	results []hexutil.Bytes,
+) (
+	md Metadata,
+	err error,
+) {
+	if calls[0].Error != nil {
+		err = calls[0].Error
+
+		return
+	}
+	if len(results[0]) == 0 {
+		err = ErrNotContract
+
+		return
+	}
+
+	md.Address = addr.Hex()
+
+	if calls[1].Error != nil {
+		err = fmt.Errorf("%w: symbol: %s", ErrNotERC20, calls[1].Error)
+
+		return
+	}
+	md.Symbol, err = DecodeString(results[1])
+	if err != nil {
+		err = fmt.Errorf("%w: symbol: %s", ErrNotERC20, err)
+
+		return
+	}
+
+	if calls[3].Error != nil {
+		err = fmt.Errorf("%w: decimals: %s", ErrNotERC20, calls[3].Error)
+
+		return
+	}
+	md.Decimals, err = decodeDecimals(results[3])
+	if err != nil {
+		err = fmt.Errorf("%w: decimals: %s", ErrNotERC20, err)
+
+		return
+	}
+
+	md.Name = md.Symbol
+	if calls[2].Error == nil {
+		if name, _err := DecodeString(results[2]); _err == nil && name != "" {
+			md.Name = name
+		}
+	}
+
+	return
+}
+
+// DecodeString decodes abi string output, or bytes32 one of
+// tokens like MKR which predate string symbols
+func DecodeString(b []byte) (
+	s string,
+	err error,
+) {
+	switch {
+	case len(b) == 32:
+		s = string(bytes.TrimRight(b, "\x00"))
+	case len(b) >= 64:
+		offset := new(big.Int).SetBytes(b[:32])
+		if !offset.IsUint64() || offset.Uint64() > uint64(len(b)-32) {
+			err = fmt.Errorf("invalid string offset %s", offset)
+
+			return
+		}
+		start := offset.Uint64() + 32
+
+		size := new(big.Int).SetBytes(b[start-32 : start])
+		if !size.IsUint64() || size.Uint64() > uint64(len(b))-start {
+			err = fmt.Errorf("invalid string length %s", size)
+
+			return
+		}
+		s = string(b[start : start+size.Uint64()])
+	default:
+		err = fmt.Errorf("invalid string output %x", b)
+
+		return
+	}
+
+	if !utf8.ValidString(s) {
+		s = strings.ToValidUTF8(s, "")
+	}
+	s = strings.TrimSpace(s)
+
+	return
+}
+
+func decodeDecimals(b []byte) (
+	decimals uint8,
+	err error,
+) {
+	if len(b) != 32 {
+		err = fmt.Errorf("invalid output %x", b)
+
+		return
+	}
+
+	v := new(big.Int).SetBytes(b)
+	if !v.IsUint64() || v.Uint64() > 255 {
+		err = fmt.Errorf("decimals %s out of range", v)
+
+		return
+	}
+	decimals = uint8(v.Uint64())
+
+	return
+}
+
+func (f *Fetcher) batch(
+	ctx context.Context,
+	calls []rpc.BatchElem,
+) (
+	err error,
+) {
+	for start := 0; start < len(calls); start += f.batchSize {
+		end := start + f.batchSize
+		if end > len(calls) {
+			end = len(calls)
+		}
+
+		err = f.rpc.BatchCallContext(ctx, calls[start:end])
+		if err != nil {
+			err = fmt.Errorf("batch %v-%v: %w", start, end, err)
+
+			return
+		}
+	}
+
+	return
+}
+
+func callArg(to common.Address, data []byte) map[string]interface{} {
+	return map[string]interface{}{
+		"to":   to,
+		"data": hexutil.Bytes(data),
+	}
+}
+
+func selector(signature string) []byte {
+	return crypto.Keccak256([]byte(signature))[:4]
+}
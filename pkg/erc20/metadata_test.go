@@ -0,0 +1,196 @@
+package erc20
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+
+	"github.com/antonyuhnovets/flash-loan-arbitrage/internal/rpcstub"
+)
+
+// stubNode answers eth_getCode & eth_call of token methods,
+// a method without output reverts
+type stubNode struct {
+	*rpcstub.Node
+	tokens map[common.Address]map[string][]byte
+}
+
+func (s *stubNode) handle(req rpcstub.Request) (
+	res interface{},
+	err *rpcstub.Error,
+) {
+	switch req.Method {
+	case "eth_getCode":
+		var addr common.Address
+		req.Param(0, &addr)
+
+		res = "0x"
+		if _, ok := s.tokens[addr]; ok {
+			res = "0x6080"
+		}
+	case "eth_call":
+		var call struct {
+			To   common.Address `json:"to"`
+			Data hexutil.Bytes  `json:"data"`
+		}
+		req.Param(0, &call)
+
+		for sel, output := range s.tokens[call.To] {
+			if bytes.Equal(call.Data, []byte(sel)) {
+				res = hexutil.Bytes(output)
+
+				return
+			}
+		}
+		err = rpcstub.ErrReverted
+	default:
+		err = rpcstub.ErrNotFound
+	}
+
+	return
+}
+
+func abiString(s string) []byte {
+	out := common.LeftPadBytes(big.NewInt(32).Bytes(), 32)
+	out = append(out, common.LeftPadBytes(big.NewInt(int64(len(s))).Bytes(), 32)...)
+
+	return append(out, common.RightPadBytes([]byte(s), (len(s)+31)/32*32)...)
+}
+
+func uint256(v int64) []byte {
+	return common.LeftPadBytes(big.NewInt(v).Bytes(), 32)
+}
+
+var (
+	testUSDC = common.HexToAddress("0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48")
+	testMKR  = common.HexToAddress("0x9f8F72aA9304c8B593d555F12eF6589cC3A579A2")
+	testEOA  = common.HexToAddress("0x0000000000000000000000000000000000000e0a")
+	testNFT  = common.HexToAddress("0x0000000000000000000000000000000000000721")
+)
+
+func newTestFetcher(t *testing.T) (*Fetcher, *stubNode) {
+	node := &stubNode{
+		tokens: map[common.Address]map[string][]byte{
+			testUSDC: {
+				string(symbolSelector):   abiString("USDC"),
+				string(nameSelector):     abiString("USD Coin"),
+				string(decimalsSelector): uint256(6),
+			},
+			testMKR: {
+				string(symbolSelector):   common.RightPadBytes([]byte("MKR"), 32),
+				string(nameSelector):     common.RightPadBytes([]byte("Maker"), 32),
+				string(decimalsSelector): uint256(18),
+			},
+			// no decimals
+			testNFT: {
+				string(symbolSelector): abiString("NFT"),
+			},
+		},
+	}
+
+	var cl *rpc.Client
+	node.Node, cl = rpcstub.New(t, node.handle)
+
+	return NewFetcher(cl, 3), node
+}
+
+func TestFetch(t *testing.T) {
+	f, node := newTestFetcher(t)
+	ctx := context.Background()
+
+	md, err := f.Fetch(ctx, testUSDC.Hex(), testMKR.Hex())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []Metadata{
+		{Address: testUSDC.Hex(), Symbol: "USDC", Name: "USD Coin", Decimals: 6},
+		{Address: testMKR.Hex(), Symbol: "MKR", Name: "Maker", Decimals: 18},
+	}
+	for i := range expected {
+		if md[i] != expected[i] {
+			t.Errorf("metadata %+v, expected %+v", md[i], expected[i])
+		}
+	}
+
+	calls := node.Calls()
+	if _, err = f.Fetch(ctx, testMKR.Hex()); err != nil {
+		t.Fatal(err)
+	}
+	if node.Calls() != calls {
+		t.Errorf("cached token fetched again with %v calls", node.Calls()-calls)
+	}
+}
+
+func TestFetchRejects(t *testing.T) {
+	f, _ := newTestFetcher(t)
+	ctx := context.Background()
+
+	for addr, expected := range map[common.Address]error{
+		testEOA: ErrNotContract,
+		testNFT: ErrNotERC20,
+	} {
+		_, err := f.Fetch(ctx, testUSDC.Hex(), addr.Hex())
+		if !errors.Is(err, expected) {
+			t.Errorf("token %s: %v, expected %v", addr, err, expected)
+		}
+	}
+
+	if _, err := f.Fetch(ctx, "0x01"); err == nil {
+		t.Error("invalid address accepted")
+	}
+}
+
+func TestDecodeString(t *testing.T) {
+	for _, tc := range []struct {
+		in  []byte
+		out string
+		ok  bool
+	}{
+		{abiString("Wrapped Ether"), "Wrapped Ether", true},
+		{abiString(""), "", true},
+		{common.RightPadBytes([]byte("MKR"), 32), "MKR", true},
+		{[]byte("short"), "", false},
+		// length beyond output
+		{append(uint256(32), uint256(100)...), "", false},
+		// offset beyond output
+		{append(uint256(1000), uint256(1)...), "", false},
+	} {
+		out, err := DecodeString(tc.in)
+		if (err == nil) != tc.ok || out != tc.out {
+			t.Errorf("DecodeString(%x) = %q, %v", tc.in, out, err)
+		}
+	}
+}
+
+func TestFetchEach(t *testing.T) {
+	f, node := newTestFetcher(t)
+
+	md, errs, err := f.FetchEach(
+		context.Background(),
+		testUSDC.Hex(), testEOA.Hex(), testNFT.Hex(), testMKR.Hex(),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// code, symbol, name & decimals of every token
+	if node.Calls() != 16 {
+		t.Errorf("%v calls, expected 16", node.Calls())
+	}
+
+	for i, expected := range []error{nil, ErrNotContract, ErrNotERC20, nil} {
+		if !errors.Is(errs[i], expected) {
+			t.Errorf("token %v: %v, expected %v", i, errs[i], expected)
+		}
+	}
+	if md[0].Symbol != "USDC" || md[3].Symbol != "MKR" {
+		t.Errorf("metadata %+v", md)
+	}
+}
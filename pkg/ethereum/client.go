@@ -4,19 +4,32 @@ import (
 	"context"
 	"fmt"
 	"math/big"
+	"sync"
 
+	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
 )
 
+// Client sends transactions concurrently, wallet & chain id are set
+// by Setup and only read afterwards under the lock
 type Client struct {
 	Client  *ethclient.Client
 	Wallet  *Wallet
 	ChainID *big.Int
+
+	mu        sync.RWMutex
+	fees      FeeStrategy
+	gasMargin uint64
+	nonces    *NonceManager
+	selection Selection
+	wallets   *WalletPool
 }
 
-func NewClient(url string) (
+func NewClient(url string, opts ...Option) (
 	cl *Client,
 	err error,
 ) {
@@ -24,16 +37,54 @@ func NewClient(url string) (
 	if err != nil {
 		return
 	}
-	cl = &Client{Client: client}
+	cl = &Client{
+		Client: client,
+		fees:   LegacyFees{},
+	}
+
+	for _, opt := range opts {
+		opt(cl)
+	}
+	cl.wallets = NewWalletPool(client, cl.selection)
+
+	return
+}
+
+// Backend returns contract backend which adds gas margin to estimations
+func (c *Client) Backend() bind.ContractBackend {
+	return &marginBackend{c.Client, c.gasMargin}
+}
+
+type marginBackend struct {
+	*ethclient.Client
+	margin uint64
+}
+
+func (mb *marginBackend) EstimateGas(
+	ctx context.Context,
+	msg ethereum.CallMsg,
+) (
+	gas uint64,
+	err error,
+) {
+	gas, err = mb.Client.EstimateGas(ctx, msg)
+	if err != nil {
+		return
+	}
+	gas += gas * mb.margin / 100
 
 	return
 }
 
+// Setup reads chain id once & sets wallet of owner only calls
 func (c *Client) Setup(ctx context.Context, wall *Wallet) (
 	err error,
 ) {
+	err = c.UpdateChainID(ctx)
+	if err != nil {
+		return
+	}
 	c.UseWallet(wall)
-	c.setChainID(c.GetChainID(ctx))
 
 	return
 }
@@ -46,22 +97,60 @@ func (c *Client) ClientGet() (
 	return
 }
 
+// RPC returns underlying json-rpc client for batch requests
+func (c *Client) RPC() *rpc.Client {
+	return c.Client.Client()
+}
+
+// UseWallet sets wallet of owner only calls & adds it to pool,
+// wallet which is already pooled keeps its nonce manager
 func (c *Client) UseWallet(wall *Wallet) {
+	_ = c.wallets.Add(wall)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
 	c.Wallet = wall
+	c.nonces = c.wallets.Nonces(wall.Address)
+}
+
+// primary returns wallet of owner only calls & its nonce manager
+func (c *Client) primary() (*Wallet, *NonceManager) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.Wallet, c.nonces
+}
+
+func (c *Client) chainID() *big.Int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.ChainID
+}
+
+// Nonces returns nonce manager of current wallet
+func (c *Client) Nonces() *NonceManager {
+	_, nonces := c.primary()
+
+	return nonces
+}
+
+// Wallets returns pool of wallets pooled transactions are sent from
+func (c *Client) Wallets() *WalletPool {
+	return c.wallets
 }
 
+// GetBallance returns wei balance of current wallet at latest block
 func (c *Client) GetBallance(ctx context.Context) (
-	ball int,
+	ball *big.Int,
 	err error,
 ) {
-	b, err := c.Client.BalanceAt(ctx, c.Wallet.Address, c.ChainID)
-	ball = int(b.Int64())
+	wall, _ := c.primary()
 
-	return
-}
+	ball, err = c.Client.BalanceAt(ctx, wall.Address, nil)
 
-func (c *Client) setChainID(chainId *big.Int) {
-	c.ChainID = chainId
+	return
 }
 
 func (c *Client) GetChainID(ctx context.Context) *big.Int {
@@ -73,87 +162,249 @@ func (c *Client) GetChainID(ctx context.Context) *big.Int {
 	return chainId
 }
 
-// GetNextTransaction returns the next transaction in the pending transaction queue
+// GasPrice returns expected price per gas unit: base fee + tip,
+// or legacy gas price for chains without base fee
+func (c *Client) GasPrice(ctx context.Context) (
+	price *big.Int,
+	err error,
+) {
+	head, err := c.Client.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return
+	}
+	if head.BaseFee == nil {
+		price, err = c.Client.SuggestGasPrice(ctx)
+
+		return
+	}
+
+	tip, err := c.Client.SuggestGasTipCap(ctx)
+	if err != nil {
+		return
+	}
+	price = new(big.Int).Add(head.BaseFee, tip)
+
+	return
+}
+
+// EstimateGas estimates gas of call from wallet address
+func (c *Client) EstimateGas(
+	ctx context.Context,
+	to common.Address,
+	data []byte,
+) (
+	gas uint64,
+	err error,
+) {
+	wall, _ := c.primary()
+
+	gas, err = c.Client.EstimateGas(ctx, ethereum.CallMsg{
+		From: wall.Address,
+		To:   &to,
+		Data: data,
+	})
+
+	return
+}
+
+// GetNextTransaction returns the next transaction in the pending transaction queue,
+// gas limit is left empty to be estimated by contract backend.
+// Nonce is reserved, report the result with Transact or nonce manager
 func (c *Client) GetNextTransaction(ctx context.Context) (
 	opts *bind.TransactOpts,
 	err error,
 ) {
-	gasPrice, err := c.Client.SuggestGasPrice(ctx)
+	wall, nonces := c.primary()
+
+	nonce, err := nonces.Next(ctx)
 	if err != nil {
-		return nil, err
+		return
 	}
 
-	// nonce
-	nonce, err := c.Client.PendingNonceAt(ctx, c.Wallet.Address)
+	opts, err = c.transactOpts(ctx, wall, nonce)
 	if err != nil {
+		nonces.Release(nonce)
+	}
+
+	return
+}
+
+// TransactOpts returns signed options of transaction with given nonce
+func (c *Client) TransactOpts(ctx context.Context, nonce uint64) (
+	opts *bind.TransactOpts,
+	err error,
+) {
+	wall, _ := c.primary()
+
+	opts, err = c.transactOpts(ctx, wall, nonce)
+
+	return
+}
+
+func (c *Client) transactOpts(
+	ctx context.Context,
+	wall *Wallet,
+	nonce uint64,
+) (
+	opts *bind.TransactOpts,
+	err error,
+) {
+	chainID := c.chainID()
+	if chainID == nil {
+		err = bind.ErrNoChainID
+
 		return
 	}
 
-	c.UpdateChainID(ctx)
+	// transactions are signed by wallet signer, key may be remote
+	auth := &bind.TransactOpts{
+		From: wall.Address,
+		Signer: func(addr common.Address, tx *types.Transaction) (
+			*types.Transaction, error,
+		) {
+			if addr != wall.Address {
+				return nil, bind.ErrNotAuthorized
+			}
+
+			return wall.signer.SignTx(ctx, tx, chainID)
+		},
+		Context: ctx,
+	}
+	auth.Nonce = new(big.Int).SetUint64(nonce)
+	auth.Value = big.NewInt(0) // in wei
 
-	// sign the transaction
-	auth, err := bind.NewKeyedTransactorWithChainID(
-		c.Wallet.privateKey,
-		c.ChainID,
-	)
+	err = applyFees(ctx, c.fees, c.Client, auth)
 	if err != nil {
 		return
 	}
-	auth.Nonce = big.NewInt(int64(nonce))
-	auth.Value = big.NewInt(0)     // in wei
-	auth.GasLimit = uint64(300000) // in units
-	auth.GasPrice = gasPrice       // in wei
 
 	return auth, nil
 }
 
-func (c *Client) UpdateChainID(ctx context.Context) (
+// Transact sends transaction built by fn from current wallet with next
+// nonce, nonce is released if fn fails & resynced if node rejects it
+func (c *Client) Transact(
+	ctx context.Context,
+	fn func(*bind.TransactOpts) (*types.Transaction, error),
+) (
+	tx *types.Transaction,
 	err error,
 ) {
-	chainID := c.GetChainID(ctx)
-	if chainID == nil {
-		err = fmt.Errorf("fail getting chain id")
+	wall, nonces := c.primary()
 
+	nonce, err := nonces.Next(ctx)
+	if err != nil {
 		return
 	}
-	c.setChainID(chainID)
+
+	tx, err = c.transact(ctx, wall, nonces, nonce, fn)
 
 	return
 }
 
-// not used
-func (c *Client) SignTx(ctx context.Context, tx *types.Transaction) (
-	t *types.Transaction,
+// TransactPooled sends transaction built by fn from wallet picked by pool,
+// calls restricted to contract owner have to go through Transact
+func (c *Client) TransactPooled(
+	ctx context.Context,
+	fn func(*bind.TransactOpts) (*types.Transaction, error),
+) (
+	tx *types.Transaction,
 	err error,
 ) {
-	// s := types.NewEIP155Signer(tx.ChainId())
-	s := types.NewLondonSigner(c.ChainID)
-	t, err = types.SignTx(tx, s, c.Wallet.privateKey)
+	wall, nonces, nonce, err := c.wallets.Acquire(ctx)
+	if err != nil {
+		return
+	}
+
+	tx, err = c.transact(ctx, wall, nonces, nonce, fn)
 
 	return
 }
 
-func (c *Client) ReplaceTx(ctx context.Context, hash string) (
-	opts *bind.TransactOpts,
+func (c *Client) transact(
+	ctx context.Context,
+	wall *Wallet,
+	nonces *NonceManager,
+	nonce uint64,
+	fn func(*bind.TransactOpts) (*types.Transaction, error),
+) (
+	tx *types.Transaction,
 	err error,
 ) {
+	opts, err := c.transactOpts(ctx, wall, nonce)
+	if err != nil {
+		nonces.Release(nonce)
 
-	opts, err = c.GetNextTransaction(ctx)
+		return
+	}
+
+	tx, err = fn(opts)
 	if err != nil {
-		fmt.Println(err)
+		nonces.Release(nonce)
+		if IsNonceError(err) {
+			_ = nonces.Resync(ctx)
+		}
 
 		return
 	}
+	nonces.Sent(nonce, tx.Hash())
 
-	trans, _, err := c.Client.TransactionByHash(
-		ctx,
-		ToHash(hash))
+	return
+}
 
+// Sent records transaction signed outside of Transact
+// in nonce manager of its sender
+func (c *Client) Sent(tx *types.Transaction) {
+	from, err := Sender(tx)
 	if err != nil {
 		return
 	}
 
-	opts.Nonce = ToBigInt(int(trans.Nonce()))
+	nonces := c.wallets.Nonces(from)
+	if nonces == nil {
+		return
+	}
+	nonces.Sent(tx.Nonce(), tx.Hash())
+}
+
+// UpdateChainID reads chain id from node, it's done once by Setup
+func (c *Client) UpdateChainID(ctx context.Context) (
+	err error,
+) {
+	chainID := c.GetChainID(ctx)
+	if chainID == nil {
+		err = fmt.Errorf("fail getting chain id")
+
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.ChainID = chainID
+
+	return
+}
+
+// not used
+func (c *Client) SignTx(ctx context.Context, tx *types.Transaction) (
+	t *types.Transaction,
+	err error,
+) {
+	wall, _ := c.primary()
+
+	t, err = wall.signer.SignTx(ctx, tx, c.chainID())
+
+	return
+}
+
+// ReplaceTx returns options reusing nonce of pending tx with fees
+// bumped enough to replace it
+func (c *Client) ReplaceTx(ctx context.Context, hash string) (
+	opts *bind.TransactOpts,
+	err error,
+) {
+	opts, _, err = c.replaceOpts(ctx, ToHash(hash), MinBumpPercent)
 
 	return
 }
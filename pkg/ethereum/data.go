@@ -46,10 +46,6 @@ func FromAddress(a common.Address) string {
 	return a.Hex()
 }
 
-func FromBigInt(b *big.Int) int {
-	return int(b.Int64())
-}
-
 func ToBigInt(b int) *big.Int {
 	return big.NewInt(int64(b))
 }
@@ -0,0 +1,170 @@
+package ethereum
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sort"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+)
+
+const (
+	_defaultFeePercentile   = 50
+	_defaultFeeHistoryBlock = 10
+)
+
+var ErrNoLondon = errors.New("chain has no base fee, use legacy fees")
+
+// FeeBackend is a node api used to suggest fees
+type FeeBackend interface {
+	SuggestGasPrice(context.Context) (*big.Int, error)
+	SuggestGasTipCap(context.Context) (*big.Int, error)
+	FeeHistory(
+		context.Context, uint64, *big.Int, []float64,
+	) (*ethereum.FeeHistory, error)
+}
+
+// FeeStrategy sets fee fields of transaction options
+type FeeStrategy interface {
+	Apply(context.Context, FeeBackend, *bind.TransactOpts) error
+}
+
+// applyFees prices opts by strategy, transactions of chains without
+// base fee get legacy gas price
+func applyFees(
+	ctx context.Context,
+	fees FeeStrategy,
+	b FeeBackend,
+	opts *bind.TransactOpts,
+) (
+	err error,
+) {
+	err = fees.Apply(ctx, b, opts)
+	if errors.Is(err, ErrNoLondon) {
+		err = LegacyFees{}.Apply(ctx, b, opts)
+	}
+
+	return
+}
+
+// LegacyFees builds legacy transactions with suggested gas price
+type LegacyFees struct{}
+
+func (LegacyFees) Apply(
+	ctx context.Context,
+	b FeeBackend,
+	opts *bind.TransactOpts,
+) (
+	err error,
+) {
+	price, err := b.SuggestGasPrice(ctx)
+	if err != nil {
+		return
+	}
+	opts.GasPrice = price
+	opts.GasFeeCap, opts.GasTipCap = nil, nil
+
+	return
+}
+
+// DynamicFees builds type-2 transactions, tip is a percentile of
+// priority fees paid in last blocks
+type DynamicFees struct {
+	Percentile float64
+	Blocks     uint64
+}
+
+func NewDynamicFees(percentile float64, blocks uint64) *DynamicFees {
+	if percentile <= 0 || percentile > 100 {
+		percentile = _defaultFeePercentile
+	}
+	if blocks == 0 {
+		blocks = _defaultFeeHistoryBlock
+	}
+
+	return &DynamicFees{
+		Percentile: percentile,
+		Blocks:     blocks,
+	}
+}
+
+func (df *DynamicFees) Apply(
+	ctx context.Context,
+	b FeeBackend,
+	opts *bind.TransactOpts,
+) (
+	err error,
+) {
+	tip, feeCap, err := df.Fees(ctx, b)
+	if err != nil {
+		return
+	}
+	opts.GasPrice = nil
+	opts.GasTipCap = tip
+	opts.GasFeeCap = feeCap
+
+	return
+}
+
+// Fees returns tip & fee cap, cap covers doubled next block base fee
+func (df *DynamicFees) Fees(
+	ctx context.Context,
+	b FeeBackend,
+) (
+	tip, feeCap *big.Int,
+	err error,
+) {
+	hist, err := b.FeeHistory(
+		ctx, df.Blocks, nil, []float64{df.Percentile},
+	)
+	if err != nil {
+		return
+	}
+
+	// last base fee is the one of the next block
+	if len(hist.BaseFee) == 0 ||
+		hist.BaseFee[len(hist.BaseFee)-1] == nil ||
+		hist.BaseFee[len(hist.BaseFee)-1].Sign() == 0 {
+		err = ErrNoLondon
+
+		return
+	}
+	baseFee := hist.BaseFee[len(hist.BaseFee)-1]
+
+	tip = medianReward(hist.Reward)
+	if tip == nil || tip.Sign() == 0 {
+		tip, err = b.SuggestGasTipCap(ctx)
+		if err != nil {
+			return
+		}
+	}
+
+	feeCap = new(big.Int).Mul(baseFee, big.NewInt(2))
+	feeCap.Add(feeCap, tip)
+
+	return
+}
+
+func medianReward(rewards [][]*big.Int) (
+	median *big.Int,
+) {
+	values := make([]*big.Int, 0, len(rewards))
+
+	for _, r := range rewards {
+		if len(r) > 0 && r[0] != nil {
+			values = append(values, r[0])
+		}
+	}
+	if len(values) == 0 {
+		return
+	}
+
+	sort.Slice(values, func(i, j int) bool {
+		return values[i].Cmp(values[j]) < 0
+	})
+	median = new(big.Int).Set(values[len(values)/2])
+
+	return
+}
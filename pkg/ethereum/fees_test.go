@@ -0,0 +1,160 @@
+package ethereum
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/ethclient"
+
+	"github.com/antonyuhnovets/flash-loan-arbitrage/internal/rpcstub"
+)
+
+// newFeeNode serves results of json-rpc methods, unknown methods fail
+func newFeeNode(t *testing.T, results map[string]interface{}) *ethclient.Client {
+	_, cl := rpcstub.New(t, func(req rpcstub.Request) (
+		res interface{},
+		err *rpcstub.Error,
+	) {
+		res, ok := results[req.Method]
+		if !ok {
+			err = rpcstub.ErrNotFound
+		}
+
+		return
+	})
+
+	return ethclient.NewClient(cl)
+}
+
+func TestApplyFees(t *testing.T) {
+	const (
+		gasPrice = "0x4a817c800" // 20 gwei
+		tipCap   = "0x77359400"  // 2 gwei
+	)
+
+	tests := []struct {
+		name    string
+		fees    FeeStrategy
+		history interface{}
+		tip     *big.Int
+		feeCap  *big.Int
+		price   *big.Int
+	}{
+		{
+			name: "median reward",
+			fees: NewDynamicFees(50, 3),
+			history: map[string]interface{}{
+				"oldestBlock":   "0x1",
+				"baseFeePerGas": []string{"0x3b9aca00", "0x3b9aca00", "0x3b9aca00", "0x12a05f200"}, // next 5 gwei
+				"gasUsedRatio":  []float64{0.5, 0.5, 0.5},
+				"reward":        [][]string{{"0x3"}, {"0x1"}, {"0x2"}},
+			},
+			tip:    big.NewInt(2),
+			feeCap: big.NewInt(10_000_000_002), // doubled base fee & tip
+		},
+		{
+			name: "empty rewards",
+			fees: NewDynamicFees(50, 2),
+			history: map[string]interface{}{
+				"oldestBlock":   "0x1",
+				"baseFeePerGas": []string{"0x3b9aca00", "0x3b9aca00", "0x3b9aca00"},
+				"gasUsedRatio":  []float64{0, 0},
+				"reward":        [][]string{{}, {"0x0"}},
+			},
+			tip:    big.NewInt(2_000_000_000), // suggested by node
+			feeCap: big.NewInt(4_000_000_000),
+		},
+		{
+			name: "pre-london zero base fee",
+			fees: NewDynamicFees(50, 2),
+			history: map[string]interface{}{
+				"oldestBlock":   "0x1",
+				"baseFeePerGas": []string{"0x0", "0x0", "0x0"},
+				"gasUsedRatio":  []float64{0.5, 0.5},
+			},
+			price: big.NewInt(20_000_000_000),
+		},
+		{
+			name: "pre-london no base fee",
+			fees: NewDynamicFees(50, 2),
+			history: map[string]interface{}{
+				"oldestBlock":  "0x1",
+				"gasUsedRatio": []float64{0.5, 0.5},
+			},
+			price: big.NewInt(20_000_000_000),
+		},
+		{
+			name:  "legacy",
+			fees:  LegacyFees{},
+			price: big.NewInt(20_000_000_000),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			node := newFeeNode(t, map[string]interface{}{
+				"eth_feeHistory":           test.history,
+				"eth_gasPrice":             gasPrice,
+				"eth_maxPriorityFeePerGas": tipCap,
+			})
+
+			// fields of previous pricing are reset
+			opts := &bind.TransactOpts{
+				GasPrice:  big.NewInt(1),
+				GasTipCap: big.NewInt(1),
+				GasFeeCap: big.NewInt(1),
+			}
+			if err := applyFees(context.Background(), test.fees, node, opts); err != nil {
+				t.Fatal(err)
+			}
+
+			for _, f := range []struct {
+				name      string
+				got, want *big.Int
+			}{
+				{"tip", opts.GasTipCap, test.tip},
+				{"fee cap", opts.GasFeeCap, test.feeCap},
+				{"gas price", opts.GasPrice, test.price},
+			} {
+				if (f.got == nil) != (f.want == nil) ||
+					f.want != nil && f.got.Cmp(f.want) != 0 {
+					t.Errorf("%s %v, expected %v", f.name, f.got, f.want)
+				}
+			}
+			if opts.GasFeeCap != nil && opts.GasFeeCap.Cmp(opts.GasTipCap) < 0 {
+				t.Errorf("fee cap %v below tip %v", opts.GasFeeCap, opts.GasTipCap)
+			}
+		})
+	}
+}
+
+func TestDynamicFeesHistoryError(t *testing.T) {
+	// node without eth_feeHistory
+	node := newFeeNode(t, map[string]interface{}{"eth_gasPrice": "0x1"})
+
+	err := applyFees(context.Background(), NewDynamicFees(0, 0), node, &bind.TransactOpts{})
+	if err == nil {
+		t.Error("fee history error ignored")
+	}
+}
+
+func TestMarginBackend(t *testing.T) {
+	node := newFeeNode(t, map[string]interface{}{
+		"eth_estimateGas": "0x5208", // 21000
+	})
+
+	for margin, want := range map[uint64]uint64{0: 21_000, 20: 25_200, 100: 42_000} {
+		mb := &marginBackend{node, margin}
+
+		gas, err := mb.EstimateGas(context.Background(), ethereum.CallMsg{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if gas != want {
+			t.Errorf("margin %v%%: gas %v, expected %v", margin, gas, want)
+		}
+	}
+}
@@ -0,0 +1,221 @@
+package ethereum
+
+import (
+	"context"
+	"math/big"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// NonceSource is a node api used to read account nonces
+type NonceSource interface {
+	PendingNonceAt(context.Context, common.Address) (uint64, error)
+	NonceAt(context.Context, common.Address, *big.Int) (uint64, error)
+}
+
+// NonceManager hands out nonces of one wallet under a lock,
+// so concurrent transactions never share a nonce
+type NonceManager struct {
+	mu       sync.Mutex
+	source   NonceSource
+	address  common.Address
+	next     uint64
+	inflight map[uint64]common.Hash
+	released map[uint64]struct{} // below next, handed out before next
+}
+
+func NewNonceManager(source NonceSource, address common.Address) *NonceManager {
+	return &NonceManager{
+		source:   source,
+		address:  address,
+		inflight: make(map[uint64]common.Hash),
+		released: make(map[uint64]struct{}),
+	}
+}
+
+func (nm *NonceManager) Address() common.Address {
+	return nm.address
+}
+
+// Next reserves nonce for a new transaction, the lowest released
+// nonce is reused first so no gap is left. Transactions sent from
+// the wallet outside of manager are respected
+func (nm *NonceManager) Next(ctx context.Context) (
+	nonce uint64,
+	err error,
+) {
+	nm.mu.Lock()
+	defer nm.mu.Unlock()
+
+	pending, err := nm.source.PendingNonceAt(ctx, nm.address)
+	if err != nil {
+		return
+	}
+	nm.dropReleased(pending)
+
+	if low, ok := nm.lowestReleased(); ok {
+		delete(nm.released, low)
+		nm.inflight[low] = common.Hash{}
+		nonce = low
+
+		return
+	}
+
+	nonce = nm.next
+	if pending > nonce {
+		nonce = pending
+	}
+	nm.next = nonce + 1
+	nm.inflight[nonce] = common.Hash{}
+
+	return
+}
+
+// Sent records hash of transaction sent with reserved nonce
+func (nm *NonceManager) Sent(nonce uint64, hash common.Hash) {
+	nm.mu.Lock()
+	defer nm.mu.Unlock()
+
+	nm.inflight[nonce] = hash
+}
+
+// Release returns reserved nonce of transaction that was not sent,
+// it's handed out again before any higher one
+func (nm *NonceManager) Release(nonce uint64) {
+	nm.mu.Lock()
+	defer nm.mu.Unlock()
+
+	if _, ok := nm.inflight[nonce]; !ok || nonce >= nm.next {
+		return
+	}
+	delete(nm.inflight, nonce)
+	nm.released[nonce] = struct{}{}
+
+	// released nonces at the top just lower next
+	for nm.next > 0 {
+		if _, ok := nm.released[nm.next-1]; !ok {
+			break
+		}
+		nm.next--
+		delete(nm.released, nm.next)
+	}
+}
+
+// Confirm forgets transaction once it is mined
+func (nm *NonceManager) Confirm(nonce uint64) {
+	nm.mu.Lock()
+	defer nm.mu.Unlock()
+
+	delete(nm.inflight, nonce)
+}
+
+// InFlight returns sent transactions which are not confirmed yet
+func (nm *NonceManager) InFlight() (
+	txs map[uint64]common.Hash,
+) {
+	nm.mu.Lock()
+	defer nm.mu.Unlock()
+
+	txs = make(map[uint64]common.Hash, len(nm.inflight))
+	for nonce, hash := range nm.inflight {
+		txs[nonce] = hash
+	}
+
+	return
+}
+
+// Pending returns number of reserved & sent transactions not confirmed
+func (nm *NonceManager) Pending() int {
+	nm.mu.Lock()
+	defer nm.mu.Unlock()
+
+	return len(nm.inflight)
+}
+
+// Resync takes next nonce from the chain after a dropped or rejected
+// transaction. Mined nonces are forgotten, reservations are kept until
+// they are released, so a nonce is never handed out twice
+func (nm *NonceManager) Resync(ctx context.Context) (
+	err error,
+) {
+	nm.mu.Lock()
+	defer nm.mu.Unlock()
+
+	mined, err := nm.source.NonceAt(ctx, nm.address, nil)
+	if err != nil {
+		return
+	}
+	pending, err := nm.source.PendingNonceAt(ctx, nm.address)
+	if err != nil {
+		return
+	}
+
+	next := pending
+	for nonce := range nm.inflight {
+		if nonce < mined {
+			delete(nm.inflight, nonce)
+
+			continue
+		}
+		if nonce >= next {
+			next = nonce + 1
+		}
+	}
+	nm.next = next
+
+	// released nonces the node already has or that are above next
+	// are not gaps anymore
+	nm.dropReleased(pending)
+	for nonce := range nm.released {
+		if nonce >= next {
+			delete(nm.released, nonce)
+		}
+	}
+
+	return
+}
+
+// dropReleased forgets released nonces below pending nonce of node,
+// transactions of them were sent outside of manager
+func (nm *NonceManager) dropReleased(pending uint64) {
+	for nonce := range nm.released {
+		if nonce < pending {
+			delete(nm.released, nonce)
+		}
+	}
+}
+
+func (nm *NonceManager) lowestReleased() (
+	low uint64,
+	ok bool,
+) {
+	for nonce := range nm.released {
+		if !ok || nonce < low {
+			low, ok = nonce, true
+		}
+	}
+
+	return
+}
+
+// IsNonceError reports whether node rejected transaction because
+// its nonce is already used or out of order
+func IsNonceError(err error) bool {
+	if err == nil {
+		return false
+	}
+	msg := strings.ToLower(err.Error())
+
+	for _, s := range []string{
+		"nonce too low",
+		"invalid transaction nonce",
+	} {
+		if strings.Contains(msg, s) {
+			return true
+		}
+	}
+
+	return false
+}
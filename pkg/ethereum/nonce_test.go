@@ -0,0 +1,342 @@
+package ethereum
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"math/big"
+	"sync"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind/backends"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+func newTestBackend(t *testing.T) (
+	*backends.SimulatedBackend,
+	*ecdsa.PrivateKey,
+	common.Address,
+) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := crypto.PubkeyToAddress(key.PublicKey)
+
+	sim := backends.NewSimulatedBackend(core.GenesisAlloc{
+		addr: {Balance: new(big.Int).Mul(big.NewInt(100), big.NewInt(params.Ether))},
+	}, 10_000_000)
+	t.Cleanup(func() { sim.Close() })
+
+	return sim, key, addr
+}
+
+func sendTestTx(
+	t *testing.T,
+	sim *backends.SimulatedBackend,
+	key *ecdsa.PrivateKey,
+	nonce uint64,
+) (
+	*types.Transaction,
+	error,
+) {
+	ctx := context.Background()
+
+	head, err := sim.HeaderByNumber(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tx, err := types.SignTx(
+		types.NewTransaction(
+			nonce,
+			common.HexToAddress("0x0000000000000000000000000000000000000001"),
+			big.NewInt(1),
+			params.TxGas,
+			new(big.Int).Mul(head.BaseFee, big.NewInt(2)),
+			nil,
+		),
+		types.LatestSigner(sim.Blockchain().Config()),
+		key,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return tx, sim.SendTransaction(ctx, tx)
+}
+
+func TestNonceManagerConcurrent(t *testing.T) {
+	sim, key, addr := newTestBackend(t)
+	nm := NewNonceManager(sim, addr)
+	ctx := context.Background()
+
+	const n = 20
+
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		seen = make(map[uint64]bool)
+	)
+
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			nonce, err := nm.Next(ctx)
+			if err != nil {
+				t.Error(err)
+
+				return
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			if seen[nonce] {
+				t.Errorf("nonce %v handed out twice", nonce)
+			}
+			seen[nonce] = true
+		}()
+	}
+	wg.Wait()
+
+	for nonce := uint64(0); nonce < n; nonce++ {
+		if !seen[nonce] {
+			t.Fatalf("nonce %v skipped", nonce)
+		}
+
+		tx, err := sendTestTx(t, sim, key, nonce)
+		if err != nil {
+			t.Fatal(err)
+		}
+		nm.Sent(nonce, tx.Hash())
+	}
+	if len(nm.InFlight()) != n {
+		t.Errorf("%v txs in flight, expected %v", len(nm.InFlight()), n)
+	}
+
+	sim.Commit()
+
+	if err := nm.Resync(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if len(nm.InFlight()) != 0 {
+		t.Errorf("mined txs are still in flight: %v", nm.InFlight())
+	}
+
+	next, err := nm.Next(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if next != n {
+		t.Errorf("next nonce %v, expected %v", next, n)
+	}
+}
+
+func TestNonceManagerRelease(t *testing.T) {
+	nm := NewNonceManager(stubNonces{}, common.Address{})
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		if _, err := nm.Next(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// the last nonce is reused
+	nm.Release(2)
+
+	next, err := nm.Next(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if next != 2 {
+		t.Errorf("released nonce 2 not reused, got %v", next)
+	}
+
+	// nonce in the middle is handed out again while higher are held,
+	// the lowest released first
+	nm.Release(1)
+	nm.Release(0)
+
+	for _, want := range []uint64{0, 1, 3} {
+		next, err = nm.Next(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if next != want {
+			t.Errorf("next nonce %v, expected %v", next, want)
+		}
+	}
+
+	// resync keeps the gap while higher nonces are in flight
+	nm.Release(1)
+	if err = nm.Resync(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	next, err = nm.Next(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if next != 1 {
+		t.Errorf("next nonce %v after resync, expected released 1", next)
+	}
+}
+
+func TestNonceManagerResyncDropped(t *testing.T) {
+	sim, key, addr := newTestBackend(t)
+	nm := NewNonceManager(sim, addr)
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		if _, err := nm.Next(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// only the first tx reaches the node, the rest are still reserved
+	tx, err := sendTestTx(t, sim, key, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	nm.Sent(0, tx.Hash())
+
+	if err = nm.Resync(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if inflight := nm.InFlight(); len(inflight) != 3 {
+		t.Errorf("in flight %v, reservations dropped", inflight)
+	}
+
+	next, err := nm.Next(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if next != 3 {
+		t.Errorf("next nonce %v after resync, expected 3", next)
+	}
+
+	// dropped ones are released explicitly
+	for _, nonce := range []uint64{3, 2, 1} {
+		nm.Release(nonce)
+	}
+	if err = nm.Resync(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	inflight := nm.InFlight()
+	if len(inflight) != 1 || inflight[0] != tx.Hash() {
+		t.Errorf("in flight %v, expected only %s", inflight, tx.Hash())
+	}
+
+	next, err = nm.Next(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if next != 1 {
+		t.Errorf("next nonce %v after release, expected 1", next)
+	}
+}
+
+// stubNonces is a node which never sees sent transactions
+type stubNonces struct {
+	mined, pending uint64
+}
+
+func (s stubNonces) PendingNonceAt(context.Context, common.Address) (uint64, error) {
+	return s.pending, nil
+}
+
+func (s stubNonces) NonceAt(context.Context, common.Address, *big.Int) (uint64, error) {
+	return s.mined, nil
+}
+
+func TestNonceManagerResyncConcurrent(t *testing.T) {
+	nm := NewNonceManager(stubNonces{}, common.Address{})
+	ctx := context.Background()
+
+	const n = 50
+
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		seen = make(map[uint64]bool)
+	)
+
+	for i := 0; i < n; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+
+			nonce, err := nm.Next(ctx)
+			if err != nil {
+				t.Error(err)
+
+				return
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			if seen[nonce] {
+				t.Errorf("nonce %v reserved twice", nonce)
+			}
+			seen[nonce] = true
+		}()
+		go func() {
+			defer wg.Done()
+
+			if err := nm.Resync(ctx); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if nm.Pending() != n {
+		t.Errorf("%v reservations, expected %v", nm.Pending(), n)
+	}
+}
+
+func TestNonceManagerNonceTooLow(t *testing.T) {
+	sim, key, addr := newTestBackend(t)
+	nm := NewNonceManager(sim, addr)
+	ctx := context.Background()
+
+	nonce, err := nm.Next(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// another sender uses the same wallet & nonce
+	if _, err = sendTestTx(t, sim, key, nonce); err != nil {
+		t.Fatal(err)
+	}
+	sim.Commit()
+
+	_, err = sendTestTx(t, sim, key, nonce)
+	if !IsNonceError(err) {
+		t.Fatalf("expected nonce error, got %v", err)
+	}
+
+	if err = nm.Resync(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	next, err := nm.Next(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if next != nonce+1 {
+		t.Errorf("next nonce %v after resync, expected %v", next, nonce+1)
+	}
+	if _, err = sendTestTx(t, sim, key, next); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,27 @@
+package ethereum
+
+// Option -.
+type Option func(*Client)
+
+// Fees sets strategy used to price transactions
+func Fees(strategy FeeStrategy) Option {
+	return func(c *Client) {
+		if strategy != nil {
+			c.fees = strategy
+		}
+	}
+}
+
+// GasMargin sets percent added to estimated gas limit
+func GasMargin(percent uint64) Option {
+	return func(c *Client) {
+		c.gasMargin = percent
+	}
+}
+
+// WalletSelection sets how pool picks wallet of pooled transaction
+func WalletSelection(s Selection) Option {
+	return func(c *Client) {
+		c.selection = s
+	}
+}
@@ -0,0 +1,326 @@
+package ethereum
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Selection is a way pool picks wallet for next transaction
+type Selection string
+
+const (
+	// RoundRobin takes the next wallet without in-flight transactions,
+	// or just the next one if every wallet is busy
+	RoundRobin Selection = "round-robin"
+	// LeastPending takes wallet with the fewest in-flight transactions
+	LeastPending Selection = "least-pending"
+)
+
+var (
+	ErrNoWallet      = errors.New("no enabled wallet in pool")
+	ErrUnknownWallet = errors.New("unknown wallet")
+	ErrWalletExists  = errors.New("wallet already added")
+)
+
+// ParseSelection returns selection by name, empty name is round-robin
+func ParseSelection(name string) (
+	s Selection,
+	err error,
+) {
+	switch Selection(name) {
+	case "", RoundRobin:
+		s = RoundRobin
+	case LeastPending:
+		s = LeastPending
+	default:
+		err = fmt.Errorf("unknown wallet selection %q", name)
+	}
+
+	return
+}
+
+// WalletSource is a node api used to read wallet nonces & balances
+type WalletSource interface {
+	NonceSource
+	BalanceAt(context.Context, common.Address, *big.Int) (*big.Int, error)
+}
+
+// WalletState is a snapshot of pooled wallet, it never holds the key
+type WalletState struct {
+	Address   common.Address
+	Enabled   bool
+	Pending   int      // reserved & sent transactions not confirmed
+	Nonce     uint64   // pending nonce on chain at last refresh
+	Balance   *big.Int // wei at last refresh, nil before it
+	UpdatedAt time.Time
+}
+
+type pooled struct {
+	wallet   *Wallet
+	nonces   *NonceManager
+	disabled bool
+
+	balance   *big.Int
+	nonce     uint64
+	updatedAt time.Time
+}
+
+// WalletPool holds signers transactions are spread over, every wallet
+// has own nonce manager so wallets never wait for each other
+type WalletPool struct {
+	mu        sync.Mutex
+	source    WalletSource
+	selection Selection
+	next      int
+	wallets   []*pooled
+}
+
+func NewWalletPool(source WalletSource, selection Selection) *WalletPool {
+	if selection == "" {
+		selection = RoundRobin
+	}
+
+	return &WalletPool{
+		source:    source,
+		selection: selection,
+	}
+}
+
+// Add puts enabled wallet to pool
+func (wp *WalletPool) Add(wall *Wallet) (
+	err error,
+) {
+	wp.mu.Lock()
+	defer wp.mu.Unlock()
+
+	if wp.find(wall.Address) != nil {
+		err = fmt.Errorf("%w %s", ErrWalletExists, wall.Address)
+
+		return
+	}
+
+	wp.wallets = append(wp.wallets, &pooled{
+		wallet: wall,
+		nonces: NewNonceManager(wp.source, wall.Address),
+	})
+
+	return
+}
+
+// SetEnabled includes wallet in selection or excludes it,
+// in-flight transactions of disabled wallet are still tracked
+func (wp *WalletPool) SetEnabled(address common.Address, enabled bool) (
+	err error,
+) {
+	wp.mu.Lock()
+	defer wp.mu.Unlock()
+
+	p := wp.find(address)
+	if p == nil {
+		err = fmt.Errorf("%w %s", ErrUnknownWallet, address)
+
+		return
+	}
+	p.disabled = !enabled
+
+	return
+}
+
+// Nonces returns nonce manager of wallet, nil if it is not in pool
+func (wp *WalletPool) Nonces(address common.Address) *NonceManager {
+	wp.mu.Lock()
+	defer wp.mu.Unlock()
+
+	p := wp.find(address)
+	if p == nil {
+		return nil
+	}
+
+	return p.nonces
+}
+
+// Acquire picks wallet for next transaction & reserves its nonce.
+// Selection & reservation are done under one lock, so concurrent
+// transactions are spread over free wallets
+func (wp *WalletPool) Acquire(ctx context.Context) (
+	wall *Wallet,
+	nonces *NonceManager,
+	nonce uint64,
+	err error,
+) {
+	wp.mu.Lock()
+	defer wp.mu.Unlock()
+
+	p, i := wp.pick()
+	if p == nil {
+		err = ErrNoWallet
+
+		return
+	}
+
+	nonce, err = p.nonces.Next(ctx)
+	if err != nil {
+		return
+	}
+	wp.next = i + 1
+	wall, nonces = p.wallet, p.nonces
+
+	return
+}
+
+// pick returns enabled wallet by selection starting after the last picked
+func (wp *WalletPool) pick() (
+	p *pooled,
+	index int,
+) {
+	best := -1
+
+	n := len(wp.wallets)
+	for k := 0; k < n; k++ {
+		i := (wp.next + k) % n
+		w := wp.wallets[i]
+		if w.disabled {
+			continue
+		}
+		if best < 0 {
+			best = i
+		}
+
+		pending := w.nonces.Pending()
+		if wp.selection == LeastPending {
+			if pending < wp.wallets[best].nonces.Pending() {
+				best = i
+			}
+
+			continue
+		}
+		if pending == 0 {
+			best = i
+
+			break
+		}
+	}
+	if best < 0 {
+		return
+	}
+	p, index = wp.wallets[best], best
+
+	return
+}
+
+// Refresh reads balance & pending nonce of every wallet
+func (wp *WalletPool) Refresh(ctx context.Context) (
+	err error,
+) {
+	wp.mu.Lock()
+	wallets := make([]*pooled, len(wp.wallets))
+	copy(wallets, wp.wallets)
+	wp.mu.Unlock()
+
+	for _, p := range wallets {
+		addr := p.wallet.Address
+
+		balance, _err := wp.source.BalanceAt(ctx, addr, nil)
+		if _err != nil {
+			err = fmt.Errorf("balance of %s: %w", addr, _err)
+
+			return
+		}
+		nonce, _err := wp.source.PendingNonceAt(ctx, addr)
+		if _err != nil {
+			err = fmt.Errorf("nonce of %s: %w", addr, _err)
+
+			return
+		}
+
+		wp.mu.Lock()
+		p.balance, p.nonce = balance, nonce
+		p.updatedAt = time.Now().UTC()
+		wp.mu.Unlock()
+	}
+
+	return
+}
+
+// States returns snapshot of wallets in order they were added
+func (wp *WalletPool) States() (
+	states []WalletState,
+) {
+	wp.mu.Lock()
+	defer wp.mu.Unlock()
+
+	states = make([]WalletState, len(wp.wallets))
+	for i, p := range wp.wallets {
+		states[i] = p.state()
+	}
+
+	return
+}
+
+// State returns snapshot of wallet
+func (wp *WalletPool) State(address common.Address) (
+	state WalletState,
+	err error,
+) {
+	wp.mu.Lock()
+	defer wp.mu.Unlock()
+
+	p := wp.find(address)
+	if p == nil {
+		err = fmt.Errorf("%w %s", ErrUnknownWallet, address)
+
+		return
+	}
+	state = p.state()
+
+	return
+}
+
+func (wp *WalletPool) wallet(address common.Address) (
+	wall *Wallet,
+	err error,
+) {
+	wp.mu.Lock()
+	defer wp.mu.Unlock()
+
+	p := wp.find(address)
+	if p == nil {
+		err = fmt.Errorf("%w %s", ErrUnknownWallet, address)
+
+		return
+	}
+	wall = p.wallet
+
+	return
+}
+
+func (wp *WalletPool) find(address common.Address) *pooled {
+	for _, p := range wp.wallets {
+		if p.wallet.Address == address {
+			return p
+		}
+	}
+
+	return nil
+}
+
+func (p *pooled) state() WalletState {
+	s := WalletState{
+		Address:   p.wallet.Address,
+		Enabled:   !p.disabled,
+		Pending:   p.nonces.Pending(),
+		Nonce:     p.nonce,
+		UpdatedAt: p.updatedAt,
+	}
+	if p.balance != nil {
+		s.Balance = new(big.Int).Set(p.balance)
+	}
+
+	return s
+}
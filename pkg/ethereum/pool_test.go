@@ -0,0 +1,206 @@
+package ethereum
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sync"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+
+	"github.com/antonyuhnovets/flash-loan-arbitrage/pkg/signer"
+)
+
+func newTestPool(t *testing.T, selection Selection, n int) (
+	*WalletPool,
+	[]*Wallet,
+) {
+	sim, key, addr := newTestBackend(t)
+	pool := NewWalletPool(sim, selection)
+
+	wallets := []*Wallet{NewWallet(signer.FromECDSA(key))}
+	if wallets[0].Address != addr {
+		t.Fatalf("wallet %s of funded %s", wallets[0].Address, addr)
+	}
+	for len(wallets) < n {
+		k, err := crypto.GenerateKey()
+		if err != nil {
+			t.Fatal(err)
+		}
+		wallets = append(wallets, NewWallet(signer.FromECDSA(k)))
+	}
+
+	for _, wall := range wallets {
+		if err := pool.Add(wall); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return pool, wallets
+}
+
+func TestWalletPoolRoundRobin(t *testing.T) {
+	pool, wallets := newTestPool(t, RoundRobin, 3)
+	ctx := context.Background()
+
+	for i, want := range wallets {
+		wall, _, _, err := pool.Acquire(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if wall != want {
+			t.Errorf("acquire %v: got %s, expected %s", i, wall.Address, want.Address)
+		}
+	}
+
+	// every wallet is busy, rotation goes on
+	wall, _, _, err := pool.Acquire(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if wall != wallets[0] {
+		t.Errorf("got %s, expected %s", wall.Address, wallets[0].Address)
+	}
+
+	// the only free wallet is picked out of turn
+	pool.Nonces(wallets[2].Address).Confirm(0)
+
+	wall, _, _, err = pool.Acquire(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if wall != wallets[2] {
+		t.Errorf("got %s, expected free %s", wall.Address, wallets[2].Address)
+	}
+}
+
+func TestWalletPoolLeastPending(t *testing.T) {
+	pool, wallets := newTestPool(t, LeastPending, 2)
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		if _, _, _, err := pool.Acquire(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	states := pool.States()
+	if states[0].Pending != 2 || states[1].Pending != 1 {
+		t.Fatalf("pending %v & %v, expected 2 & 1", states[0].Pending, states[1].Pending)
+	}
+
+	pool.Nonces(wallets[1].Address).Confirm(0)
+
+	for i := 0; i < 2; i++ {
+		wall, _, nonce, err := pool.Acquire(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if wall != wallets[1] {
+			t.Errorf("acquire %v: got %s, expected %s", i, wall.Address, wallets[1].Address)
+		}
+		if nonce != uint64(i+1) {
+			t.Errorf("acquire %v: nonce %v, expected %v", i, nonce, i+1)
+		}
+	}
+}
+
+func TestWalletPoolDisabled(t *testing.T) {
+	pool, wallets := newTestPool(t, RoundRobin, 2)
+	ctx := context.Background()
+
+	if err := pool.SetEnabled(wallets[0].Address, false); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 2; i++ {
+		wall, _, _, err := pool.Acquire(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if wall != wallets[1] {
+			t.Errorf("disabled wallet %s picked", wall.Address)
+		}
+	}
+
+	if err := pool.SetEnabled(wallets[1].Address, false); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, _, err := pool.Acquire(ctx); !errors.Is(err, ErrNoWallet) {
+		t.Errorf("expected %v, got %v", ErrNoWallet, err)
+	}
+
+	k, _ := crypto.GenerateKey()
+	err := pool.SetEnabled(crypto.PubkeyToAddress(k.PublicKey), true)
+	if !errors.Is(err, ErrUnknownWallet) {
+		t.Errorf("expected %v, got %v", ErrUnknownWallet, err)
+	}
+	if err = pool.Add(wallets[0]); !errors.Is(err, ErrWalletExists) {
+		t.Errorf("expected %v, got %v", ErrWalletExists, err)
+	}
+}
+
+func TestWalletPoolConcurrent(t *testing.T) {
+	pool, wallets := newTestPool(t, RoundRobin, 4)
+	ctx := context.Background()
+
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		seen = make(map[*Wallet]bool)
+	)
+
+	for range wallets {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			wall, _, _, err := pool.Acquire(ctx)
+			if err != nil {
+				t.Error(err)
+
+				return
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			if seen[wall] {
+				t.Errorf("wallet %s picked twice while others are free", wall.Address)
+			}
+			seen[wall] = true
+		}()
+	}
+	wg.Wait()
+}
+
+func TestWalletPoolRefresh(t *testing.T) {
+	pool, wallets := newTestPool(t, RoundRobin, 2)
+
+	if b := pool.States()[0].Balance; b != nil {
+		t.Errorf("balance %s before refresh", b)
+	}
+
+	if err := pool.Refresh(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	funded := new(big.Int).Mul(big.NewInt(100), big.NewInt(params.Ether))
+	for i, s := range pool.States() {
+		if s.Address != wallets[i].Address {
+			t.Errorf("state %v of %s, expected %s", i, s.Address, wallets[i].Address)
+		}
+		want := big.NewInt(0)
+		if i == 0 {
+			want = funded
+		}
+		if s.Balance == nil || s.Balance.Cmp(want) != 0 {
+			t.Errorf("wallet %v balance %v, expected %s", i, s.Balance, want)
+		}
+		if s.UpdatedAt.IsZero() {
+			t.Errorf("wallet %v not refreshed", i)
+		}
+	}
+}
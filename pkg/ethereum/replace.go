@@ -0,0 +1,218 @@
+package ethereum
+
+import (
+	"context"
+	"errors"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// MinBumpPercent is the fee increase nodes require to replace
+// pending transaction with the same nonce
+const MinBumpPercent = 10
+
+var ErrNotPending = errors.New("transaction is not pending")
+
+// SpeedUp resends pending transaction with the same nonce & bumped fees
+func (c *Client) SpeedUp(
+	ctx context.Context,
+	hash string,
+	percent uint64,
+) (
+	tx *types.Transaction,
+	err error,
+) {
+	opts, old, err := c.replaceOpts(ctx, ToHash(hash), percent)
+	if err != nil {
+		return
+	}
+
+	tx, err = c.sendReplacement(
+		ctx, opts, old.To(), old.Value(), old.Gas(), old.Data(),
+	)
+
+	return
+}
+
+// Cancel replaces pending transaction with zero-value self-transfer
+func (c *Client) Cancel(
+	ctx context.Context,
+	hash string,
+	percent uint64,
+) (
+	tx *types.Transaction,
+	err error,
+) {
+	opts, _, err := c.replaceOpts(ctx, ToHash(hash), percent)
+	if err != nil {
+		return
+	}
+
+	to := opts.From
+	tx, err = c.sendReplacement(
+		ctx, opts, &to, big.NewInt(0), params.TxGas, nil,
+	)
+
+	return
+}
+
+// FillNonce sends zero-value self-transfer from wallet with nonce of
+// dropped transaction, so higher nonces sent after it aren't stuck
+func (c *Client) FillNonce(
+	ctx context.Context,
+	from common.Address,
+	nonce uint64,
+) (
+	tx *types.Transaction,
+	err error,
+) {
+	wall, err := c.wallets.wallet(from)
+	if err != nil {
+		return
+	}
+
+	opts, err := c.transactOpts(ctx, wall, nonce)
+	if err != nil {
+		return
+	}
+
+	tx, err = c.sendReplacement(
+		ctx, opts, &from, big.NewInt(0), params.TxGas, nil,
+	)
+
+	return
+}
+
+func (c *Client) replaceOpts(
+	ctx context.Context,
+	hash common.Hash,
+	percent uint64,
+) (
+	opts *bind.TransactOpts,
+	old *types.Transaction,
+	err error,
+) {
+	old, pending, err := c.Client.TransactionByHash(ctx, hash)
+	if err != nil {
+		return
+	}
+	if !pending {
+		err = ErrNotPending
+
+		return
+	}
+
+	// replacement has to be signed by sender of pending tx
+	from, err := Sender(old)
+	if err != nil {
+		return
+	}
+	wall, err := c.wallets.wallet(from)
+	if err != nil {
+		return
+	}
+
+	opts, err = c.transactOpts(ctx, wall, old.Nonce())
+	if err != nil {
+		return
+	}
+	BumpFees(opts, old, percent)
+
+	return
+}
+
+func (c *Client) sendReplacement(
+	ctx context.Context,
+	opts *bind.TransactOpts,
+	to *common.Address,
+	value *big.Int,
+	gas uint64,
+	data []byte,
+) (
+	tx *types.Transaction,
+	err error,
+) {
+	var inner types.TxData
+
+	if opts.GasPrice != nil {
+		inner = &types.LegacyTx{
+			Nonce:    opts.Nonce.Uint64(),
+			GasPrice: opts.GasPrice,
+			Gas:      gas,
+			To:       to,
+			Value:    value,
+			Data:     data,
+		}
+	} else {
+		inner = &types.DynamicFeeTx{
+			ChainID:   c.chainID(),
+			Nonce:     opts.Nonce.Uint64(),
+			GasTipCap: opts.GasTipCap,
+			GasFeeCap: opts.GasFeeCap,
+			Gas:       gas,
+			To:        to,
+			Value:     value,
+			Data:      data,
+		}
+	}
+
+	tx, err = opts.Signer(opts.From, types.NewTx(inner))
+	if err != nil {
+		return
+	}
+
+	err = c.Client.SendTransaction(ctx, tx)
+	if err != nil {
+		return
+	}
+	c.Sent(tx)
+
+	return
+}
+
+// BumpFees raises fees of options to replace old transaction:
+// each fee is at least percent above the old one, suggested fees
+// are kept if they are higher
+func BumpFees(
+	opts *bind.TransactOpts,
+	old *types.Transaction,
+	percent uint64,
+) {
+	if percent < MinBumpPercent {
+		percent = MinBumpPercent
+	}
+
+	if opts.GasPrice != nil {
+		// legacy price has to cover both fee cap & tip of old tx
+		opts.GasPrice = bump(old.GasFeeCap(), opts.GasPrice, percent)
+
+		return
+	}
+
+	opts.GasTipCap = bump(old.GasTipCap(), opts.GasTipCap, percent)
+	opts.GasFeeCap = bump(old.GasFeeCap(), opts.GasFeeCap, percent)
+
+	if opts.GasFeeCap.Cmp(opts.GasTipCap) < 0 {
+		opts.GasFeeCap = new(big.Int).Set(opts.GasTipCap)
+	}
+}
+
+// bump returns old value raised by percent rounding up,
+// or suggested value if it is higher
+func bump(old, suggested *big.Int, percent uint64) (
+	out *big.Int,
+) {
+	out = new(big.Int).Mul(old, new(big.Int).SetUint64(100+percent))
+	out.Add(out, big.NewInt(99))
+	out.Quo(out, big.NewInt(100))
+
+	if suggested != nil && suggested.Cmp(out) > 0 {
+		out = new(big.Int).Set(suggested)
+	}
+
+	return
+}
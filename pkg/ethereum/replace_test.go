@@ -0,0 +1,85 @@
+package ethereum
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+func TestBumpFees(t *testing.T) {
+	dynamic := types.NewTx(&types.DynamicFeeTx{
+		GasTipCap: big.NewInt(2_000_000_000),
+		GasFeeCap: big.NewInt(30_000_000_000),
+	})
+	legacy := types.NewTx(&types.LegacyTx{
+		GasPrice: big.NewInt(25_000_000_001),
+	})
+
+	tests := []struct {
+		name    string
+		old     *types.Transaction
+		opts    bind.TransactOpts
+		percent uint64
+		tip     *big.Int
+		feeCap  *big.Int
+		price   *big.Int
+	}{
+		{
+			name: "dynamic below rule",
+			old:  dynamic,
+			opts: bind.TransactOpts{
+				GasTipCap: big.NewInt(1_000_000_000),
+				GasFeeCap: big.NewInt(20_000_000_000),
+			},
+			percent: 5, // raised to replacement minimum
+			tip:     big.NewInt(2_200_000_000),
+			feeCap:  big.NewInt(33_000_000_000),
+		},
+		{
+			name: "dynamic suggested above bump",
+			old:  dynamic,
+			opts: bind.TransactOpts{
+				GasTipCap: big.NewInt(3_000_000_000),
+				GasFeeCap: big.NewInt(50_000_000_000),
+			},
+			percent: 10,
+			tip:     big.NewInt(3_000_000_000),
+			feeCap:  big.NewInt(50_000_000_000),
+		},
+		{
+			name:    "legacy rounds up",
+			old:     legacy,
+			opts:    bind.TransactOpts{GasPrice: big.NewInt(1)},
+			percent: 20,
+			price:   big.NewInt(30_000_000_002),
+		},
+		{
+			name:    "legacy replaces dynamic",
+			old:     dynamic,
+			opts:    bind.TransactOpts{GasPrice: big.NewInt(1)},
+			percent: 10,
+			price:   big.NewInt(33_000_000_000),
+		},
+	}
+
+	for _, test := range tests {
+		opts := test.opts
+		BumpFees(&opts, test.old, test.percent)
+
+		if test.price != nil {
+			if opts.GasPrice.Cmp(test.price) != 0 {
+				t.Errorf("%s: gas price %s, expected %s", test.name, opts.GasPrice, test.price)
+			}
+
+			continue
+		}
+		if opts.GasTipCap.Cmp(test.tip) != 0 || opts.GasFeeCap.Cmp(test.feeCap) != 0 {
+			t.Errorf(
+				"%s: tip %s fee cap %s, expected %s %s",
+				test.name, opts.GasTipCap, opts.GasFeeCap, test.tip, test.feeCap,
+			)
+		}
+	}
+}
@@ -0,0 +1,162 @@
+package ethereum
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+var (
+	ErrNoRevertData = errors.New("call reverted without data")
+	ErrReverted     = errors.New("transaction reverted")
+)
+
+// RevertData extracts revert data returned by node with call error
+func RevertData(err error) (
+	data []byte,
+	ok bool,
+) {
+	var de rpc.DataError
+	if !errors.As(err, &de) {
+		return
+	}
+
+	s, isString := de.ErrorData().(string)
+	if !isString {
+		return
+	}
+
+	data, _err := hexutil.Decode(s)
+	if _err != nil {
+		return
+	}
+	ok = true
+
+	return
+}
+
+// Sender recovers address which signed transaction
+func Sender(tx *types.Transaction) (
+	from common.Address,
+	err error,
+) {
+	from, err = types.Sender(types.LatestSignerForChainID(tx.ChainId()), tx)
+
+	return
+}
+
+// ReplayTx repeats transaction as call on state before given block,
+// revert data is returned if call fails
+func (c *Client) ReplayTx(
+	ctx context.Context,
+	tx *types.Transaction,
+	block *big.Int,
+) (
+	data []byte,
+	err error,
+) {
+	from, err := Sender(tx)
+	if err != nil {
+		return
+	}
+
+	var parent *big.Int
+	if block != nil && block.Sign() > 0 {
+		parent = new(big.Int).Sub(block, big.NewInt(1))
+	}
+
+	_, err = c.Client.CallContract(ctx, ethereum.CallMsg{
+		From:  from,
+		To:    tx.To(),
+		Gas:   tx.Gas(),
+		Value: tx.Value(),
+		Data:  tx.Data(),
+	}, parent)
+	if err == nil {
+		return
+	}
+
+	data, ok := RevertData(err)
+	if !ok || len(data) == 0 {
+		err = ErrNoRevertData
+
+		return
+	}
+	err = nil
+
+	return
+}
+
+// Call runs message call against latest or pending state
+func (c *Client) Call(
+	ctx context.Context,
+	from, to common.Address,
+	data []byte,
+	pending bool,
+) (
+	out []byte,
+	err error,
+) {
+	msg := ethereum.CallMsg{
+		From: from,
+		To:   &to,
+		Data: data,
+	}
+
+	if pending {
+		out, err = c.Client.PendingCallContract(ctx, msg)
+
+		return
+	}
+	out, err = c.Client.CallContract(ctx, msg, nil)
+
+	return
+}
+
+// WaitMined waits for receipt of sent transaction & lets nonce manager
+// of sender forget it, reverted transaction fails with ErrReverted
+func (c *Client) WaitMined(ctx context.Context, tx *types.Transaction) (
+	receipt *types.Receipt,
+	err error,
+) {
+	receipt, err = bind.WaitMined(ctx, c.Client, tx)
+	if err != nil {
+		return
+	}
+
+	if from, _err := Sender(tx); _err == nil {
+		if nonces := c.wallets.Nonces(from); nonces != nil {
+			nonces.Confirm(tx.Nonce())
+		}
+	}
+
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		err = fmt.Errorf("%w: %s", ErrReverted, tx.Hash())
+	}
+
+	return
+}
+
+// DeployedCode returns runtime code which creation code deploys
+// with current wallet, creation is run as call & nothing is sent
+func (c *Client) DeployedCode(ctx context.Context, creation []byte) (
+	code []byte,
+	err error,
+) {
+	wall, _ := c.primary()
+
+	code, err = c.Client.CallContract(ctx, ethereum.CallMsg{
+		From: wall.Address,
+		Data: creation,
+	}, nil)
+
+	return
+}
@@ -1,62 +1,30 @@
 package ethereum
 
 import (
-	"crypto/ecdsa"
+	"context"
+	"math/big"
 
 	"github.com/ethereum/go-ethereum/common"
-	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/core/types"
 )
 
-type Wallet struct {
-	Address    common.Address
-	PubKey     *ecdsa.PublicKey
-	privateKey *ecdsa.PrivateKey
-}
-
-func NewWallet() *Wallet {
-	return &Wallet{}
-}
-
-func (wall *Wallet) Setup(key string) (
-	err error,
-) {
-	err = wall.setPK(key)
-	if err != nil {
-		return
-	}
-	err = wall.setPubKey()
-	if err != nil {
-		return
-	}
-	wall.setAddr()
-
-	return
+// Signer signs transactions of one account, key may be
+// held by keystore, remote signer or memory
+type Signer interface {
+	Address() common.Address
+	SignTx(
+		ctx context.Context, tx *types.Transaction, chainID *big.Int,
+	) (*types.Transaction, error)
 }
 
-func (wall *Wallet) setPK(key string) (
-	err error,
-) {
-	privateKey, err := crypto.HexToECDSA(key)
-	if err != nil {
-		return
-	}
-	wall.privateKey = privateKey
-
-	return
+type Wallet struct {
+	Address common.Address
+	signer  Signer
 }
 
-func (wall *Wallet) setPubKey() (
-	err error,
-) {
-	pubKey, err := PullPublicKey(wall.privateKey)
-	if err != nil {
-		return
+func NewWallet(signer Signer) *Wallet {
+	return &Wallet{
+		Address: signer.Address(),
+		signer:  signer,
 	}
-	wall.PubKey = pubKey
-
-	return
-}
-
-func (wall *Wallet) setAddr() {
-	wall.Address = crypto.PubkeyToAddress(*wall.PubKey)
 }
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"path/filepath"
 	"strings"
 )
 
@@ -50,6 +51,13 @@ func (fs *FileStorage) NewFile(
 
 	err error,
 ) {
+	// keep data of previous runs
+	if info, _err := os.Stat(path); _err == nil && info.Size() > 0 {
+		fs.UseFile(name, path)
+
+		return
+	}
+
 	f, err := os.Create(path)
 
 	if err != nil {
@@ -91,9 +99,9 @@ func (fs *FileStorage) Store(
 	if err != nil {
 		return
 	}
+	defer f.Close()
 
-	n, err := f.Write(item.([]byte))
-	log.Println(n)
+	_, err = f.Write(item.([]byte))
 
 	return
 }
@@ -111,7 +119,7 @@ func (fs *FileStorage) Read(
 	if err != nil {
 		return
 	}
-	_, ok := out.(*[]byte)
+	raw, ok := out.(*[]byte)
 	if !ok {
 		err = json.Unmarshal(b, out)
 		if err != nil {
@@ -119,8 +127,7 @@ func (fs *FileStorage) Read(
 			return
 		}
 	} else {
-		res := &b
-		out = res
+		*raw = b
 	}
 
 	return
@@ -141,6 +148,7 @@ func (fs *FileStorage) ContinueFile(
 	if err != nil {
 		return
 	}
+	defer f.Close()
 
 	b, err := os.ReadFile(fs.Files[where])
 	if err != nil {
@@ -160,8 +168,40 @@ func (fs *FileStorage) ContinueFile(
 
 	b = append(b, item.([]byte)...)
 
-	n, err := f.Write(b)
-	log.Println(n)
+	_, err = f.Write(b)
+
+	return
+}
+
+// Replace writes content to a temporary file & renames it over the
+// file, so readers never see it cleared or half written
+func (fs *FileStorage) Replace(
+	ctx c.Context,
+	where string,
+	content []byte,
+) (
+	err error,
+) {
+	path := fs.Files[where]
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = tmp.Write(content)
+	if err == nil {
+		err = tmp.Chmod(0644)
+	}
+	if _err := tmp.Close(); err == nil {
+		err = _err
+	}
+	if err != nil {
+		return
+	}
+
+	err = os.Rename(tmp.Name(), path)
 
 	return
 }
@@ -202,9 +242,7 @@ func (fs *FileStorage) Remove(
 
 	fs.Clear(ctx, where)
 
-	n, err := f.Write(out)
-
-	fmt.Println("written bytes ", n)
+	_, err = f.Write(out)
 
 	return
 }
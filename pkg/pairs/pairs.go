@@ -2,45 +2,43 @@ package pairs
 
 import (
 	"fmt"
+	"sort"
+	"strings"
 
 	"github.com/antonyuhnovets/flash-loan-arbitrage/internal/entities"
+	"github.com/antonyuhnovets/flash-loan-arbitrage/pkg/simulator"
 )
 
+// GetTradePairs makes every unordered combination of pools of each
+// token pair. Pairs are sorted by token pair, then by pool addresses,
+// pool with lower address goes first
 func GetTradePairs(
 	tradeMap map[entities.TokenPair]map[string]entities.Pool,
 ) (
 	tradePairs []entities.TradePair,
 	err error,
 ) {
-	for _, poolMap := range tradeMap {
-		n := len(poolMap)
-
-		switch {
-		case n < 2:
-			continue
-		case n == 2:
-			pair, _err := MakeTradePair(
-				poolMap,
-			)
-			if err != nil {
-				err = _err
-
-				return
-			}
-			tradePairs = append(
-				tradePairs,
-				pair,
-			)
-		case n > 2:
-			tradePairs = DeleteDublicates(
-				SplitPoolsOnPairs(poolMap),
-			)
-		}
+	keys := make([]entities.TokenPair, 0, len(tradeMap))
+	for key := range tradeMap {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return lessPair(keys[i], keys[j])
+	})
+
+	for _, key := range keys {
+		tradePairs = append(
+			tradePairs,
+			SplitPoolsOnPairs(tradeMap[key])...,
+		)
 	}
 
 	return
 }
 
+// GetTradeMap groups pools by token pair regardless of token order
+// and address case, pool met twice is kept once. Pairs & pools are
+// keyed by checksummed addresses, the form repository stores
 func GetTradeMap(
 	pools []entities.Pool,
 ) (
@@ -50,100 +48,98 @@ func GetTradeMap(
 	trade = make(map[entities.TokenPair]map[string]entities.Pool)
 
 	for _, pool := range pools {
-		_, ok := trade[pool.Pair]
-		if !ok {
-			trade[pool.Pair] = make(
-				map[string]entities.Pool,
-				0,
-			)
+		if pool.Address == "" {
+			err = fmt.Errorf("pool of pair %v has no address", pool.Pair)
+
+			return
+		}
+
+		key := pairKey(pool.Pair)
+		if _, ok := trade[key]; !ok {
+			trade[key] = make(map[string]entities.Pool)
 		}
-		_, ok = trade[pool.Pair][pool.Address]
-		if ok {
+
+		addr := addressKey(pool.Address)
+		if _, ok := trade[key][addr]; ok {
 			continue
 		}
-		trade[pool.Pair][pool.Address] = pool
+		trade[key][addr] = pool
 	}
 
 	return
 }
 
+// SplitPoolsOnPairs makes every unordered combination of pools,
+// sorted by pool addresses
 func SplitPoolsOnPairs(
 	pools map[string]entities.Pool,
 ) (
 	pairs []entities.TradePair,
 ) {
-	addrList := make([]string, 0)
-
-	for addr, pool := range pools {
-		addrList = append(
-			addrList,
-			addr,
-		)
-		pairs = append(
-			pairs,
-			entities.TradePair{Pool0: pool},
-		)
+	list := make([]entities.Pool, 0, len(pools))
+	for _, pool := range pools {
+		list = append(list, pool)
 	}
+	sort.Slice(list, func(i, j int) bool {
+		return lessAddress(list[i].Address, list[j].Address)
+	})
 
-	for n, pair := range pairs {
-		for i := len(addrList); i >= 0; i-- {
-			if i == n {
+	for i := range list {
+		for _, pool1 := range list[i+1:] {
+			if sameAddress(list[i].Address, pool1.Address) {
 				continue
 			}
-			pair.Pool1 = pools[addrList[i]]
+			pairs = append(pairs, entities.TradePair{
+				Pool0: list[i],
+				Pool1: pool1,
+			})
 		}
 	}
 
 	return
 }
 
+// DeleteDublicates removes pairs of pools met before in any order
 func DeleteDublicates(
 	pairs []entities.TradePair,
 ) (
 	out []entities.TradePair,
 ) {
-	out = pairs
+	seen := make(map[[2]string]bool, len(pairs))
 
-	for n, pair := range pairs {
-		if n != len(pairs) {
-			for i, p := range pairs[n+1:] {
-				if !(pair.Pool0 == p.Pool1 &&
-					pair.Pool1 == p.Pool0) ||
-					!(pair.Pool0 == p.Pool0 &&
-						pair.Pool1 == p.Pool1) {
-					continue
-				} else {
-					pairs = append(
-						out[:i-1],
-						out[i:]...,
-					)
-				}
-			}
+	for _, pair := range pairs {
+		a := addressKey(pair.Pool0.Address)
+		b := addressKey(pair.Pool1.Address)
+		if lessAddress(b, a) {
+			a, b = b, a
 		}
+		if seen[[2]string{a, b}] {
+			continue
+		}
+		seen[[2]string{a, b}] = true
+		out = append(out, pair)
 	}
 
 	return
 }
 
+// MakeTradePair pairs exactly two different pools
 func MakeTradePair(
 	pools map[string]entities.Pool,
 ) (
 	pair entities.TradePair,
 	err error,
 ) {
-	for _, pool := range pools {
-		if pair.Pool0 != pool {
-			pair.Pool0 = pool
-		} else if pair.Pool1 != pool {
-			pair.Pool1 = pool
-		} else {
-			err = fmt.Errorf(
-				"pair with same pools %v",
-				pool,
-			)
-			return
-		}
+	split := SplitPoolsOnPairs(pools)
+	if len(pools) != 2 || len(split) != 1 {
+		err = fmt.Errorf(
+			"pair needs two different pools, got %v",
+			len(pools),
+		)
+
+		return
 	}
+	pair = split[0]
 
 	return
 }
@@ -158,7 +154,7 @@ func TokenPairContain(
 	ok = false
 
 	for n, pair := range pairs {
-		if pair == searchEl {
+		if pair.Same(searchEl) {
 			ok = true
 			index = n
 
@@ -169,34 +165,30 @@ func TokenPairContain(
 	return
 }
 
+// CheckPairProtocol reports whether both pools come from factory
+// of protocol, other protocol fields may differ by source
 func CheckPairProtocol(
 	pair entities.TradePair,
 	protocol entities.SwapProtocol,
 ) (
 	ok bool,
 ) {
-	ok = false
-
-	if pair.Pool0.Protocol == protocol &&
-		pair.Pool1.Protocol == protocol {
-		ok = true
-	}
+	ok = protocol.Factory != "" &&
+		sameAddress(pair.Pool0.Protocol.Factory, protocol.Factory) &&
+		sameAddress(pair.Pool1.Protocol.Factory, protocol.Factory)
 
 	return
 }
 
+// CheckPairTokens reports whether both pools trade tokens in any order
 func CheckPairTokens(
 	pair entities.TradePair,
 	tokens entities.TokenPair,
 ) (
 	ok bool,
 ) {
-	ok = false
-
-	if pair.Pool0.Pair == tokens &&
-		pair.Pool1.Pair == tokens {
-		ok = true
-	}
+	ok = PoolContainPair(pair.Pool0, tokens) &&
+		PoolContainPair(pair.Pool1, tokens)
 
 	return
 }
@@ -207,10 +199,10 @@ func GetTokenFromPair(
 ) (
 	token *entities.Token,
 ) {
-	switch addr {
-	case pair.Token0.Address:
+	switch {
+	case sameAddress(addr, pair.Token0.Address):
 		token = &pair.Token0
-	case pair.Token1.Address:
+	case sameAddress(addr, pair.Token1.Address):
 		token = &pair.Token1
 	default:
 		token = nil
@@ -219,21 +211,14 @@ func GetTokenFromPair(
 	return
 }
 
+// PoolContainPair compares token addresses of pool & pair in any order
 func PoolContainPair(
 	pool entities.Pool,
 	pair entities.TokenPair,
 ) (
 	ok bool,
 ) {
-	if (pool.Pair.Token0 == pair.Token0 &&
-		pool.Pair.Token1 == pair.Token1) ||
-		(pool.Pair.Token0 == pair.Token1 &&
-			pool.Pair.Token1 == pair.Token0) {
-		ok = true
-
-		return
-	}
-	ok = false
+	ok = pairKey(pool.Pair) == pairKey(pair)
 
 	return
 }
@@ -253,34 +238,183 @@ func GetPoolsByPair(
 	return
 }
 
+// PairsFromTokens makes every unordered pair of different tokens,
+// token with lower address goes first, pairs are sorted
 func PairsFromTokens(tokens []entities.Token) (pairs []entities.TokenPair) {
-	m := make(
-		map[entities.Token]entities.TokenPair,
-	)
-	n := make(
-		map[entities.TokenPair]int,
-	)
-
-	for index, token := range tokens {
-		if _, ok := m[token]; !ok {
-			for _, t := range tokens {
-				if t != token {
-
-					m[token] = entities.TokenPair{
-						Token0: token,
-						Token1: t,
-					}
-
-					n[m[token]] = index
-				}
-			}
+	unique := make([]entities.Token, 0, len(tokens))
+	seen := make(map[string]bool, len(tokens))
+
+	for _, token := range tokens {
+		addr := addressKey(token.Address)
+		if addr == "" || seen[addr] {
+			continue
+		}
+		seen[addr] = true
+		unique = append(unique, token)
+	}
+	sort.Slice(unique, func(i, j int) bool {
+		return lessAddress(unique[i].Address, unique[j].Address)
+	})
+
+	for i := range unique {
+		for _, token1 := range unique[i+1:] {
+			pairs = append(pairs, entities.TokenPair{
+				Token0: unique[i],
+				Token1: token1,
+			})
+		}
+	}
+
+	return
+}
+
+// pairKey identifies token pair by checksummed addresses, lower first
+func pairKey(pair entities.TokenPair) entities.TokenPair {
+	a := addressKey(pair.Token0.Address)
+	b := addressKey(pair.Token1.Address)
+	if lessAddress(b, a) {
+		a, b = b, a
+	}
+
+	return entities.TokenPair{
+		Token0: entities.Token{Address: a},
+		Token1: entities.Token{Address: b},
+	}
+}
+
+func lessPair(a, b entities.TokenPair) bool {
+	if a.Token0.Address != b.Token0.Address {
+		return lessAddress(a.Token0.Address, b.Token0.Address)
+	}
+
+	return lessAddress(a.Token1.Address, b.Token1.Address)
+}
+
+// addressKey is checksummed address, a string which is not a hex
+// address is lower cased, so any case gives the same key
+func addressKey(addr string) string {
+	return entities.Checksum(strings.ToLower(addr))
+}
+
+func lessAddress(a, b string) bool {
+	return entities.LessAddress(a, b)
+}
+
+func sameAddress(a, b string) bool {
+	return entities.SameAddress(a, b)
+}
+
+// IsV3 reports whether pool is a uniswap-v3 pool with fee tier
+func IsV3(pool entities.Pool) bool {
+	return pool.FeeTier != 0 || pool.Protocol.Family == entities.FamilyUniV3
+}
+
+// FeeBps returns swap fee of pool in basis points: fee of pool,
+// its v3 tier, its protocol or 0.3% of uniswap-v2 if none is known
+func FeeBps(pool entities.Pool) uint32 {
+	switch {
+	case pool.FeeBps != 0:
+		return pool.FeeBps
+	case pool.FeeTier != 0:
+		return pool.FeeTier / 100
+	case pool.Protocol.FeeBps != 0:
+		return pool.Protocol.FeeBps
+	}
+
+	return simulator.ContractFeeBps
+}
+
+// Executable reports whether contract can trade the pair,
+// FlashBot only speaks IUniswapV2Pair & prices swaps at 0.3%
+func Executable(pair entities.TradePair) bool {
+	return executable(pair.Pool0) && executable(pair.Pool1)
+}
+
+// ExecutablePools keeps pools contract can trade
+func ExecutablePools(
+	pools []entities.Pool,
+) (
+	out []entities.Pool,
+) {
+	for _, pool := range pools {
+		if executable(pool) {
+			out = append(out, pool)
 		}
+	}
+
+	return
+}
 
-		continue
+// pool charging more than contract assumes reverts on K check
+func executable(pool entities.Pool) bool {
+	return !IsV3(pool) && FeeBps(pool) <= simulator.ContractFeeBps
+}
+
+// FeeMismatch is a pair contract would price with a wrong fee
+type FeeMismatch struct {
+	Pair        entities.TradePair `json:"pair"`
+	Fee0        uint32             `json:"fee0"`        // bps of pool 0
+	Fee1        uint32             `json:"fee1"`        // bps of pool 1
+	ContractFee uint32             `json:"contractFee"` // bps assumed by contract
+	Reverts     bool               `json:"reverts"`     // a pool charges more, flash swap fails
+}
+
+// FeeMismatches lists uniswap-v2 like pool pairs of the same tokens
+// where any pool fee differs from fee of contract. Pools charging
+// less are traded at contract price & leave profit on the table
+func FeeMismatches(
+	pools []entities.Pool,
+) (
+	out []FeeMismatch,
+) {
+	for i, pool0 := range pools {
+		if IsV3(pool0) {
+			continue
+		}
+
+		for _, pool1 := range pools[i+1:] {
+			if IsV3(pool1) || pool1.Address == pool0.Address ||
+				!PoolContainPair(pool1, pool0.Pair) {
+				continue
+			}
+
+			fee0, fee1 := FeeBps(pool0), FeeBps(pool1)
+			if fee0 == simulator.ContractFeeBps && fee1 == simulator.ContractFeeBps {
+				continue
+			}
+
+			out = append(out, FeeMismatch{
+				Pair:        entities.TradePair{Pool0: pool0, Pool1: pool1},
+				Fee0:        fee0,
+				Fee1:        fee1,
+				ContractFee: simulator.ContractFeeBps,
+				Reverts: fee0 > simulator.ContractFeeBps ||
+					fee1 > simulator.ContractFeeBps,
+			})
+		}
 	}
 
-	for k := range n {
-		pairs = append(pairs, k)
+	return
+}
+
+// MixedPairs pairs every v3 pool with v2 pools of the same tokens,
+// v2 pool goes first
+func MixedPairs(
+	pools []entities.Pool,
+) (
+	out []entities.TradePair,
+) {
+	for _, v3 := range pools {
+		if !IsV3(v3) {
+			continue
+		}
+
+		for _, v2 := range GetPoolsByPair(pools, v3.Pair) {
+			if IsV3(v2) || v2.Address == v3.Address {
+				continue
+			}
+			out = append(out, entities.TradePair{Pool0: v2, Pool1: v3})
+		}
 	}
 
 	return
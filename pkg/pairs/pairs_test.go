@@ -0,0 +1,143 @@
+package pairs
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/antonyuhnovets/flash-loan-arbitrage/internal/entities"
+)
+
+var testPair = entities.TokenPair{
+	Token0: entities.Token{Address: "0x0000000000000000000000000000000000000b01"},
+	Token1: entities.Token{Address: "0x0000000000000000000000000000000000000b02"},
+}
+
+func testPool(addr string, fee uint32, tier uint32) entities.Pool {
+	return entities.Pool{
+		Address:  addr,
+		Pair:     testPair,
+		Protocol: entities.SwapProtocol{FeeBps: fee},
+		FeeTier:  tier,
+	}
+}
+
+func TestFeeBps(t *testing.T) {
+	tests := []struct {
+		pool entities.Pool
+		fee  uint32
+	}{
+		{testPool("0xa1", 0, 0), 30},
+		{testPool("0xa1", 25, 0), 25},
+		{testPool("0xa1", 0, 500), 5},
+		{entities.Pool{FeeBps: 20, Protocol: entities.SwapProtocol{FeeBps: 30}}, 20},
+	}
+
+	for index, tc := range tests {
+		if fee := FeeBps(tc.pool); fee != tc.fee {
+			t.Errorf("%v: fee %v, expected %v", index, fee, tc.fee)
+		}
+	}
+}
+
+func TestExecutableFee(t *testing.T) {
+	uni := testPool("0xa1", 30, 0)
+	pancake := testPool("0xa2", 25, 0)
+	expensive := testPool("0xa3", 100, 0)
+	v3 := testPool("0xa4", 0, 3000)
+
+	if !Executable(entities.TradePair{Pool0: uni, Pool1: pancake}) {
+		t.Error("cheaper pool not executable")
+	}
+	if Executable(entities.TradePair{Pool0: uni, Pool1: expensive}) {
+		t.Error("expensive pool executable")
+	}
+
+	pools := ExecutablePools([]entities.Pool{uni, pancake, expensive, v3})
+	if len(pools) != 2 {
+		t.Errorf("executable pools %+v", pools)
+	}
+}
+
+func TestFeeMismatches(t *testing.T) {
+	pools := []entities.Pool{
+		testPool("0xa1", 30, 0),
+		testPool("0xa2", 30, 0),
+		testPool("0xa3", 25, 0),
+		testPool("0xa4", 100, 0),
+		testPool("0xa5", 0, 3000),
+	}
+
+	out := FeeMismatches(pools)
+
+	// a1-a3, a1-a4, a2-a3, a2-a4, a3-a4
+	if len(out) != 5 {
+		t.Fatalf("mismatches %+v", out)
+	}
+	for _, m := range out {
+		reverts := m.Pair.Pool0.Address == "0xa4" || m.Pair.Pool1.Address == "0xa4"
+		if m.Reverts != reverts || m.ContractFee != 30 {
+			t.Errorf("mismatch %+v", m)
+		}
+	}
+}
+
+func TestTokenPairContain(t *testing.T) {
+	pair := testPair
+	pair.Token0.Wei = entities.NewAmount(big.NewInt(1))
+
+	// same tokens with other amounts
+	search := testPair
+	search.Token0.Wei = entities.NewAmount(big.NewInt(1))
+
+	index, ok := TokenPairContain([]entities.TokenPair{pair}, search)
+	if !ok || index != 0 {
+		t.Errorf("pair not found: %v, %v", index, ok)
+	}
+}
+
+func TestCheckPairProtocol(t *testing.T) {
+	const factory = "0x5C69bEe701ef814a2B6a3EDD4B1652CB9cc5aA6f"
+
+	// pools loaded from db carry id & lower case factory
+	pool0 := testPool("0xa1", 30, 0)
+	pool0.Protocol = entities.SwapProtocol{ID: 3, Factory: strings.ToLower(factory)}
+	pool1 := testPool("0xa2", 30, 0)
+	pool1.Protocol = entities.SwapProtocol{Name: "Uniswap-V2", Factory: factory}
+	pair := entities.TradePair{Pool0: pool0, Pool1: pool1}
+
+	if !CheckPairProtocol(pair, entities.SwapProtocol{Name: "uni", Factory: factory}) {
+		t.Error("pair of the same factory rejected")
+	}
+	if CheckPairProtocol(pair, entities.SwapProtocol{Factory: testPair.Token0.Address}) {
+		t.Error("pair of other factory accepted")
+	}
+	if CheckPairProtocol(pair, entities.SwapProtocol{}) {
+		t.Error("pair accepted by protocol without factory")
+	}
+}
+
+func TestGetTradeMapChecksumKeys(t *testing.T) {
+	const pool = "0x0000000000000000000000000000000000000aBc"
+
+	lower := testPool(strings.ToLower(pool), 30, 0)
+	lower.Pair.Token0.Address = strings.ToUpper(lower.Pair.Token0.Address[2:])
+	upper := testPool(entities.Checksum(pool), 30, 0)
+
+	tradeMap, err := GetTradeMap([]entities.Pool{lower, upper})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tradeMap) != 1 {
+		t.Fatalf("pairs %v, expected one", tradeMap)
+	}
+
+	for key, pools := range tradeMap {
+		if key.Token0.Address != entities.Checksum(testPair.Token0.Address) {
+			t.Errorf("pair key %v not checksummed", key)
+		}
+		if _, ok := pools[entities.Checksum(pool)]; !ok || len(pools) != 1 {
+			t.Errorf("pools %v, expected one by checksummed address", pools)
+		}
+	}
+}
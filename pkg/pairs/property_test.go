@@ -0,0 +1,228 @@
+package pairs
+
+import (
+	"fmt"
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/quick"
+
+	"github.com/antonyuhnovets/flash-loan-arbitrage/internal/entities"
+)
+
+// poolSet is a random set of pools over few tokens, with repeated
+// pools, swapped token order & mixed address case
+type poolSet []entities.Pool
+
+func (poolSet) Generate(r *rand.Rand, size int) reflect.Value {
+	tokens := make([]entities.Token, 2+r.Intn(4))
+	for i := range tokens {
+		tokens[i] = entities.Token{Address: fmt.Sprintf("0x%040x", 0xb00+i)}
+	}
+
+	n := r.Intn(size + 1)
+	set := make(poolSet, 0, n)
+
+	for i := 0; i < n; i++ {
+		t0 := tokens[r.Intn(len(tokens))]
+		t1 := tokens[r.Intn(len(tokens))]
+		for t1 == t0 {
+			t1 = tokens[r.Intn(len(tokens))]
+		}
+
+		addr := fmt.Sprintf("0x%040x", 0xa00+r.Intn(3*len(tokens)))
+		if r.Intn(2) == 0 {
+			addr = strings.ToUpper(addr[2:])
+			addr = "0x" + addr
+		}
+
+		// the same pool address always trades the same tokens
+		if p, ok := set.find(addr); ok {
+			t0, t1 = p.Pair.Token1, p.Pair.Token0
+		}
+
+		set = append(set, entities.Pool{
+			Address: addr,
+			Pair:    entities.TokenPair{Token0: t0, Token1: t1},
+		})
+	}
+
+	return reflect.ValueOf(set)
+}
+
+func (s poolSet) find(addr string) (entities.Pool, bool) {
+	for _, p := range s {
+		if strings.EqualFold(p.Address, addr) {
+			return p, true
+		}
+	}
+
+	return entities.Pool{}, false
+}
+
+func tradePairs(t *testing.T, pools []entities.Pool) []entities.TradePair {
+	tradeMap, err := GetTradeMap(pools)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := GetTradePairs(tradeMap)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return out
+}
+
+// pools with unique addresses grouped by token pair
+func groups(pools []entities.Pool) map[entities.TokenPair]map[string]bool {
+	g := make(map[entities.TokenPair]map[string]bool)
+
+	for _, p := range pools {
+		key := pairKey(p.Pair)
+		if g[key] == nil {
+			g[key] = make(map[string]bool)
+		}
+		g[key][strings.ToLower(p.Address)] = true
+	}
+
+	return g
+}
+
+func TestPropertyEveryCombination(t *testing.T) {
+	prop := func(set poolSet) bool {
+		out := tradePairs(t, set)
+
+		want := 0
+		for _, pools := range groups(set) {
+			want += len(pools) * (len(pools) - 1) / 2
+		}
+
+		return len(out) == want
+	}
+
+	if err := quick.Check(prop, nil); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestPropertyValidPairs(t *testing.T) {
+	prop := func(set poolSet) bool {
+		seen := make(map[string]bool)
+
+		for _, pair := range tradePairs(t, set) {
+			a := strings.ToLower(pair.Pool0.Address)
+			b := strings.ToLower(pair.Pool1.Address)
+
+			// distinct pools, lower address first, no duplicates
+			if a >= b || seen[a+b] {
+				return false
+			}
+			seen[a+b] = true
+
+			// both pools trade the same tokens in any order
+			if !CheckPairTokens(pair, pair.Pool0.Pair) {
+				return false
+			}
+		}
+
+		return true
+	}
+
+	if err := quick.Check(prop, nil); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestPropertyDeterministic(t *testing.T) {
+	prop := func(set poolSet, seed int64) bool {
+		shuffled := append(poolSet(nil), set...)
+		rand.New(rand.NewSource(seed)).Shuffle(len(shuffled), func(i, j int) {
+			shuffled[i], shuffled[j] = shuffled[j], shuffled[i]
+		})
+
+		a, b := tradePairs(t, set), tradePairs(t, shuffled)
+		if len(a) != len(b) {
+			return false
+		}
+		for i := range a {
+			if !strings.EqualFold(a[i].Pool0.Address, b[i].Pool0.Address) ||
+				!strings.EqualFold(a[i].Pool1.Address, b[i].Pool1.Address) {
+				return false
+			}
+		}
+
+		return true
+	}
+
+	if err := quick.Check(prop, nil); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestPropertyDeleteDublicates(t *testing.T) {
+	prop := func(set poolSet) bool {
+		pairs := tradePairs(t, set)
+
+		// every pair again in reversed order
+		doubled := append([]entities.TradePair(nil), pairs...)
+		for _, p := range pairs {
+			doubled = append(doubled, entities.TradePair{Pool0: p.Pool1, Pool1: p.Pool0})
+		}
+
+		return reflect.DeepEqual(DeleteDublicates(doubled), pairs)
+	}
+
+	if err := quick.Check(prop, nil); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestPropertyPairsFromTokens(t *testing.T) {
+	prop := func(n uint8, dup uint8) bool {
+		count := int(n % 12)
+
+		tokens := make([]entities.Token, 0, count)
+		for i := 0; i < count; i++ {
+			tokens = append(tokens, entities.Token{Address: fmt.Sprintf("0x%040x", 0xb00+i)})
+		}
+		// repeated tokens are ignored
+		for i := 0; i < int(dup%4) && count > 0; i++ {
+			tokens = append(tokens, tokens[i%count])
+		}
+
+		pairs := PairsFromTokens(tokens)
+		if len(pairs) != count*(count-1)/2 {
+			return false
+		}
+		for i, p := range pairs {
+			if !lessAddress(p.Token0.Address, p.Token1.Address) {
+				return false
+			}
+			if i > 0 && !lessPair(pairKey(pairs[i-1]), pairKey(p)) {
+				return false
+			}
+		}
+
+		return true
+	}
+
+	if err := quick.Check(prop, nil); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestMakeTradePair(t *testing.T) {
+	pool0 := entities.Pool{Address: "0xa2", Pair: testPair}
+	pool1 := entities.Pool{Address: "0xA1", Pair: testPair}
+
+	pair, err := MakeTradePair(map[string]entities.Pool{"a": pool0, "b": pool1})
+	if err != nil || pair.Pool0 != pool1 || pair.Pool1 != pool0 {
+		t.Errorf("pair %+v %v", pair, err)
+	}
+
+	_, err = MakeTradePair(map[string]entities.Pool{"a": pool0})
+	if err == nil {
+		t.Error("pair of one pool")
+	}
+}
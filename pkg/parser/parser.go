@@ -8,11 +8,15 @@ import (
 )
 
 type Parser struct {
-	Pools []entities.Pool
+	Pools    []entities.Pool
+	Rejected []entities.PoolRejection
 }
 
 func New() *Parser {
-	return &Parser{make([]entities.Pool, 0)}
+	return &Parser{
+		Pools:    make([]entities.Pool, 0),
+		Rejected: make([]entities.PoolRejection, 0),
+	}
 }
 
 func (p *Parser) AddPool(pool entities.Pool) error {
@@ -36,6 +40,19 @@ func (p *Parser) RemovePool(pool entities.Pool) error {
 
 func (p *Parser) Clear() {
 	p.Pools = make([]entities.Pool, 0)
+	p.Rejected = make([]entities.PoolRejection, 0)
+}
+
+// Reject removes pool failed validation & keeps it with reason
+func (p *Parser) Reject(r entities.PoolRejection) {
+	if index, ok := p.containPool(r.Pool); ok {
+		p.Pools = append(p.Pools[:index], p.Pools[index+1:]...)
+	}
+	p.Rejected = append(p.Rejected, r)
+}
+
+func (p *Parser) ListRejected() []entities.PoolRejection {
+	return p.Rejected
 }
 
 func (p *Parser) GetPairPools(
@@ -84,7 +101,7 @@ func (p *Parser) containPool(pool entities.Pool) (
 	ok bool,
 ) {
 	for n, pl := range p.Pools {
-		if pl == pool {
+		if pl.Same(pool) {
 			index = n
 			ok = true
 
@@ -103,7 +120,7 @@ func (p *Parser) containPair(
 	ok bool,
 ) {
 	for n, p := range p.Pools {
-		if p.Pair == pair {
+		if p.Pair.Same(pair) {
 			index = n
 			ok = true
 
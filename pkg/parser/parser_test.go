@@ -0,0 +1,57 @@
+package parser
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/antonyuhnovets/flash-loan-arbitrage/internal/entities"
+)
+
+const testPoolJSON = `{
+	"address": "0xB4e16d0168e52d35CaCD2c6185b44281Ec28C9Dc",
+	"pair": {
+		"token0": {"address": "0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48", "wei": "1000"},
+		"token1": {"address": "0xC02aaA39b223FE8D0A0e5C4F27eAD9083C756Cc2", "wei": "2000"}
+	}
+}`
+
+func decodeTestPool(t *testing.T, s string) (
+	pool entities.Pool,
+) {
+	if err := json.Unmarshal([]byte(s), &pool); err != nil {
+		t.Fatal(err)
+	}
+
+	return
+}
+
+func TestParserDeduplicatesDecodedPools(t *testing.T) {
+	p := New()
+
+	// amounts are decoded to separate pointers
+	pool := decodeTestPool(t, testPoolJSON)
+	same := decodeTestPool(t, strings.ToLower(testPoolJSON))
+
+	if err := p.AddPool(pool); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.AddPool(same); err == nil {
+		t.Error("equal pool added twice")
+	}
+	if len(p.ListPools()) != 1 {
+		t.Fatalf("pools %+v", p.ListPools())
+	}
+
+	pools, err := p.GetPairPools(same.Pair)
+	if err != nil || len(pools) != 1 {
+		t.Errorf("pools of pair %+v, %v", pools, err)
+	}
+
+	if err = p.RemovePool(same); err != nil {
+		t.Error(err)
+	}
+	if len(p.ListPools()) != 0 {
+		t.Errorf("pool not removed %+v", p.ListPools())
+	}
+}
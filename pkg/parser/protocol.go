@@ -2,73 +2,93 @@ package parser
 
 import (
 	"fmt"
-	"math/big"
-
-	uni "github.com/ackermanx/ethclient/uniswap"
-	sushi "github.com/ebadiere/go-defi/sushiswap"
+	"sync"
 
 	"github.com/antonyuhnovets/flash-loan-arbitrage/internal/entities"
-	"github.com/antonyuhnovets/flash-loan-arbitrage/pkg/ethereum"
 )
 
+// ProtocolManager keeps parsers of protocols, protocols are added &
+// removed over api while pools are parsed
 type ProtocolManager struct {
-	p []*Protocol
+	mu sync.RWMutex
+	p  []*Protocol
 	ProtocolResolver
 }
 
 func NewManager(pr ProtocolResolver) *ProtocolManager {
 	return &ProtocolManager{
-		make([]*Protocol, 0),
-		pr,
+		p:                make([]*Protocol, 0),
+		ProtocolResolver: pr,
 	}
 }
 
+// AddProtocol builds parser of protocol, unset family is uniswap-v2
+// and unset fee of uniswap-v2 family is 0.3%
 func (pm *ProtocolManager) AddProtocol(sp entities.SwapProtocol) (
 	err error,
 ) {
+	sp = sp.Normalize()
+	if sp.Family == "" {
+		sp.Family = entities.FamilyUniV2
+	}
+	if sp.Family == entities.FamilyUniV2 && sp.FeeBps == 0 {
+		sp.FeeBps = DefaultV2FeeBps
+	}
+
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+
 	for _, proto := range pm.p {
-		if proto.SwapProtocol == sp {
+		if proto.is(sp) {
 			err = fmt.Errorf("protocol %s already added", sp.Name)
 
 			return
 		}
 	}
-	pm.p = append(pm.p, NewProtocol(sp))
+
+	p := NewProtocol(sp)
+	p.parser, err = pm.ProtocolResolver.Resolve(p)
+	if err != nil {
+		return
+	}
+	pm.p = append(pm.p, p)
 
 	return
 }
 
+// RemoveProtocol removes protocol of the same name or factory
 func (pm *ProtocolManager) RemoveProtocol(sp entities.SwapProtocol) (
 	err error,
 ) {
-	err = fmt.Errorf("protocol not found")
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
 
-	for index, proto := range pm.ListProtocols() {
-		if proto == sp {
+	for index, proto := range pm.p {
+		if proto.is(sp) {
 			pm.p = append(pm.p[:index], pm.p[index+1:]...)
-			err = nil
+
+			return
 		}
 	}
+	err = fmt.Errorf("protocol not found")
+
 	return
 }
 
+// GetPoolAddresses computes pools of pair for every protocol,
+// protocols with fee tiers give a pool per tier. Pools get the
+// pair checksummed & in token0 < token1 order
 func (pm *ProtocolManager) GetPoolAddresses(pair entities.TokenPair) (
-	out map[entities.SwapProtocol]string,
+	out []entities.Pool,
 	err error,
 ) {
-	out = make(map[entities.SwapProtocol]string)
-
-	for _, proto := range pm.p {
-		parser, _err := pm.ProtocolResolver.Resolve(proto)
-		if err != nil {
-			err = _err
-			return
-
-		}
+	pair = pair.Normalize()
 
+	for _, proto := range pm.protocols() {
+		parser := proto.parser
 		if parser == nil {
-			err = fmt.Errorf("parser is nil")
-			fmt.Println(err)
+			err = fmt.Errorf("protocol %s has no parser", proto.Name)
+
 			return
 		}
 
@@ -78,14 +98,44 @@ func (pm *ProtocolManager) GetPoolAddresses(pair entities.TokenPair) (
 			return
 		}
 
+		if tiered, ok := parser.(TieredParser); ok {
+			tiers, _err := tiered.GetPoolAddresses(pair)
+			if _err != nil {
+				err = _err
+
+				return
+			}
+
+			for _, fee := range V3FeeTiers {
+				addr, ok := tiers[fee]
+				if !ok {
+					continue
+				}
+				out = append(out, entities.Pool{
+					Address:  addr,
+					Pair:     pair,
+					Protocol: proto.GetProtocolData(),
+					FeeTier:  fee,
+					FeeBps:   fee / 100,
+				})
+			}
+
+			continue
+		}
+
 		address, _err := parser.GetPoolAddress(pair)
-		if err != nil {
+		if _err != nil {
 			err = _err
 
 			return
 		}
 
-		out[proto.GetProtocolData()] = address
+		out = append(out, entities.Pool{
+			Address:  address,
+			Pair:     pair,
+			Protocol: proto.GetProtocolData(),
+			FeeBps:   proto.FeeBps,
+		})
 	}
 
 	return
@@ -94,7 +144,7 @@ func (pm *ProtocolManager) GetPoolAddresses(pair entities.TokenPair) (
 func (pm *ProtocolManager) ListProtocols() (
 	out []entities.SwapProtocol,
 ) {
-	for _, proto := range pm.p {
+	for _, proto := range pm.protocols() {
 		p := proto.GetProtocolData()
 		out = append(out, p)
 	}
@@ -102,14 +152,24 @@ func (pm *ProtocolManager) ListProtocols() (
 	return
 }
 
+// protocols returns copy of protocol list, parsers may call chain
+// so the lock isn't held while they run
+func (pm *ProtocolManager) protocols() []*Protocol {
+	pm.mu.RLock()
+	defer pm.mu.RUnlock()
+
+	return append([]*Protocol(nil), pm.p...)
+}
+
 type Protocol struct {
 	entities.SwapProtocol
+	parser ProtocolParser
 }
 
 func NewProtocol(sp entities.SwapProtocol) (
 	p *Protocol,
 ) {
-	p = &Protocol{sp}
+	p = &Protocol{SwapProtocol: sp}
 
 	return
 }
@@ -128,66 +188,16 @@ type ProtocolParser interface {
 	GetPoolAddress(entities.TokenPair) (string, error)
 }
 
-type UniV2 Protocol
-
-func (u2 *UniV2) GetPoolAddress(
-	pair entities.TokenPair,
-) (
-	address string,
-	err error,
-) {
-	pAddr, err := uni.CalculatePoolAddressV2(
-		pair.Token0.Address,
-		pair.Token1.Address,
-	)
-	if err != nil {
-		return
-	}
-
-	address = pAddr.Hex()
-
-	return
+// TieredParser resolves a pool of pair per fee tier
+type TieredParser interface {
+	GetPoolAddresses(entities.TokenPair) (map[uint32]string, error)
 }
 
-type UniV3 Protocol
+// V3FeeTiers are fee tiers enabled by uniswap-v3 factory,
+// in hundredths of a bip: 0.01%, 0.05%, 0.3% & 1%
+var V3FeeTiers = []uint32{100, 500, 3000, 10000}
 
-func (u3 *UniV3) GetPoolAddress(
-	pair entities.TokenPair,
-) (
-	address string,
-	err error,
-) {
-	pAddr, err := uni.CalculatePoolAddressV3(
-		pair.Token0.Address,
-		pair.Token1.Address,
-		big.NewInt(3000),
-	)
-	if err != nil {
-		return
-	}
-
-	address = pAddr.Hex()
-
-	return
-}
-
-type SushiV2 Protocol
-
-func (s2 *SushiV2) GetPoolAddress(
-	pair entities.TokenPair,
-) (
-	address string,
-	err error,
-) {
-	sushi.FactoryAddress = ethereum.ToAddress(s2.Factory)
-	sushi.Router02Address = ethereum.ToAddress(s2.SwapRouter)
-
-	pAddr := sushi.GeneratePairAddress(
-		ethereum.ToAddress(pair.Token0.Address),
-		ethereum.ToAddress(pair.Token1.Address),
-	)
-
-	address = pAddr.Hex()
-
-	return
+// is reports whether sp names protocol, by name or factory
+func (pro *Protocol) is(sp entities.SwapProtocol) bool {
+	return sp.Same(pro.SwapProtocol)
 }
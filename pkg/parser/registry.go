@@ -0,0 +1,239 @@
+package parser
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/antonyuhnovets/flash-loan-arbitrage/internal/entities"
+)
+
+// DefaultV2FeeBps is swap fee of uniswap-v2 pairs, 0.3%
+const DefaultV2FeeBps = 30
+
+// Builder creates parser of protocol from its data
+type Builder func(entities.SwapProtocol) (ProtocolParser, error)
+
+// Registry resolves protocols to parsers by AMM family,
+// so a fork of known family is added from data only
+type Registry struct {
+	mu       sync.RWMutex
+	builders map[string]Builder
+}
+
+// NewRegistry returns registry of uniswap-v2 & v3 families
+func NewRegistry() *Registry {
+	r := &Registry{
+		builders: make(map[string]Builder),
+	}
+	r.Register(entities.FamilyUniV2, NewV2Parser)
+	r.Register(entities.FamilyUniV3, NewV3Parser)
+
+	return r
+}
+
+// Register sets builder of family
+func (r *Registry) Register(family string, b Builder) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.builders[family] = b
+}
+
+// Resolve builds a new parser for protocol, parsers of
+// different protocols share nothing
+func (r *Registry) Resolve(p *Protocol) (
+	pp ProtocolParser,
+	err error,
+) {
+	r.mu.RLock()
+	b, ok := r.builders[p.Family]
+	r.mu.RUnlock()
+
+	if !ok {
+		err = fmt.Errorf("protocol %s: unknown family %q", p.Name, p.Family)
+
+		return
+	}
+
+	pp, err = b(p.GetProtocolData())
+	if err != nil {
+		err = fmt.Errorf("protocol %s: %w", p.Name, err)
+	}
+
+	return
+}
+
+// V2Parser computes addresses of uniswap-v2 pairs by CREATE2
+type V2Parser struct {
+	factory      common.Address
+	initCodeHash common.Hash
+}
+
+func NewV2Parser(sp entities.SwapProtocol) (
+	pp ProtocolParser,
+	err error,
+) {
+	factory, hash, err := create2Data(sp)
+	if err != nil {
+		return
+	}
+	if sp.FeeBps >= 10000 {
+		err = fmt.Errorf("invalid fee %v bps", sp.FeeBps)
+
+		return
+	}
+	pp = &V2Parser{factory, hash}
+
+	return
+}
+
+func (v2 *V2Parser) GetPoolAddress(
+	pair entities.TokenPair,
+) (
+	address string,
+	err error,
+) {
+	t0, t1, err := sortTokens(pair)
+	if err != nil {
+		return
+	}
+
+	salt := crypto.Keccak256Hash(t0.Bytes(), t1.Bytes())
+	address = crypto.CreateAddress2(
+		v2.factory, salt, v2.initCodeHash.Bytes(),
+	).Hex()
+
+	return
+}
+
+// V3Parser computes addresses of uniswap-v3 pools by CREATE2
+type V3Parser struct {
+	factory      common.Address
+	initCodeHash common.Hash
+}
+
+func NewV3Parser(sp entities.SwapProtocol) (
+	pp ProtocolParser,
+	err error,
+) {
+	factory, hash, err := create2Data(sp)
+	if err != nil {
+		return
+	}
+	pp = &V3Parser{factory, hash}
+
+	return
+}
+
+// GetPoolAddress returns pool of the 0.3% fee tier
+func (v3 *V3Parser) GetPoolAddress(
+	pair entities.TokenPair,
+) (
+	address string,
+	err error,
+) {
+	address, err = v3.poolAddress(pair, 3000)
+
+	return
+}
+
+// GetPoolAddresses returns pools of pair for every fee tier
+func (v3 *V3Parser) GetPoolAddresses(
+	pair entities.TokenPair,
+) (
+	out map[uint32]string,
+	err error,
+) {
+	out = make(map[uint32]string, len(V3FeeTiers))
+
+	for _, fee := range V3FeeTiers {
+		address, _err := v3.poolAddress(pair, fee)
+		if _err != nil {
+			err = _err
+
+			return
+		}
+		out[fee] = address
+	}
+
+	return
+}
+
+func (v3 *V3Parser) poolAddress(
+	pair entities.TokenPair,
+	fee uint32,
+) (
+	address string,
+	err error,
+) {
+	t0, t1, err := sortTokens(pair)
+	if err != nil {
+		return
+	}
+
+	// keccak256(abi.encode(token0, token1, fee))
+	salt := crypto.Keccak256Hash(
+		common.LeftPadBytes(t0.Bytes(), 32),
+		common.LeftPadBytes(t1.Bytes(), 32),
+		common.LeftPadBytes(big.NewInt(int64(fee)).Bytes(), 32),
+	)
+	address = crypto.CreateAddress2(
+		v3.factory, salt, v3.initCodeHash.Bytes(),
+	).Hex()
+
+	return
+}
+
+func create2Data(sp entities.SwapProtocol) (
+	factory common.Address,
+	hash common.Hash,
+	err error,
+) {
+	if !common.IsHexAddress(sp.Factory) {
+		err = fmt.Errorf("invalid factory address %q", sp.Factory)
+
+		return
+	}
+
+	code := common.FromHex(sp.InitCodeHash)
+	if len(code) != common.HashLength {
+		err = fmt.Errorf("invalid init code hash %q", sp.InitCodeHash)
+
+		return
+	}
+
+	factory = common.HexToAddress(sp.Factory)
+	hash = common.BytesToHash(code)
+
+	return
+}
+
+func sortTokens(pair entities.TokenPair) (
+	t0, t1 common.Address,
+	err error,
+) {
+	if !common.IsHexAddress(pair.Token0.Address) ||
+		!common.IsHexAddress(pair.Token1.Address) {
+		err = fmt.Errorf("invalid pair token address")
+
+		return
+	}
+
+	t0 = common.HexToAddress(pair.Token0.Address)
+	t1 = common.HexToAddress(pair.Token1.Address)
+	if t0 == t1 {
+		err = fmt.Errorf("identical pair tokens %s", t0)
+
+		return
+	}
+	if bytes.Compare(t1.Bytes(), t0.Bytes()) < 0 {
+		t0, t1 = t1, t0
+	}
+
+	return
+}
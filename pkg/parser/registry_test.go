@@ -0,0 +1,197 @@
+package parser
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/antonyuhnovets/flash-loan-arbitrage/internal/entities"
+)
+
+const (
+	testWETH = "0xC02aaA39b223FE8D0A0e5C4F27eAD9083C756Cc2"
+	testUSDC = "0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48"
+)
+
+var testPair = entities.TokenPair{
+	Token0: entities.Token{Address: testWETH},
+	Token1: entities.Token{Address: testUSDC},
+}
+
+func TestRegistryPoolAddresses(t *testing.T) {
+	pm := NewManager(NewRegistry())
+
+	for _, sp := range []entities.SwapProtocol{
+		{
+			Name:         "Uniswap-V2",
+			Factory:      "0x5C69bEe701ef814a2B6a3EDD4B1652CB9cc5aA6f",
+			InitCodeHash: "0x96e8ac4277198ff8b6f785478aa9a39f403cb768dd02cbee326c3e7da348845f",
+		},
+		{
+			Name:         "Sushiswap-V2",
+			Factory:      "0xC0AEe478e3658e2610c5F7A4A2E1777cE9e4f2Ac",
+			InitCodeHash: "0xe18a34eb0e04b04f7a0ac29a6e80748dca96319b42c54d679cb821dca90c6303",
+			Family:       entities.FamilyUniV2,
+		},
+		{
+			Name:         "Uniswap-V3",
+			Factory:      "0x1F98431c8aD98523631AE4a59f267346ea31F984",
+			InitCodeHash: "0xe34f199b19b2b4f47f68442619d555527d244f78a3297ea89325f843f87b8b54",
+			Family:       entities.FamilyUniV3,
+		},
+	} {
+		if err := pm.AddProtocol(sp); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	pools, err := pm.GetPoolAddresses(testPair)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]bool{
+		"0xB4e16d0168e52d35CaCD2c6185b44281Ec28C9Dc": false, // uniswap-v2
+		"0x397FF1542f962076d0BFE58eA045FfA2d347ACa0": false, // sushiswap
+		"0x88e6A0c2dDD26FEEb64F039a2c41296FcB3f5640": false, // uniswap-v3 0.05%
+		"0x8ad599c3A0ff1De082011EFDDc58f1908eb6e6D8": false, // uniswap-v3 0.3%
+	}
+	for _, pool := range pools {
+		if _, ok := want[pool.Address]; ok {
+			want[pool.Address] = true
+		}
+	}
+	for addr, found := range want {
+		if !found {
+			t.Errorf("pool %s not resolved", addr)
+		}
+	}
+
+	if len(pools) != 2+len(V3FeeTiers) {
+		t.Errorf("%v pools", len(pools))
+	}
+	if pools[0].Protocol.FeeBps != DefaultV2FeeBps {
+		t.Errorf("default fee %v", pools[0].Protocol.FeeBps)
+	}
+}
+
+func TestRegistryIsolated(t *testing.T) {
+	pm := NewManager(NewRegistry())
+
+	// same family, different factories resolve different pairs
+	for _, sp := range []entities.SwapProtocol{
+		{
+			Name:         "Uniswap-V2",
+			Factory:      "0x5C69bEe701ef814a2B6a3EDD4B1652CB9cc5aA6f",
+			InitCodeHash: "0x96e8ac4277198ff8b6f785478aa9a39f403cb768dd02cbee326c3e7da348845f",
+		},
+		{
+			Name:         "Fork",
+			Factory:      "0x0000000000000000000000000000000000000f01",
+			InitCodeHash: "0x96e8ac4277198ff8b6f785478aa9a39f403cb768dd02cbee326c3e7da348845f",
+		},
+	} {
+		if err := pm.AddProtocol(sp); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	pools, err := pm.GetPoolAddresses(testPair)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pools) != 2 || pools[0].Address == pools[1].Address {
+		t.Errorf("pools %+v", pools)
+	}
+	if pools[0].Address != "0xB4e16d0168e52d35CaCD2c6185b44281Ec28C9Dc" {
+		t.Errorf("uniswap-v2 pair %s", pools[0].Address)
+	}
+}
+
+func TestRegistryRejects(t *testing.T) {
+	pm := NewManager(NewRegistry())
+
+	valid := entities.SwapProtocol{
+		Name:         "Uniswap-V2",
+		Factory:      "0x5C69bEe701ef814a2B6a3EDD4B1652CB9cc5aA6f",
+		InitCodeHash: "0x96e8ac4277198ff8b6f785478aa9a39f403cb768dd02cbee326c3e7da348845f",
+	}
+
+	for name, sp := range map[string]entities.SwapProtocol{
+		"factory": {Name: "a", Factory: "0x01", InitCodeHash: valid.InitCodeHash},
+		"hash":    {Name: "b", Factory: valid.Factory, InitCodeHash: "0x1234"},
+		"family":  {Name: "c", Factory: valid.Factory, InitCodeHash: valid.InitCodeHash, Family: "curve"},
+		"fee":     {Name: "d", Factory: valid.Factory, InitCodeHash: valid.InitCodeHash, FeeBps: 10000},
+	} {
+		if err := pm.AddProtocol(sp); err == nil {
+			t.Errorf("invalid %s accepted", name)
+		}
+	}
+
+	if err := pm.AddProtocol(valid); err != nil {
+		t.Fatal(err)
+	}
+	if err := pm.AddProtocol(valid); err == nil {
+		t.Error("duplicate accepted")
+	}
+	if len(pm.ListProtocols()) != 1 {
+		t.Errorf("protocols %+v", pm.ListProtocols())
+	}
+
+	if err := pm.RemoveProtocol(entities.SwapProtocol{Name: valid.Name}); err != nil {
+		t.Fatal(err)
+	}
+	if len(pm.ListProtocols()) != 0 {
+		t.Errorf("protocols %+v", pm.ListProtocols())
+	}
+}
+
+func TestManagerConcurrent(t *testing.T) {
+	pm := NewManager(NewRegistry())
+
+	const n = 20
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	// protocols are added & removed over api while pools are parsed
+	go func() {
+		defer wg.Done()
+
+		for i := 0; i < n; i++ {
+			sp := entities.SwapProtocol{
+				Name:         fmt.Sprintf("Fork-%v", i),
+				Factory:      fmt.Sprintf("0x%040x", i+1),
+				InitCodeHash: "0x96e8ac4277198ff8b6f785478aa9a39f403cb768dd02cbee326c3e7da348845f",
+			}
+			if err := pm.AddProtocol(sp); err != nil {
+				t.Error(err)
+
+				return
+			}
+			if i%2 == 0 {
+				if err := pm.RemoveProtocol(sp); err != nil {
+					t.Error(err)
+
+					return
+				}
+			}
+		}
+	}()
+	go func() {
+		defer wg.Done()
+
+		for i := 0; i < n; i++ {
+			if _, err := pm.GetPoolAddresses(testPair); err != nil {
+				t.Error(err)
+
+				return
+			}
+			pm.ListProtocols()
+		}
+	}()
+	wg.Wait()
+
+	if got := len(pm.ListProtocols()); got != n/2 {
+		t.Errorf("%v protocols, expected %v", got, n/2)
+	}
+}
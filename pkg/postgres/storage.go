@@ -15,7 +15,12 @@ type Storage struct {
 }
 
 func Connect(dsn string, i ...interface{}) (*Storage, error) {
-	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{
+	return Open(postgres.Open(dsn), i...)
+}
+
+// Open opens storage of dialector & migrates tables of items
+func Open(dialector gorm.Dialector, i ...interface{}) (*Storage, error) {
+	db, err := gorm.Open(dialector, &gorm.Config{
 		Logger: logger.Default,
 		// DisableForeignKeyConstraintWhenMigrating: true,
 		// FullSaveAssociations:                     true,
@@ -33,6 +38,27 @@ func Connect(dsn string, i ...interface{}) (*Storage, error) {
 	return &Storage{db}, nil
 }
 
+// Transaction runs fn on storage of a db transaction, it's committed
+// if fn returns nil & rolled back otherwise
+func (ps *Storage) Transaction(ctx c.Context, fn func(*Storage) error) (
+	err error,
+) {
+	err = ps.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(&Storage{tx})
+	})
+
+	return
+}
+
+// Migrate creates or updates table of item under its own name
+func (ps *Storage) Migrate(where string, item interface{}) (
+	err error,
+) {
+	err = ps.db.Table(where).AutoMigrate(item)
+
+	return
+}
+
 func (ps *Storage) Store(ctx c.Context, where string, item interface{}) (
 	err error,
 ) {
@@ -45,6 +71,15 @@ func (ps *Storage) Store(ctx c.Context, where string, item interface{}) (
 	return
 }
 
+// Update saves item with all fields, item is created if it has no primary key
+func (ps *Storage) Update(ctx c.Context, where string, item interface{}) (
+	err error,
+) {
+	err = ps.db.Table(where).WithContext(ctx).Save(item).Error
+
+	return
+}
+
 func (ps *Storage) Read(ctx c.Context, where string, items interface{}) (
 	err error,
 ) {
@@ -53,6 +88,36 @@ func (ps *Storage) Read(ctx c.Context, where string, items interface{}) (
 	return
 }
 
+// ReadPreloaded reads items with associations of preload fields
+func (ps *Storage) ReadPreloaded(
+	ctx c.Context, where string, items interface{}, preload ...string,
+) (
+	err error,
+) {
+	db := ps.db.Table(where).WithContext(ctx)
+	for _, field := range preload {
+		db = db.Preload(field)
+	}
+
+	err = db.Find(items).Error
+
+	return
+}
+
+// UpdateWhere sets columns of rows matching query
+func (ps *Storage) UpdateWhere(
+	ctx c.Context,
+	where string,
+	columns map[string]interface{},
+	query string, args ...interface{},
+) (
+	err error,
+) {
+	err = ps.db.Table(where).WithContext(ctx).Where(query, args...).Updates(columns).Error
+
+	return
+}
+
 func (ps *Storage) Remove(ctx c.Context, where string, item interface{}) (
 	err error,
 ) {
@@ -0,0 +1,231 @@
+package reserves
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+
+	"github.com/antonyuhnovets/flash-loan-arbitrage/internal/entities"
+	"github.com/antonyuhnovets/flash-loan-arbitrage/pkg/pairs"
+	"github.com/antonyuhnovets/flash-loan-arbitrage/pkg/simulator"
+)
+
+// DefaultBatchSize is max number of eth_call in one json-rpc batch
+const DefaultBatchSize = 300
+
+var (
+	getReservesSelector = selector("getReserves()")
+	token0Selector      = selector("token0()")
+	token1Selector      = selector("token1()")
+)
+
+// Caller is json-rpc client able to send batch requests
+type Caller interface {
+	CallContext(
+		ctx context.Context, result interface{}, method string, args ...interface{},
+	) error
+
+	BatchCallContext(
+		ctx context.Context, b []rpc.BatchElem,
+	) error
+}
+
+type Loader struct {
+	rpc       Caller
+	batchSize int
+}
+
+func NewLoader(cl Caller, batchSize int) *Loader {
+	if batchSize <= 0 {
+		batchSize = DefaultBatchSize
+	}
+
+	return &Loader{
+		rpc:       cl,
+		batchSize: batchSize,
+	}
+}
+
+// Snapshot is a block consistent state of pools
+type Snapshot struct {
+	Block    uint64
+	Reserves map[common.Address]simulator.Reserves
+	Failed   map[common.Address]error
+}
+
+func (s *Snapshot) Get(pool string) (
+	res simulator.Reserves,
+	ok bool,
+) {
+	res, ok = s.Reserves[common.HexToAddress(pool)]
+
+	return
+}
+
+func (s *Snapshot) List() (
+	out []simulator.Reserves,
+) {
+	for _, r := range s.Reserves {
+		out = append(out, r)
+	}
+
+	return
+}
+
+// BlockNumber returns latest block number
+func (l *Loader) BlockNumber(ctx context.Context) (
+	block uint64,
+	err error,
+) {
+	var res hexutil.Uint64
+
+	err = l.rpc.CallContext(ctx, &res, "eth_blockNumber")
+	if err != nil {
+		return
+	}
+	block = uint64(res)
+
+	return
+}
+
+// Load fetches reserves & tokens of pools pinned to block,
+// latest block is used if block is nil
+func (l *Loader) Load(
+	ctx context.Context,
+	pools []entities.Pool,
+	block *big.Int,
+) (
+	snap *Snapshot,
+	err error,
+) {
+	var number uint64
+
+	if block == nil {
+		number, err = l.BlockNumber(ctx)
+		if err != nil {
+			return
+		}
+	} else {
+		number = block.Uint64()
+	}
+
+	snap = &Snapshot{
+		Block:    number,
+		Reserves: make(map[common.Address]simulator.Reserves),
+		Failed:   make(map[common.Address]error),
+	}
+
+	addrs := uniqueAddresses(pools)
+	blockArg := hexutil.EncodeUint64(number)
+
+	fees := make(map[common.Address]uint32, len(pools))
+	for _, pool := range pools {
+		fees[common.HexToAddress(pool.Address)] = pairs.FeeBps(pool)
+	}
+
+	calls := make([]rpc.BatchElem, 0, len(addrs)*3)
+	results := make([]hexutil.Bytes, len(addrs)*3)
+
+	for n, addr := range addrs {
+		for i, sel := range [][]byte{getReservesSelector, token0Selector, token1Selector} {
+			calls = append(calls, rpc.BatchElem{
+				Method: "eth_call",
+				Args:   []interface{}{callArg(addr, sel), blockArg},
+				Result: &results[n*3+i],
+			})
+		}
+	}
+
+	err = l.batch(ctx, calls)
+	if err != nil {
+		return
+	}
+
+	for n, addr := range addrs {
+		res, _err := decodePool(
+			addr,
+			calls[n*3:n*3+3],
+			results[n*3:n*3+3],
+		)
+		if _err != nil {
+			snap.Failed[addr] = _err
+
+			continue
+		}
+		res.FeeBps = fees[addr]
+		snap.Reserves[addr] = res
+	}
+
+	return
+}
+
+func decodePool(
+	addr common.Address,
+	calls []rpc.BatchElem,
+	results []hexutil.Bytes,
+) (
+	res simulator.Reserves,
+	err error,
+) {
+	for _, call := range calls {
+		if call.Error != nil {
+			err = call.Error
+
+			return
+		}
+	}
+
+	if len(results[0]) < 64 {
+		err = fmt.Errorf("invalid getReserves output %s", results[0])
+
+		return
+	}
+	if len(results[1]) < 32 || len(results[2]) < 32 {
+		err = fmt.Errorf("invalid token output")
+
+		return
+	}
+
+	res = simulator.Reserves{
+		Pool:     addr.Hex(),
+		Token0:   common.BytesToAddress(results[1][:32]).Hex(),
+		Token1:   common.BytesToAddress(results[2][:32]).Hex(),
+		Reserve0: new(big.Int).SetBytes(results[0][:32]),
+		Reserve1: new(big.Int).SetBytes(results[0][32:64]),
+	}
+
+	return
+}
+
+func callArg(to common.Address, data []byte) map[string]interface{} {
+	return map[string]interface{}{
+		"to":   to,
+		"data": hexutil.Bytes(data),
+	}
+}
+
+func uniqueAddresses(pools []entities.Pool) (
+	out []common.Address,
+) {
+	seen := make(map[common.Address]bool)
+
+	for _, pool := range pools {
+		addr := common.HexToAddress(pool.Address)
+		if seen[addr] {
+			continue
+		}
+		seen[addr] = true
+		out = append(out, addr)
+	}
+
+	return
+}
+
+func selector(signature string) []byte {
+	return crypto.Keccak256([]byte(signature))[:4]
+}
@@ -0,0 +1,183 @@
+package reserves
+
+import (
+	"bytes"
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+
+	"github.com/antonyuhnovets/flash-loan-arbitrage/internal/entities"
+	"github.com/antonyuhnovets/flash-loan-arbitrage/internal/rpcstub"
+)
+
+type stubPool struct {
+	token0   common.Address
+	token1   common.Address
+	reserve0 *big.Int
+	reserve1 *big.Int
+}
+
+// stubNode answers eth_blockNumber & eth_call of uniswap-v2 pair methods
+type stubNode struct {
+	*rpcstub.Node
+	block  uint64
+	pools  map[common.Address]stubPool
+	blocks map[string]int
+}
+
+func (s *stubNode) handle(req rpcstub.Request) (
+	res interface{},
+	err *rpcstub.Error,
+) {
+	switch req.Method {
+	case "eth_blockNumber":
+		res = hexutil.EncodeUint64(s.block)
+	case "eth_call":
+		var call struct {
+			To   common.Address `json:"to"`
+			Data hexutil.Bytes  `json:"data"`
+		}
+		var block string
+		req.Param(0, &call)
+		req.Param(1, &block)
+		s.blocks[block]++
+
+		pool, ok := s.pools[call.To]
+		if !ok {
+			err = rpcstub.ErrReverted
+
+			return
+		}
+
+		switch {
+		case bytes.Equal(call.Data, getReservesSelector):
+			out := append(
+				common.LeftPadBytes(pool.reserve0.Bytes(), 32),
+				common.LeftPadBytes(pool.reserve1.Bytes(), 32)...,
+			)
+			out = append(out, make([]byte, 32)...)
+			res = hexutil.Bytes(out)
+		case bytes.Equal(call.Data, token0Selector):
+			res = hexutil.Bytes(common.LeftPadBytes(pool.token0.Bytes(), 32))
+		case bytes.Equal(call.Data, token1Selector):
+			res = hexutil.Bytes(common.LeftPadBytes(pool.token1.Bytes(), 32))
+		}
+	case "eth_getCode":
+		var addr common.Address
+		req.Param(0, &addr)
+
+		res = "0x"
+		if _, ok := s.pools[addr]; ok {
+			res = "0x6080"
+		}
+	default:
+		err = rpcstub.ErrNotFound
+	}
+
+	return
+}
+
+var (
+	testToken0 = common.HexToAddress("0x0000000000000000000000000000000000000b01")
+	testToken1 = common.HexToAddress("0x0000000000000000000000000000000000000b02")
+	testPools  = map[common.Address]stubPool{
+		common.HexToAddress("0x0000000000000000000000000000000000000a01"): {
+			testToken0, testToken1, big.NewInt(1000), big.NewInt(2000),
+		},
+		common.HexToAddress("0x0000000000000000000000000000000000000a02"): {
+			testToken0, testToken1, big.NewInt(3000), big.NewInt(5000),
+		},
+	}
+)
+
+func newTestLoader(t *testing.T, batchSize int) (*Loader, *stubNode) {
+	node := &stubNode{
+		block:  1234,
+		pools:  testPools,
+		blocks: make(map[string]int),
+	}
+
+	var cl *rpc.Client
+	node.Node, cl = rpcstub.New(t, node.handle)
+
+	return NewLoader(cl, batchSize), node
+}
+
+func TestLoad(t *testing.T) {
+	loader, node := newTestLoader(t, 2)
+
+	pools := []entities.Pool{
+		{Address: "0x0000000000000000000000000000000000000a01"},
+		{Address: "0x0000000000000000000000000000000000000A02"},
+		{Address: "0x0000000000000000000000000000000000000a02"},
+		{Address: "0x0000000000000000000000000000000000000a03"},
+	}
+
+	snap, err := loader.Load(context.Background(), pools, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if snap.Block != node.block {
+		t.Errorf("snapshot block %v, expected %v", snap.Block, node.block)
+	}
+	if len(snap.Reserves) != 2 {
+		t.Errorf("loaded %v pools, expected 2", len(snap.Reserves))
+	}
+	if len(snap.Failed) != 1 {
+		t.Errorf("failed %v pools, expected 1", len(snap.Failed))
+	}
+
+	for addr, expected := range testPools {
+		res, ok := snap.Get(addr.Hex())
+		if !ok {
+			t.Errorf("pool %s not loaded", addr)
+
+			continue
+		}
+		if res.Reserve0.Cmp(expected.reserve0) != 0 ||
+			res.Reserve1.Cmp(expected.reserve1) != 0 {
+			t.Errorf(
+				"pool %s reserves %s/%s, expected %s/%s",
+				addr, res.Reserve0, res.Reserve1,
+				expected.reserve0, expected.reserve1,
+			)
+		}
+		if res.Token0 != expected.token0.Hex() ||
+			res.Token1 != expected.token1.Hex() {
+			t.Errorf("pool %s tokens %s/%s", addr, res.Token0, res.Token1)
+		}
+	}
+
+	// 3 pools * 3 calls split on batches of 2
+	if node.Batches() != 5 {
+		t.Errorf("sent %v batches, expected 5", node.Batches())
+	}
+	if len(node.blocks) != 1 || node.blocks[hexutil.EncodeUint64(node.block)] != 9 {
+		t.Errorf("calls are not pinned to one block: %v", node.blocks)
+	}
+}
+
+func TestLoadAtBlock(t *testing.T) {
+	loader, node := newTestLoader(t, 0)
+
+	pools := []entities.Pool{
+		{Address: "0x0000000000000000000000000000000000000a01"},
+	}
+
+	snap, err := loader.Load(context.Background(), pools, big.NewInt(77))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if snap.Block != 77 || node.blocks["0x4d"] != 3 {
+		t.Errorf("calls not pinned to given block: %v", node.blocks)
+	}
+	if node.Batches() != 1 {
+		t.Errorf("sent %v batches, expected 1", node.Batches())
+	}
+}
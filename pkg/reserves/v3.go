@@ -0,0 +1,380 @@
+package reserves
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+
+	"github.com/antonyuhnovets/flash-loan-arbitrage/internal/entities"
+	"github.com/antonyuhnovets/flash-loan-arbitrage/pkg/simulator"
+)
+
+// DefaultTickWords is number of tick bitmap words loaded
+// on each side of the current one
+const DefaultTickWords = 1
+
+var (
+	slot0Selector       = selector("slot0()")
+	liquiditySelector   = selector("liquidity()")
+	feeSelector         = selector("fee()")
+	tickSpacingSelector = selector("tickSpacing()")
+	tickBitmapSelector  = selector("tickBitmap(int16)")
+	ticksSelector       = selector("ticks(int24)")
+)
+
+var twoTo256 = new(big.Int).Lsh(big.NewInt(1), 256)
+
+// V3Snapshot is a block consistent state of uniswap-v3 pools
+type V3Snapshot struct {
+	Block  uint64
+	Pools  map[common.Address]simulator.V3Pool
+	Failed map[common.Address]error
+}
+
+func (s *V3Snapshot) Get(pool string) (
+	res simulator.V3Pool,
+	ok bool,
+) {
+	res, ok = s.Pools[common.HexToAddress(pool)]
+
+	return
+}
+
+// LoadV3 fetches price, liquidity & initialized ticks of
+// uniswap-v3 pools pinned to block, ticks are read from
+// words bitmap words around the current tick
+func (l *Loader) LoadV3(
+	ctx context.Context,
+	pools []entities.Pool,
+	block *big.Int,
+	words int,
+) (
+	snap *V3Snapshot,
+	err error,
+) {
+	var number uint64
+
+	if block == nil {
+		number, err = l.BlockNumber(ctx)
+		if err != nil {
+			return
+		}
+	} else {
+		number = block.Uint64()
+	}
+
+	snap = &V3Snapshot{
+		Block:  number,
+		Pools:  make(map[common.Address]simulator.V3Pool),
+		Failed: make(map[common.Address]error),
+	}
+	blockArg := hexutil.EncodeUint64(number)
+
+	// state of pools
+	selectors := [][]byte{
+		slot0Selector, liquiditySelector, token0Selector,
+		token1Selector, feeSelector, tickSpacingSelector,
+	}
+	n := len(selectors)
+
+	addrs := uniqueAddresses(pools)
+	calls := make([]rpc.BatchElem, 0, len(addrs)*n)
+	results := make([]hexutil.Bytes, len(addrs)*n)
+
+	for i, addr := range addrs {
+		for j, sel := range selectors {
+			calls = append(calls, rpc.BatchElem{
+				Method: "eth_call",
+				Args:   []interface{}{callArg(addr, sel), blockArg},
+				Result: &results[i*n+j],
+			})
+		}
+	}
+
+	err = l.batch(ctx, calls)
+	if err != nil {
+		return
+	}
+
+	spacings := make(map[common.Address]int)
+
+	for i, addr := range addrs {
+		pool, spacing, _err := decodeV3Pool(
+			addr, calls[i*n:i*n+n], results[i*n:i*n+n],
+		)
+		if _err != nil {
+			snap.Failed[addr] = _err
+
+			continue
+		}
+
+		pool.TickLower, pool.TickUpper = tickRange(pool.Tick, spacing, words)
+		snap.Pools[addr] = pool
+		spacings[addr] = spacing
+	}
+
+	err = l.loadTicks(ctx, snap, spacings, blockArg)
+
+	return
+}
+
+// loadTicks reads bitmap words within loaded range of each pool
+// & liquidity of every initialized tick found there
+func (l *Loader) loadTicks(
+	ctx context.Context,
+	snap *V3Snapshot,
+	spacings map[common.Address]int,
+	blockArg string,
+) (
+	err error,
+) {
+	type word struct {
+		pool common.Address
+		pos  int
+	}
+
+	var (
+		words   []word
+		calls   []rpc.BatchElem
+		results []hexutil.Bytes
+	)
+
+	for addr, pool := range snap.Pools {
+		spacing := spacings[addr]
+		low, _ := wordPosition(pool.TickLower, spacing)
+		high, _ := wordPosition(pool.TickUpper-spacing, spacing)
+
+		for pos := low; pos <= high; pos++ {
+			words = append(words, word{addr, pos})
+		}
+	}
+
+	results = make([]hexutil.Bytes, len(words))
+	for i, w := range words {
+		calls = append(calls, rpc.BatchElem{
+			Method: "eth_call",
+			Args: []interface{}{
+				callArg(w.pool, callData(tickBitmapSelector, w.pos)),
+				blockArg,
+			},
+			Result: &results[i],
+		})
+	}
+
+	err = l.batch(ctx, calls)
+	if err != nil {
+		return
+	}
+
+	type tick struct {
+		pool  common.Address
+		index int
+	}
+
+	var ticks []tick
+
+	for i, w := range words {
+		if calls[i].Error != nil || len(results[i]) < 32 {
+			snap.Failed[w.pool] = fmt.Errorf("tick bitmap word %v: %v", w.pos, calls[i].Error)
+
+			continue
+		}
+
+		bitmap := new(big.Int).SetBytes(results[i][:32])
+		for bit := 0; bit < 256; bit++ {
+			if bitmap.Bit(bit) == 0 {
+				continue
+			}
+			ticks = append(ticks, tick{w.pool, (w.pos*256 + bit) * spacings[w.pool]})
+		}
+	}
+
+	calls = calls[:0]
+	results = make([]hexutil.Bytes, len(ticks))
+	for i, t := range ticks {
+		calls = append(calls, rpc.BatchElem{
+			Method: "eth_call",
+			Args: []interface{}{
+				callArg(t.pool, callData(ticksSelector, t.index)),
+				blockArg,
+			},
+			Result: &results[i],
+		})
+	}
+
+	err = l.batch(ctx, calls)
+	if err != nil {
+		return
+	}
+
+	for i, t := range ticks {
+		if calls[i].Error != nil || len(results[i]) < 64 {
+			snap.Failed[t.pool] = fmt.Errorf("tick %v: %v", t.index, calls[i].Error)
+
+			continue
+		}
+
+		pool := snap.Pools[t.pool]
+		pool.Ticks = append(pool.Ticks, simulator.V3Tick{
+			Index:        t.index,
+			LiquidityNet: decodeInt(results[i][32:64]),
+		})
+		snap.Pools[t.pool] = pool
+	}
+
+	for addr, pool := range snap.Pools {
+		if _, failed := snap.Failed[addr]; failed {
+			delete(snap.Pools, addr)
+
+			continue
+		}
+		sort.Slice(pool.Ticks, func(i, j int) bool {
+			return pool.Ticks[i].Index < pool.Ticks[j].Index
+		})
+	}
+
+	return
+}
+
+func decodeV3Pool(
+	addr common.Address,
+	calls []rpc.BatchElem,
+	results []hexutil.Bytes,
+) (
+	pool simulator.V3Pool,
+	spacing int,
+	err error,
+) {
+	for _, call := range calls {
+		if call.Error != nil {
+			err = call.Error
+
+			return
+		}
+	}
+
+	if len(results[0]) < 64 {
+		err = fmt.Errorf("invalid slot0 output %s", results[0])
+
+		return
+	}
+	for _, r := range results[1:] {
+		if len(r) < 32 {
+			err = fmt.Errorf("invalid pool output")
+
+			return
+		}
+	}
+
+	spacing = int(decodeInt(results[5][:32]).Int64())
+	if spacing <= 0 {
+		err = fmt.Errorf("invalid tick spacing %v", spacing)
+
+		return
+	}
+
+	pool = simulator.V3Pool{
+		Pool:         addr.Hex(),
+		Token0:       common.BytesToAddress(results[2][:32]).Hex(),
+		Token1:       common.BytesToAddress(results[3][:32]).Hex(),
+		Fee:          uint32(new(big.Int).SetBytes(results[4][:32]).Uint64()),
+		SqrtPriceX96: new(big.Int).SetBytes(results[0][:32]),
+		Tick:         int(decodeInt(results[0][32:64]).Int64()),
+		Liquidity:    new(big.Int).SetBytes(results[1][:32]),
+	}
+
+	return
+}
+
+// tickRange returns ticks covered by loaded bitmap words,
+// initialized ticks outside of it are unknown
+func tickRange(tick, spacing, words int) (
+	lower, upper int,
+) {
+	pos, _ := wordPosition(tick, spacing)
+
+	lower = (pos - words) * 256 * spacing
+	upper = (pos + words + 1) * 256 * spacing
+
+	if lower < simulator.MinTick {
+		lower = simulator.MinTick
+	}
+	if upper > simulator.MaxTick {
+		upper = simulator.MaxTick
+	}
+
+	return
+}
+
+// wordPosition mirrors TickBitmap.position of compressed tick
+func wordPosition(tick, spacing int) (
+	word, bit int,
+) {
+	compressed := tick / spacing
+	if tick < 0 && tick%spacing != 0 {
+		compressed--
+	}
+
+	word = compressed >> 8
+	bit = compressed & 0xff
+
+	return
+}
+
+// decodeInt reads two's complement abi word
+func decodeInt(b []byte) *big.Int {
+	v := new(big.Int).SetBytes(b)
+	if len(b) > 0 && b[0]&0x80 != 0 {
+		v.Sub(v, twoTo256)
+	}
+
+	return v
+}
+
+func callData(sel []byte, arg int) (
+	data []byte,
+) {
+	data = make([]byte, 0, len(sel)+32)
+	data = append(data, sel...)
+	data = append(data, encodeInt(arg)...)
+
+	return
+}
+
+// encodeInt packs signed integer as abi word
+func encodeInt(v int) []byte {
+	n := big.NewInt(int64(v))
+	if n.Sign() < 0 {
+		n.Add(n, twoTo256)
+	}
+
+	return common.LeftPadBytes(n.Bytes(), 32)
+}
+
+func (l *Loader) batch(
+	ctx context.Context,
+	calls []rpc.BatchElem,
+) (
+	err error,
+) {
+	for start := 0; start < len(calls); start += l.batchSize {
+		end := start + l.batchSize
+		if end > len(calls) {
+			end = len(calls)
+		}
+
+		err = l.rpc.BatchCallContext(ctx, calls[start:end])
+		if err != nil {
+			err = fmt.Errorf("batch %v-%v: %w", start, end, err)
+
+			return
+		}
+	}
+
+	return
+}
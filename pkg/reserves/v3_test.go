@@ -0,0 +1,46 @@
+package reserves
+
+import (
+	"testing"
+)
+
+func TestWordPosition(t *testing.T) {
+	tests := []struct {
+		tick, spacing int
+		word, bit     int
+	}{
+		{0, 60, 0, 0},
+		{60 * 255, 60, 0, 255},
+		{60 * 256, 60, 1, 0},
+		{-1, 60, -1, 255},
+		{-60, 60, -1, 255},
+		{-61, 60, -1, 254},
+		{-60 * 256, 1, -60, 0},
+	}
+
+	for _, tt := range tests {
+		word, bit := wordPosition(tt.tick, tt.spacing)
+		if word != tt.word || bit != tt.bit {
+			t.Errorf(
+				"tick %v spacing %v: got (%v, %v), want (%v, %v)",
+				tt.tick, tt.spacing, word, bit, tt.word, tt.bit,
+			)
+		}
+	}
+}
+
+func TestTickRange(t *testing.T) {
+	lower, upper := tickRange(-100, 60, 1)
+	if lower != -2*256*60 || upper != 256*60 {
+		t.Errorf("got [%v, %v]", lower, upper)
+	}
+}
+
+func TestSignedWord(t *testing.T) {
+	for _, v := range []int{0, 1, -1, 887272, -887272} {
+		got := decodeInt(encodeInt(v))
+		if got.Int64() != int64(v) {
+			t.Errorf("%v decoded as %s", v, got)
+		}
+	}
+}
@@ -0,0 +1,183 @@
+package reserves
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+
+	"github.com/antonyuhnovets/flash-loan-arbitrage/internal/entities"
+)
+
+var balanceOfSelector = selector("balanceOf(address)")
+
+// Validate checks pools on chain: contract is deployed, its tokens
+// are the tokens of pair & base token liquidity is at least min,
+// pools without base token are not checked for liquidity.
+// Uniswap-v2 liquidity is a reserve, v3 one is pool balance of token
+func (l *Loader) Validate(
+	ctx context.Context,
+	pools []entities.Pool,
+	base string,
+	min *big.Int,
+) (
+	valid []entities.Pool,
+	rejected []entities.PoolRejection,
+	err error,
+) {
+	if len(pools) == 0 {
+		return
+	}
+
+	number, err := l.BlockNumber(ctx)
+	if err != nil {
+		return
+	}
+	blockArg := hexutil.EncodeUint64(number)
+	baseAddr := common.HexToAddress(base)
+
+	const n = 4
+
+	calls := make([]rpc.BatchElem, 0, len(pools)*n)
+	codes := make([]hexutil.Bytes, len(pools))
+	results := make([]hexutil.Bytes, len(pools)*3)
+
+	for i, pool := range pools {
+		addr := common.HexToAddress(pool.Address)
+
+		liquidity := callArg(addr, getReservesSelector)
+		if pool.FeeTier != 0 {
+			liquidity = callArg(baseAddr, callDataAddress(balanceOfSelector, addr))
+		}
+
+		calls = append(calls,
+			rpc.BatchElem{
+				Method: "eth_getCode",
+				Args:   []interface{}{addr, blockArg},
+				Result: &codes[i],
+			},
+			rpc.BatchElem{
+				Method: "eth_call",
+				Args:   []interface{}{callArg(addr, token0Selector), blockArg},
+				Result: &results[i*3],
+			},
+			rpc.BatchElem{
+				Method: "eth_call",
+				Args:   []interface{}{callArg(addr, token1Selector), blockArg},
+				Result: &results[i*3+1],
+			},
+			rpc.BatchElem{
+				Method: "eth_call",
+				Args:   []interface{}{liquidity, blockArg},
+				Result: &results[i*3+2],
+			},
+		)
+	}
+
+	err = l.batch(ctx, calls)
+	if err != nil {
+		return
+	}
+
+	for i, pool := range pools {
+		reason, detail := checkPool(
+			pool, baseAddr, min,
+			calls[i*n:i*n+n], codes[i], results[i*3:i*3+3],
+		)
+		if reason != "" {
+			rejected = append(rejected, entities.PoolRejection{
+				Pool:   pool,
+				Reason: reason,
+				Detail: detail,
+			})
+
+			continue
+		}
+		valid = append(valid, pool)
+	}
+
+	return
+}
+
+func checkPool(
+	pool entities.Pool,
+	base common.Address,
+	min *big.Int,
+	calls []rpc.BatchElem,
+	code hexutil.Bytes,
+	results []hexutil.Bytes,
+) (
+	reason, detail string,
+) {
+	if calls[0].Error != nil {
+		return entities.RejectCallFailed, calls[0].Error.Error()
+	}
+	if len(code) == 0 {
+		return entities.RejectNoCode, ""
+	}
+
+	for i, call := range calls[1:3] {
+		if call.Error != nil {
+			return entities.RejectCallFailed, call.Error.Error()
+		}
+		if len(results[i]) < 32 {
+			return entities.RejectCallFailed, "invalid token output"
+		}
+	}
+
+	token0 := common.BytesToAddress(results[0][:32])
+	token1 := common.BytesToAddress(results[1][:32])
+	pair0 := common.HexToAddress(pool.Pair.Token0.Address)
+	pair1 := common.HexToAddress(pool.Pair.Token1.Address)
+
+	if !(token0 == pair0 && token1 == pair1) &&
+		!(token0 == pair1 && token1 == pair0) {
+		return entities.RejectTokens, fmt.Sprintf("pool tokens %s, %s", token0, token1)
+	}
+
+	if min == nil || min.Sign() <= 0 {
+		return
+	}
+	// liquidity of pools without base token can't be measured in it,
+	// they are kept as middle hops of routes through base tokens
+	if base != token0 && base != token1 {
+		return
+	}
+
+	if calls[3].Error != nil {
+		return entities.RejectCallFailed, calls[3].Error.Error()
+	}
+
+	var liquidity *big.Int
+
+	switch {
+	case pool.FeeTier != 0 && len(results[2]) >= 32:
+		liquidity = new(big.Int).SetBytes(results[2][:32])
+	case pool.FeeTier == 0 && len(results[2]) >= 64:
+		liquidity = new(big.Int).SetBytes(results[2][:32])
+		if base == token1 {
+			liquidity = new(big.Int).SetBytes(results[2][32:64])
+		}
+	default:
+		return entities.RejectCallFailed, "invalid liquidity output"
+	}
+
+	if liquidity.Cmp(min) < 0 {
+		return entities.RejectLowLiquidity, fmt.Sprintf("%s of base token", liquidity)
+	}
+
+	return
+}
+
+func callDataAddress(sel []byte, addr common.Address) (
+	data []byte,
+) {
+	data = make([]byte, 0, len(sel)+32)
+	data = append(data, sel...)
+	data = append(data, common.LeftPadBytes(addr.Bytes(), 32)...)
+
+	return
+}
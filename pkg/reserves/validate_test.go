@@ -0,0 +1,78 @@
+package reserves
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/antonyuhnovets/flash-loan-arbitrage/internal/entities"
+)
+
+func TestValidate(t *testing.T) {
+	loader, _ := newTestLoader(t, 0)
+
+	pair := entities.TokenPair{
+		Token0: entities.Token{Address: testToken0.Hex()},
+		Token1: entities.Token{Address: testToken1.Hex()},
+	}
+	reversed := entities.TokenPair{Token0: pair.Token1, Token1: pair.Token0}
+	other := entities.TokenPair{
+		Token0: pair.Token0,
+		Token1: entities.Token{Address: "0x0000000000000000000000000000000000000b03"},
+	}
+
+	pools := []entities.Pool{
+		{Address: "0x0000000000000000000000000000000000000a01", Pair: pair},
+		{Address: "0x0000000000000000000000000000000000000a02", Pair: reversed},
+		{Address: "0x0000000000000000000000000000000000000a03", Pair: pair},
+		{Address: "0x0000000000000000000000000000000000000a02", Pair: other},
+	}
+
+	valid, rejected, err := loader.Validate(
+		context.Background(), pools, testToken0.Hex(), big.NewInt(2000),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(valid) != 1 || valid[0] != pools[1] {
+		t.Errorf("valid pools %+v", valid)
+	}
+
+	expected := []string{
+		entities.RejectLowLiquidity,
+		entities.RejectNoCode,
+		entities.RejectTokens,
+	}
+	if len(rejected) != len(expected) {
+		t.Fatalf("rejected %+v", rejected)
+	}
+	for i, reason := range expected {
+		if rejected[i].Reason != reason {
+			t.Errorf("pool %s rejected as %s, expected %s",
+				rejected[i].Pool.Address, rejected[i].Reason, reason)
+		}
+	}
+
+	// without threshold only existence & tokens are checked
+	valid, _, err = loader.Validate(
+		context.Background(), pools[:2], testToken1.Hex(), nil,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(valid) != 2 {
+		t.Errorf("valid pools %+v", valid)
+	}
+
+	// pools without base token are kept whatever their liquidity
+	valid, rejected, err = loader.Validate(
+		context.Background(), pools[:2], other.Token1.Address, big.NewInt(2000),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(valid) != 2 || len(rejected) != 0 {
+		t.Errorf("valid pools %+v, rejected %+v", valid, rejected)
+	}
+}
@@ -0,0 +1,139 @@
+package routes
+
+import (
+	"encoding/json"
+	"math/big"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/antonyuhnovets/flash-loan-arbitrage/pkg/simulator"
+)
+
+const (
+	// MinHops is the shortest cycle, two pool cycles are trade pairs
+	MinHops = 3
+
+	DefaultMaxHops = 3
+	DefaultLimit   = 100
+)
+
+// Route is a cycle of swaps from base token back to it
+// sized for maximal profit
+type Route struct {
+	BaseToken string   `json:"baseToken"`
+	Tokens    []string `json:"tokens"` // base token first & last
+	Pools     []string `json:"pools"`
+	LogRate   float64  `json:"logRate"` // sum of -log spot rates, negative if profitable
+	Sizing
+}
+
+// MarshalJSON flattens sizing into route, as embedding does.
+// Promoted Sizing.MarshalJSON would drop fields of route
+func (r Route) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		BaseToken string   `json:"baseToken"`
+		Tokens    []string `json:"tokens"`
+		Pools     []string `json:"pools"`
+		LogRate   float64  `json:"logRate"`
+		sizingJSON
+	}{r.BaseToken, r.Tokens, r.Pools, r.LogRate, r.Sizing.json()})
+}
+
+// Finder searches profitable multi-hop cycles over pools
+type Finder struct {
+	maxHops int
+	limit   int
+}
+
+func NewFinder(opts ...Option) *Finder {
+	f := &Finder{
+		maxHops: DefaultMaxHops,
+		limit:   DefaultLimit,
+	}
+
+	for _, opt := range opts {
+		opt(f)
+	}
+
+	return f
+}
+
+// Find returns cycles through base tokens with negative log price,
+// sized optimally & ranked from the most profitable
+func (f *Finder) Find(
+	pools []simulator.Reserves,
+	baseTokens []string,
+) (
+	out []Route,
+) {
+	g := NewGraph(pools)
+
+	for _, base := range baseTokens {
+		source := common.HexToAddress(base)
+		if !g.NegativeCycle(source) {
+			continue
+		}
+
+		for _, cycle := range g.Cycles(source, MinHops, f.maxHops) {
+			route, ok := f.score(source, cycle)
+			if ok {
+				out = append(out, route)
+			}
+		}
+	}
+
+	sort.SliceStable(out, func(i, j int) bool {
+		return out[i].Profit.Cmp(out[j].Profit) > 0
+	})
+	if len(out) > f.limit {
+		out = out[:f.limit]
+	}
+
+	return
+}
+
+func (f *Finder) score(source common.Address, cycle []Edge) (
+	route Route,
+	ok bool,
+) {
+	route = Route{
+		BaseToken: source.Hex(),
+		Tokens:    []string{source.Hex()},
+	}
+
+	for _, e := range cycle {
+		route.LogRate += e.Weight
+		route.Tokens = append(route.Tokens, e.To.Hex())
+		route.Pools = append(route.Pools, e.Pool.Hex())
+	}
+	if route.LogRate >= 0 {
+		return
+	}
+
+	sizing, err := OptimalInput(cycle)
+	if err != nil {
+		return
+	}
+	route.Sizing = sizing
+	ok = true
+
+	return
+}
+
+// Simulate swaps amountIn along edges one after another
+func Simulate(edges []Edge, amountIn *big.Int) (
+	out *big.Int,
+	err error,
+) {
+	out = amountIn
+
+	for _, e := range edges {
+		out, err = e.AmountOut(out)
+		if err != nil {
+			return
+		}
+	}
+
+	return
+}
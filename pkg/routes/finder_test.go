@@ -0,0 +1,137 @@
+package routes
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/antonyuhnovets/flash-loan-arbitrage/pkg/simulator"
+)
+
+const (
+	tokenA = "0x0000000000000000000000000000000000000b01"
+	tokenB = "0x0000000000000000000000000000000000000b02"
+	tokenC = "0x0000000000000000000000000000000000000b03"
+)
+
+var oneEther = new(big.Int).Exp(big.NewInt(10), big.NewInt(18), nil)
+
+func ether(n int64) *big.Int {
+	return new(big.Int).Mul(big.NewInt(n), oneEther)
+}
+
+func pool(addr, t0, t1 string, r0, r1 int64) simulator.Reserves {
+	return simulator.Reserves{
+		Pool:     addr,
+		Token0:   t0,
+		Token1:   t1,
+		Reserve0: ether(r0),
+		Reserve1: ether(r1),
+	}
+}
+
+// 1 A = 2 B = 3 C on fair market
+func triangle(caA int64) []simulator.Reserves {
+	return []simulator.Reserves{
+		pool("0xa1", tokenA, tokenB, 1000, 2000),
+		pool("0xa2", tokenB, tokenC, 2000, 3000),
+		pool("0xa3", tokenC, tokenA, 3000, caA),
+	}
+}
+
+func TestFindTriangle(t *testing.T) {
+	f := NewFinder()
+
+	// C is cheap on third pool: A -> B -> C -> A
+	routes := f.Find(triangle(1200), []string{tokenA})
+	if len(routes) != 1 {
+		t.Fatalf("routes %+v", routes)
+	}
+
+	r := routes[0]
+	if len(r.Pools) != 3 || r.Pools[0] != common.HexToAddress("0xa1").Hex() {
+		t.Errorf("pools %v", r.Pools)
+	}
+	if r.Tokens[0] != r.Tokens[3] || r.Tokens[0] != common.HexToAddress(tokenA).Hex() {
+		t.Errorf("tokens %v", r.Tokens)
+	}
+	if r.LogRate >= 0 || r.Profit.Sign() <= 0 {
+		t.Errorf("route %+v", r)
+	}
+
+	out, err := Simulate(cycleABC(t, triangle(1200)), r.AmountIn)
+	if err != nil || out.Cmp(r.AmountOut) != 0 {
+		t.Errorf("simulated %s %v, route %s", out, err, r.AmountOut)
+	}
+}
+
+func TestRouteJSON(t *testing.T) {
+	r := NewFinder().Find(triangle(1200), []string{tokenA})[0]
+
+	b, err := json.Marshal(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var out map[string]interface{}
+	if err = json.Unmarshal(b, &out); err != nil {
+		t.Fatal(err)
+	}
+	if out["baseToken"] != r.BaseToken || out["method"] != ClosedForm {
+		t.Errorf("route fields lost %s", b)
+	}
+	if out["amountIn"] != r.AmountIn.String() || out["profit"] != r.Profit.String() {
+		t.Errorf("amounts are not decimal strings %s", b)
+	}
+}
+
+func cycleABC(t *testing.T, pools []simulator.Reserves) []Edge {
+	cycles := NewGraph(pools).Cycles(common.HexToAddress(tokenA), MinHops, DefaultMaxHops)
+	for _, c := range cycles {
+		if c[0].Pool == common.HexToAddress("0xa1") {
+			return c
+		}
+	}
+	t.Fatal("cycle not found")
+
+	return nil
+}
+
+func TestFindFairMarket(t *testing.T) {
+	g := NewGraph(triangle(1000))
+	if g.NegativeCycle(common.HexToAddress(tokenA)) {
+		t.Error("negative cycle on fair market")
+	}
+
+	// mispricing below swap fees is not an opportunity
+	if routes := NewFinder().Find(triangle(1003), []string{tokenA}); len(routes) != 0 {
+		t.Errorf("routes %+v", routes)
+	}
+}
+
+func TestCycles(t *testing.T) {
+	pools := append(
+		triangle(1000),
+		pool("0xa4", tokenA, tokenB, 10, 20), // parallel A-B pool
+	)
+	g := NewGraph(pools)
+
+	// both directions through each A-B pool
+	cycles := g.Cycles(common.HexToAddress(tokenA), MinHops, 3)
+	if len(cycles) != 4 {
+		t.Errorf("%v cycles", len(cycles))
+	}
+
+	// A -a1-> B -a4-> A is a pair, not a route
+	for _, c := range cycles {
+		if len(c) < MinHops {
+			t.Errorf("short cycle %+v", c)
+		}
+	}
+
+	if len(g.Cycles(common.HexToAddress(tokenA), 2, 2)) != 2 {
+		t.Error("two pool cycles not found")
+	}
+}
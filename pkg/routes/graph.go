@@ -0,0 +1,213 @@
+package routes
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/antonyuhnovets/flash-loan-arbitrage/pkg/simulator"
+)
+
+// Edge is a swap direction of a pool, weight is -log of spot
+// rate after fee, so a cycle of negative weight sum is profitable
+type Edge struct {
+	Pool       common.Address
+	From       common.Address
+	To         common.Address
+	ReserveIn  *big.Int
+	ReserveOut *big.Int
+	FeeBps     uint32
+	Weight     float64
+}
+
+// AmountOut swaps amountIn through the edge with constant product math
+func (e Edge) AmountOut(amountIn *big.Int) (*big.Int, error) {
+	return simulator.GetAmountOutFee(amountIn, e.ReserveIn, e.ReserveOut, e.FeeBps)
+}
+
+// NewEdge returns swap of tokenIn through uniswap-v2 like pool
+func NewEdge(r simulator.Reserves, tokenIn string) (
+	e Edge,
+	err error,
+) {
+	in := common.HexToAddress(tokenIn)
+
+	switch in {
+	case common.HexToAddress(r.Token0):
+		e = edge(r, false)
+	case common.HexToAddress(r.Token1):
+		e = edge(r, true)
+	default:
+		err = fmt.Errorf("token %s not in pool %s", tokenIn, r.Pool)
+
+		return
+	}
+	e.Weight = weight(e.ReserveIn, e.ReserveOut, e.FeeBps)
+
+	return
+}
+
+func edge(r simulator.Reserves, reverse bool) Edge {
+	e := Edge{
+		Pool:       common.HexToAddress(r.Pool),
+		From:       common.HexToAddress(r.Token0),
+		To:         common.HexToAddress(r.Token1),
+		ReserveIn:  r.Reserve0,
+		ReserveOut: r.Reserve1,
+		FeeBps:     r.Fee(),
+	}
+	if reverse {
+		e.From, e.To = e.To, e.From
+		e.ReserveIn, e.ReserveOut = e.ReserveOut, e.ReserveIn
+	}
+
+	return e
+}
+
+// Graph has tokens as nodes & uniswap-v2 like pools as edges,
+// every pool gives an edge per direction
+type Graph struct {
+	edges map[common.Address][]Edge
+	nodes []common.Address
+}
+
+func NewGraph(pools []simulator.Reserves) *Graph {
+	g := &Graph{
+		edges: make(map[common.Address][]Edge),
+	}
+
+	for _, r := range pools {
+		if r.Reserve0 == nil || r.Reserve1 == nil ||
+			r.Reserve0.Sign() <= 0 || r.Reserve1.Sign() <= 0 {
+			continue
+		}
+
+		g.add(edge(r, false))
+		g.add(edge(r, true))
+	}
+
+	return g
+}
+
+func (g *Graph) add(e Edge) {
+	e.Weight = weight(e.ReserveIn, e.ReserveOut, e.FeeBps)
+
+	if _, ok := g.edges[e.From]; !ok {
+		g.nodes = append(g.nodes, e.From)
+	}
+	if _, ok := g.edges[e.To]; !ok {
+		g.nodes = append(g.nodes, e.To)
+		g.edges[e.To] = nil
+	}
+	g.edges[e.From] = append(g.edges[e.From], e)
+}
+
+// Edges returns swaps out of token
+func (g *Graph) Edges(token common.Address) []Edge {
+	return g.edges[token]
+}
+
+// NegativeCycle runs Bellman-Ford from source & reports whether a
+// negative cycle is reachable. Without one no cycle through source
+// is profitable at spot price
+func (g *Graph) NegativeCycle(source common.Address) bool {
+	if _, ok := g.edges[source]; !ok {
+		return false
+	}
+
+	dist := make(map[common.Address]float64, len(g.nodes))
+	for _, n := range g.nodes {
+		dist[n] = math.Inf(1)
+	}
+	dist[source] = 0
+
+	for i := 0; i < len(g.nodes)-1; i++ {
+		changed := false
+
+		for _, n := range g.nodes {
+			if math.IsInf(dist[n], 1) {
+				continue
+			}
+			for _, e := range g.edges[n] {
+				if d := dist[n] + e.Weight; d < dist[e.To]-epsilon {
+					dist[e.To] = d
+					changed = true
+				}
+			}
+		}
+		if !changed {
+			return false
+		}
+	}
+
+	for _, n := range g.nodes {
+		if math.IsInf(dist[n], 1) {
+			continue
+		}
+		for _, e := range g.edges[n] {
+			if dist[n]+e.Weight < dist[e.To]-epsilon {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// Cycles enumerates simple cycles starting & ending at source of
+// min..max hops, a pool & an intermediate token are used once
+func (g *Graph) Cycles(source common.Address, min, max int) (
+	out [][]Edge,
+) {
+	var (
+		path    []Edge
+		visited = map[common.Address]bool{source: true}
+		used    = make(map[common.Address]bool)
+	)
+
+	var walk func(at common.Address)
+	walk = func(at common.Address) {
+		for _, e := range g.edges[at] {
+			if used[e.Pool] {
+				continue
+			}
+
+			if e.To == source {
+				if len(path)+1 >= min {
+					cycle := make([]Edge, len(path), len(path)+1)
+					copy(cycle, path)
+					out = append(out, append(cycle, e))
+				}
+
+				continue
+			}
+			if visited[e.To] || len(path)+1 >= max {
+				continue
+			}
+
+			visited[e.To], used[e.Pool] = true, true
+			path = append(path, e)
+
+			walk(e.To)
+
+			path = path[:len(path)-1]
+			visited[e.To], used[e.Pool] = false, false
+		}
+	}
+	walk(source)
+
+	return
+}
+
+// rounding slack of float weights
+const epsilon = 1e-12
+
+// -log(reserveOut / reserveIn * (1 - fee))
+func weight(reserveIn, reserveOut *big.Int, feeBps uint32) float64 {
+	in, _ := new(big.Float).SetInt(reserveIn).Float64()
+	out, _ := new(big.Float).SetInt(reserveOut).Float64()
+
+	return -(math.Log(out) - math.Log(in) + math.Log1p(-float64(feeBps)/10000))
+}
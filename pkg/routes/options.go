@@ -0,0 +1,22 @@
+package routes
+
+// Option -.
+type Option func(*Finder)
+
+// MaxHops sets the longest cycle searched, at least 3
+func MaxHops(n int) Option {
+	return func(f *Finder) {
+		if n >= MinHops {
+			f.maxHops = n
+		}
+	}
+}
+
+// Limit sets max number of routes returned
+func Limit(n int) Option {
+	return func(f *Finder) {
+		if n > 0 {
+			f.limit = n
+		}
+	}
+}
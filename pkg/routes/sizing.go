@@ -0,0 +1,253 @@
+package routes
+
+import (
+	"encoding/json"
+	"errors"
+	"math/big"
+
+	"github.com/antonyuhnovets/flash-loan-arbitrage/internal/entities"
+)
+
+// Sizing methods
+const (
+	ClosedForm    = "closed-form"
+	GoldenSection = "golden-section"
+)
+
+// DefaultIterations of golden section search, interval shrinks
+// to 0.618^n of the initial one
+const DefaultIterations = 100
+
+var ErrNoProfit = errors.New("route is not profitable at any size")
+
+// SwapFunc returns output of a route for input amount
+type SwapFunc func(amountIn *big.Int) (*big.Int, error)
+
+// Sizing is a profit maximising input of a route
+type Sizing struct {
+	Method      string   `json:"method"`
+	AmountIn    *big.Int `json:"amountIn"`
+	AmountOut   *big.Int `json:"amountOut"`
+	Profit      *big.Int `json:"profit"`
+	SpotRate    float64  `json:"spotRate"`    // output per input of an infinitely small trade
+	PriceImpact float64  `json:"priceImpact"` // share of spot rate lost at optimal size
+	// output slippage which erases profit, in basis points
+	BreakEvenSlippageBps float64 `json:"breakEvenSlippageBps"`
+	// share of profit lost when input is 10% off optimal
+	Sensitivity float64 `json:"sensitivity"`
+}
+
+// sizingJSON is Sizing with amounts encoded as decimal strings
+type sizingJSON struct {
+	Method               string           `json:"method"`
+	AmountIn             *entities.Amount `json:"amountIn"`
+	AmountOut            *entities.Amount `json:"amountOut"`
+	Profit               *entities.Amount `json:"profit"`
+	SpotRate             float64          `json:"spotRate"`
+	PriceImpact          float64          `json:"priceImpact"`
+	BreakEvenSlippageBps float64          `json:"breakEvenSlippageBps"`
+	Sensitivity          float64          `json:"sensitivity"`
+}
+
+func (s Sizing) json() sizingJSON {
+	return sizingJSON{
+		Method:               s.Method,
+		AmountIn:             entities.NewAmount(s.AmountIn),
+		AmountOut:            entities.NewAmount(s.AmountOut),
+		Profit:               entities.NewAmount(s.Profit),
+		SpotRate:             s.SpotRate,
+		PriceImpact:          s.PriceImpact,
+		BreakEvenSlippageBps: s.BreakEvenSlippageBps,
+		Sensitivity:          s.Sensitivity,
+	}
+}
+
+func (s Sizing) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.json())
+}
+
+// OptimalInput sizes a route of constant product pools in closed form.
+// Hop x -> g*Rout*x / (10000*Rin + g*x), g = 10000 - fee, composes to
+// x -> A*x / (B + C*x), so profit A*x/(B+C*x) - x peaks at
+// x = (sqrt(A*B) - B) / C
+func OptimalInput(edges []Edge) (
+	s Sizing,
+	err error,
+) {
+	if len(edges) == 0 {
+		err = ErrNoProfit
+
+		return
+	}
+
+	a, b, c := big.NewInt(1), big.NewInt(1), big.NewInt(0)
+	for _, e := range edges {
+		g := big.NewInt(int64(10000 - e.FeeBps))
+		ai := new(big.Int).Mul(g, e.ReserveOut)
+		bi := new(big.Int).Mul(big.NewInt(10000), e.ReserveIn)
+
+		// C = b_i*C + g*A, before A is updated
+		c.Add(c.Mul(c, bi), new(big.Int).Mul(g, a))
+		a.Mul(a, ai)
+		b.Mul(b, bi)
+	}
+
+	if a.Cmp(b) <= 0 {
+		err = ErrNoProfit
+
+		return
+	}
+
+	x := new(big.Int).Sqrt(new(big.Int).Mul(a, b))
+	x.Sub(x, b)
+	x.Quo(x, c)
+	if x.Sign() <= 0 {
+		err = ErrNoProfit
+
+		return
+	}
+
+	spot, _ := new(big.Rat).SetFrac(a, b).Float64()
+
+	s, err = sizing(
+		ClosedForm,
+		func(in *big.Int) (*big.Int, error) { return Simulate(edges, in) },
+		x, spot,
+	)
+
+	return
+}
+
+// OptimizeSwap sizes any route by golden section search of profit
+// over (0, hi], profit of a route is concave in input
+func OptimizeSwap(swap SwapFunc, hi *big.Int, iterations int) (
+	s Sizing,
+	err error,
+) {
+	if hi == nil || hi.Sign() <= 0 {
+		err = ErrNoProfit
+
+		return
+	}
+	if iterations <= 0 {
+		iterations = DefaultIterations
+	}
+
+	profit := func(x *big.Int) *big.Int {
+		out, err := swap(x)
+		if err != nil {
+			return nil
+		}
+
+		return new(big.Int).Sub(out, x)
+	}
+
+	// golden ratio conjugate as 618034 / 1000000
+	phi, scale := big.NewInt(618034), big.NewInt(1000000)
+	point := func(lo, width *big.Int, frac *big.Int) *big.Int {
+		p := new(big.Int).Mul(width, frac)
+		p.Quo(p, scale)
+
+		return p.Add(p, lo)
+	}
+
+	lo, up := big.NewInt(1), new(big.Int).Set(hi)
+	for i := 0; i < iterations; i++ {
+		width := new(big.Int).Sub(up, lo)
+		if width.Cmp(big.NewInt(2)) <= 0 {
+			break
+		}
+
+		x1 := point(lo, width, new(big.Int).Sub(scale, phi))
+		x2 := point(lo, width, phi)
+
+		// failed swap is worse than any profit
+		p1, p2 := profit(x1), profit(x2)
+		if p2 == nil || (p1 != nil && p1.Cmp(p2) > 0) {
+			up = x2
+		} else {
+			lo = x1
+		}
+	}
+
+	x := new(big.Int).Add(lo, up)
+	x.Rsh(x, 1)
+
+	if p := profit(x); p == nil || p.Sign() <= 0 {
+		err = ErrNoProfit
+
+		return
+	}
+
+	// spot rate of a trade a billion times smaller than search range
+	var spot float64
+	d := new(big.Int).Quo(hi, big.NewInt(1e9))
+	if d.Sign() == 0 {
+		d = big.NewInt(1)
+	}
+	if out, _err := swap(d); _err == nil {
+		spot, _ = new(big.Rat).SetFrac(out, d).Float64()
+	}
+
+	s, err = sizing(GoldenSection, swap, x, spot)
+
+	return
+}
+
+func sizing(
+	method string,
+	swap SwapFunc,
+	x *big.Int,
+	spot float64,
+) (
+	s Sizing,
+	err error,
+) {
+	out, err := swap(x)
+	if err != nil {
+		return
+	}
+
+	s = Sizing{
+		Method:    method,
+		AmountIn:  x,
+		AmountOut: out,
+		Profit:    new(big.Int).Sub(out, x),
+		SpotRate:  spot,
+	}
+	if s.Profit.Sign() <= 0 {
+		err = ErrNoProfit
+
+		return
+	}
+
+	rate, _ := new(big.Rat).SetFrac(out, x).Float64()
+	if spot > 0 {
+		s.PriceImpact = 1 - rate/spot
+	}
+
+	profit, _ := new(big.Float).SetInt(s.Profit).Float64()
+	output, _ := new(big.Float).SetInt(out).Float64()
+	s.BreakEvenSlippageBps = profit / output * 10000
+
+	// worst of 10% under & over sized trade
+	worst := profit
+	for _, pct := range []int64{90, 110} {
+		in := new(big.Int).Mul(x, big.NewInt(pct))
+		in.Quo(in, big.NewInt(100))
+
+		o, _err := swap(in)
+		if _err != nil {
+			worst = 0
+
+			continue
+		}
+		p, _ := new(big.Float).SetInt(new(big.Int).Sub(o, in)).Float64()
+		if p < worst {
+			worst = p
+		}
+	}
+	s.Sensitivity = 1 - worst/profit
+
+	return
+}
@@ -0,0 +1,130 @@
+package routes
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/antonyuhnovets/flash-loan-arbitrage/pkg/simulator"
+)
+
+func pairEdges(t *testing.T, fee0, fee1 uint32) []Edge {
+	cheap := pool("0xa1", tokenA, tokenB, 1000, 2000)
+	cheap.FeeBps = fee0
+	dear := pool("0xa2", tokenA, tokenB, 1000, 1900)
+	dear.FeeBps = fee1
+
+	buy, err := NewEdge(cheap, tokenA)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sell, err := NewEdge(dear, tokenB)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return []Edge{buy, sell}
+}
+
+func profitAt(t *testing.T, edges []Edge, in *big.Int) *big.Int {
+	out, err := Simulate(edges, in)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return new(big.Int).Sub(out, in)
+}
+
+func TestOptimalInputIsMaximum(t *testing.T) {
+	for _, edges := range [][]Edge{
+		pairEdges(t, 30, 30),
+		pairEdges(t, 25, 30), // cross fee pair
+		cycleABC(t, triangle(1200)),
+	} {
+		s, err := OptimalInput(edges)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s.Method != ClosedForm || s.Profit.Sign() <= 0 {
+			t.Fatalf("sizing %+v", s)
+		}
+
+		// 0.1% off optimum either side is not better
+		delta := new(big.Int).Quo(s.AmountIn, big.NewInt(1000))
+		for _, in := range []*big.Int{
+			new(big.Int).Sub(s.AmountIn, delta),
+			new(big.Int).Add(s.AmountIn, delta),
+		} {
+			if p := profitAt(t, edges, in); p.Cmp(s.Profit) > 0 {
+				t.Errorf("profit %s at %s above optimum %s at %s", p, in, s.Profit, s.AmountIn)
+			}
+		}
+
+		if s.PriceImpact <= 0 || s.PriceImpact >= 1 {
+			t.Errorf("price impact %v", s.PriceImpact)
+		}
+		if s.BreakEvenSlippageBps <= 0 || s.Sensitivity <= 0 {
+			t.Errorf("sizing %+v", s)
+		}
+	}
+}
+
+func TestOptimalInputFee(t *testing.T) {
+	low, err := OptimalInput(pairEdges(t, 25, 25))
+	if err != nil {
+		t.Fatal(err)
+	}
+	def, err := OptimalInput(pairEdges(t, 30, 30))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if low.Profit.Cmp(def.Profit) <= 0 || low.AmountIn.Cmp(def.AmountIn) <= 0 {
+		t.Errorf("0.25%% sizing %+v, 0.3%% sizing %+v", low, def)
+	}
+}
+
+func TestOptimizeSwapMatchesClosedForm(t *testing.T) {
+	edges := cycleABC(t, triangle(1200))
+
+	exact, err := OptimalInput(edges)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := OptimizeSwap(
+		func(in *big.Int) (*big.Int, error) { return Simulate(edges, in) },
+		ether(1000), 0,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Method != GoldenSection {
+		t.Errorf("method %s", s.Method)
+	}
+
+	// within a billionth of optimal profit
+	diff := new(big.Int).Sub(exact.Profit, s.Profit)
+	diff.Mul(diff, big.NewInt(1e9))
+	if diff.Cmp(exact.Profit) > 0 {
+		t.Errorf("golden section profit %s, closed form %s", s.Profit, exact.Profit)
+	}
+}
+
+func TestOptimalInputNoProfit(t *testing.T) {
+	edges := cycleABC(t, triangle(1000))
+
+	if _, err := OptimalInput(edges); err != ErrNoProfit {
+		t.Errorf("got %v, expected %v", err, ErrNoProfit)
+	}
+	_, err := OptimizeSwap(
+		func(in *big.Int) (*big.Int, error) { return Simulate(edges, in) },
+		ether(1000), 0,
+	)
+	if err != ErrNoProfit {
+		t.Errorf("got %v, expected %v", err, ErrNoProfit)
+	}
+
+	if _, err = NewEdge(simulator.Reserves{Token0: tokenA, Token1: tokenB}, tokenC); err == nil {
+		t.Error("edge of foreign token")
+	}
+}
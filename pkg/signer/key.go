@@ -0,0 +1,96 @@
+package signer
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// Key signs with private key held in memory. Raw hex keys are for
+// development only, keystore or remote signer is used otherwise
+type Key struct {
+	key     *ecdsa.PrivateKey
+	address common.Address
+}
+
+// NewKey parses hex private key, 0x prefix is optional
+func NewKey(hexKey string) (
+	k *Key,
+	err error,
+) {
+	key, err := crypto.HexToECDSA(
+		strings.TrimPrefix(strings.TrimSpace(hexKey), "0x"),
+	)
+	if err != nil {
+		err = fmt.Errorf("invalid private key: %w", err)
+
+		return
+	}
+	k = FromECDSA(key)
+
+	return
+}
+
+func FromECDSA(key *ecdsa.PrivateKey) *Key {
+	return &Key{
+		key:     key,
+		address: crypto.PubkeyToAddress(key.PublicKey),
+	}
+}
+
+// NewKeystore unlocks V3 json keystore file by passphrase from the
+// first line of passphrase file, as geth --password does
+func NewKeystore(path, passphraseFile string) (
+	k *Key,
+	err error,
+) {
+	keyJSON, err := os.ReadFile(path)
+	if err != nil {
+		err = fmt.Errorf("read keystore: %w", err)
+
+		return
+	}
+
+	pass, err := os.ReadFile(passphraseFile)
+	if err != nil {
+		err = fmt.Errorf("read passphrase: %w", err)
+
+		return
+	}
+	passphrase := strings.TrimRight(
+		strings.SplitN(string(pass), "\n", 2)[0], "\r",
+	)
+
+	key, err := keystore.DecryptKey(keyJSON, passphrase)
+	if err != nil {
+		err = fmt.Errorf("unlock keystore %s: %w", path, err)
+
+		return
+	}
+	k = FromECDSA(key.PrivateKey)
+
+	return
+}
+
+func (k *Key) Address() common.Address {
+	return k.address
+}
+
+func (k *Key) SignTx(
+	ctx context.Context,
+	tx *types.Transaction,
+	chainID *big.Int,
+) (
+	*types.Transaction,
+	error,
+) {
+	return types.SignTx(tx, types.LatestSignerForChainID(chainID), k.key)
+}
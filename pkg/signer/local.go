@@ -0,0 +1,100 @@
+package signer
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"net/http"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// LocalSigner is a stand-in of clef or web3signer for tests & local runs,
+// it answers both sign methods with keys it holds without confirmation
+type LocalSigner struct {
+	server *rpc.Server
+	keys   map[common.Address]*Key
+}
+
+func NewLocalSigner(keys ...*Key) *LocalSigner {
+	ls := &LocalSigner{
+		server: rpc.NewServer(),
+		keys:   make(map[common.Address]*Key, len(keys)),
+	}
+	for _, k := range keys {
+		ls.keys[k.Address()] = k
+	}
+
+	ls.server.RegisterName("account", &clefAPI{ls})
+	ls.server.RegisterName("eth", &web3signerAPI{ls})
+
+	return ls
+}
+
+func (ls *LocalSigner) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ls.server.ServeHTTP(w, r)
+}
+
+func (ls *LocalSigner) sign(
+	ctx context.Context,
+	args txArgs,
+) (
+	raw hexutil.Bytes,
+	err error,
+) {
+	k, ok := ls.keys[args.From]
+	if !ok {
+		err = fmt.Errorf("unknown account %s", args.From)
+
+		return
+	}
+	if args.ChainID == nil {
+		err = fmt.Errorf("chain id is required")
+
+		return
+	}
+
+	tx, err := k.SignTx(ctx, args.tx(), (*big.Int)(args.ChainID))
+	if err != nil {
+		return
+	}
+	raw, err = tx.MarshalBinary()
+
+	return
+}
+
+type clefAPI struct {
+	ls *LocalSigner
+}
+
+func (api *clefAPI) SignTransaction(
+	ctx context.Context,
+	args txArgs,
+) (
+	res *signTxResponse,
+	err error,
+) {
+	raw, err := api.ls.sign(ctx, args)
+	if err != nil {
+		return
+	}
+	res = &signTxResponse{Raw: raw}
+
+	return
+}
+
+type web3signerAPI struct {
+	ls *LocalSigner
+}
+
+func (api *web3signerAPI) SignTransaction(
+	ctx context.Context,
+	args txArgs,
+) (
+	hexutil.Bytes,
+	error,
+) {
+	return api.ls.sign(ctx, args)
+}
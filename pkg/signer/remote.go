@@ -0,0 +1,235 @@
+package signer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+const (
+	// MethodClef signs with clef, result is {raw, tx}
+	MethodClef = "account_signTransaction"
+	// MethodWeb3Signer signs with web3signer, result is raw tx
+	MethodWeb3Signer = "eth_signTransaction"
+)
+
+var (
+	ErrTxChanged   = errors.New("signer changed transaction")
+	ErrWrongSigner = errors.New("transaction signed by other account")
+)
+
+// Remote signs by json-rpc of external signer, key never
+// leaves it. Signed transaction is checked to be the requested one
+type Remote struct {
+	rpc     *rpc.Client
+	address common.Address
+	method  string
+}
+
+// NewRemote connects signer holding key of address,
+// method is MethodClef if empty
+func NewRemote(
+	ctx context.Context,
+	url string,
+	address common.Address,
+	method string,
+) (
+	r *Remote,
+	err error,
+) {
+	if method == "" {
+		method = MethodClef
+	}
+	if method != MethodClef && method != MethodWeb3Signer {
+		err = fmt.Errorf("unknown signer method %q", method)
+
+		return
+	}
+	if address == (common.Address{}) {
+		err = errors.New("remote signer needs account address")
+
+		return
+	}
+
+	cl, err := rpc.DialContext(ctx, url)
+	if err != nil {
+		return
+	}
+
+	r = &Remote{
+		rpc:     cl,
+		address: address,
+		method:  method,
+	}
+
+	return
+}
+
+func (r *Remote) Address() common.Address {
+	return r.address
+}
+
+func (r *Remote) SignTx(
+	ctx context.Context,
+	tx *types.Transaction,
+	chainID *big.Int,
+) (
+	signed *types.Transaction,
+	err error,
+) {
+	var res json.RawMessage
+
+	err = r.rpc.CallContext(
+		ctx, &res, r.method, newTxArgs(r.address, tx, chainID),
+	)
+	if err != nil {
+		err = fmt.Errorf("remote signer: %w", err)
+
+		return
+	}
+
+	raw, err := decodeRaw(res)
+	if err != nil {
+		return
+	}
+
+	signed = new(types.Transaction)
+
+	err = signed.UnmarshalBinary(raw)
+	if err != nil {
+		err = fmt.Errorf("remote signer: %w", err)
+
+		return
+	}
+
+	err = verify(signed, tx, r.address, chainID)
+
+	return
+}
+
+// decodeRaw reads raw tx of web3signer string or clef object result
+func decodeRaw(res json.RawMessage) (
+	raw hexutil.Bytes,
+	err error,
+) {
+	if len(res) > 0 && res[0] == '"' {
+		err = json.Unmarshal(res, &raw)
+	} else {
+		var out signTxResponse
+
+		err = json.Unmarshal(res, &out)
+		raw = out.Raw
+	}
+	if err == nil && len(raw) == 0 {
+		err = errors.New("empty result")
+	}
+	if err != nil {
+		err = fmt.Errorf("remote signer: %w", err)
+	}
+
+	return
+}
+
+// verify checks tx is signed by account without any field changed
+func verify(
+	signed, tx *types.Transaction,
+	from common.Address,
+	chainID *big.Int,
+) error {
+	s := types.LatestSignerForChainID(chainID)
+
+	if s.Hash(signed) != s.Hash(tx) {
+		return ErrTxChanged
+	}
+
+	sender, err := types.Sender(s, signed)
+	if err != nil {
+		return err
+	}
+	if sender != from {
+		return fmt.Errorf("%w: %s", ErrWrongSigner, sender)
+	}
+
+	return nil
+}
+
+// txArgs is transaction in form of clef & web3signer sign requests
+type txArgs struct {
+	From                 common.Address  `json:"from"`
+	To                   *common.Address `json:"to,omitempty"`
+	Gas                  hexutil.Uint64  `json:"gas"`
+	GasPrice             *hexutil.Big    `json:"gasPrice,omitempty"`
+	MaxFeePerGas         *hexutil.Big    `json:"maxFeePerGas,omitempty"`
+	MaxPriorityFeePerGas *hexutil.Big    `json:"maxPriorityFeePerGas,omitempty"`
+	Value                *hexutil.Big    `json:"value"`
+	Nonce                hexutil.Uint64  `json:"nonce"`
+	Data                 hexutil.Bytes   `json:"data"`
+	ChainID              *hexutil.Big    `json:"chainId,omitempty"`
+}
+
+type signTxResponse struct {
+	Raw hexutil.Bytes      `json:"raw"`
+	Tx  *types.Transaction `json:"tx"`
+}
+
+func newTxArgs(
+	from common.Address,
+	tx *types.Transaction,
+	chainID *big.Int,
+) txArgs {
+	args := txArgs{
+		From:    from,
+		To:      tx.To(),
+		Gas:     hexutil.Uint64(tx.Gas()),
+		Value:   (*hexutil.Big)(tx.Value()),
+		Nonce:   hexutil.Uint64(tx.Nonce()),
+		Data:    tx.Data(),
+		ChainID: (*hexutil.Big)(chainID),
+	}
+
+	if tx.Type() == types.LegacyTxType {
+		args.GasPrice = (*hexutil.Big)(tx.GasPrice())
+	} else {
+		args.MaxFeePerGas = (*hexutil.Big)(tx.GasFeeCap())
+		args.MaxPriorityFeePerGas = (*hexutil.Big)(tx.GasTipCap())
+	}
+
+	return args
+}
+
+// tx builds unsigned transaction of request
+func (args txArgs) tx() *types.Transaction {
+	value := (*big.Int)(args.Value)
+	if value == nil {
+		value = new(big.Int)
+	}
+
+	if args.MaxFeePerGas != nil {
+		return types.NewTx(&types.DynamicFeeTx{
+			ChainID:   (*big.Int)(args.ChainID),
+			Nonce:     uint64(args.Nonce),
+			GasTipCap: (*big.Int)(args.MaxPriorityFeePerGas),
+			GasFeeCap: (*big.Int)(args.MaxFeePerGas),
+			Gas:       uint64(args.Gas),
+			To:        args.To,
+			Value:     value,
+			Data:      args.Data,
+		})
+	}
+
+	return types.NewTx(&types.LegacyTx{
+		Nonce:    uint64(args.Nonce),
+		GasPrice: (*big.Int)(args.GasPrice),
+		Gas:      uint64(args.Gas),
+		To:       args.To,
+		Value:    value,
+		Data:     args.Data,
+	})
+}
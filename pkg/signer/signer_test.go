@@ -0,0 +1,248 @@
+package signer
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+var testChainID = big.NewInt(5)
+
+func newTestKey(t *testing.T) *Key {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return FromECDSA(key)
+}
+
+func testTxs() map[string]*types.Transaction {
+	to := common.HexToAddress("0x5C69bEe701ef814a2B6a3EDD4B1652CB9cc5aA6f")
+
+	return map[string]*types.Transaction{
+		"legacy": types.NewTx(&types.LegacyTx{
+			Nonce:    7,
+			GasPrice: big.NewInt(20_000_000_000),
+			Gas:      210_000,
+			To:       &to,
+			Value:    big.NewInt(0),
+			Data:     []byte{0xde, 0xad, 0xbe, 0xef},
+		}),
+		"dynamic": types.NewTx(&types.DynamicFeeTx{
+			ChainID:   testChainID,
+			Nonce:     8,
+			GasTipCap: big.NewInt(1_500_000_000),
+			GasFeeCap: big.NewInt(30_000_000_000),
+			Gas:       210_000,
+			To:        &to,
+			Value:     big.NewInt(1),
+			Data:      []byte{0x01},
+		}),
+	}
+}
+
+func TestKey(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	hex := "0x" + common.Bytes2Hex(crypto.FromECDSA(key))
+
+	k, err := NewKey(hex)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if k.Address() != crypto.PubkeyToAddress(key.PublicKey) {
+		t.Errorf("address %s of key", k.Address())
+	}
+
+	if _, err = NewKey("not a key"); err == nil {
+		t.Error("invalid key accepted")
+	}
+}
+
+func TestKeystore(t *testing.T) {
+	dir := t.TempDir()
+
+	acc, err := keystore.StoreKey(
+		dir, "secret", keystore.LightScryptN, keystore.LightScryptP,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pass := filepath.Join(dir, "pass")
+	if err = os.WriteFile(pass, []byte("secret\r\nignored\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	k, err := NewKeystore(acc.URL.Path, pass)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if k.Address() != acc.Address {
+		t.Errorf("address %s, expected %s", k.Address(), acc.Address)
+	}
+
+	if err = os.WriteFile(pass, []byte("wrong"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = NewKeystore(acc.URL.Path, pass); !errors.Is(err, keystore.ErrDecrypt) {
+		t.Errorf("expected %v, got %v", keystore.ErrDecrypt, err)
+	}
+}
+
+func TestRemote(t *testing.T) {
+	k := newTestKey(t)
+
+	srv := httptest.NewServer(NewLocalSigner(k))
+	defer srv.Close()
+
+	ctx := context.Background()
+
+	for _, method := range []string{MethodClef, MethodWeb3Signer} {
+		r, err := NewRemote(ctx, srv.URL, k.Address(), method)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for name, tx := range testTxs() {
+			signed, err := r.SignTx(ctx, tx, testChainID)
+			if err != nil {
+				t.Fatalf("%s %s: %s", method, name, err)
+			}
+
+			s := types.LatestSignerForChainID(testChainID)
+			if s.Hash(signed) != s.Hash(tx) {
+				t.Errorf("%s %s: signed other tx", method, name)
+			}
+			from, err := types.Sender(s, signed)
+			if err != nil || from != k.Address() {
+				t.Errorf("%s %s: sender %s, %v", method, name, from, err)
+			}
+		}
+	}
+
+	// signer doesn't hold key of account
+	r, err := NewRemote(ctx, srv.URL, newTestKey(t).Address(), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = r.SignTx(ctx, testTxs()["legacy"], testChainID); err == nil {
+		t.Error("unknown account signed")
+	}
+
+	if _, err = NewRemote(ctx, srv.URL, common.Address{}, ""); err == nil {
+		t.Error("remote signer without address created")
+	}
+	if _, err = NewRemote(ctx, srv.URL, k.Address(), "personal_sign"); err == nil {
+		t.Error("unknown method accepted")
+	}
+}
+
+func TestVerify(t *testing.T) {
+	k := newTestKey(t)
+	ctx := context.Background()
+
+	for name, tx := range testTxs() {
+		signed, err := k.SignTx(ctx, tx, testChainID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err = verify(signed, tx, k.Address(), testChainID); err != nil {
+			t.Errorf("%s: %s", name, err)
+		}
+
+		other, err := newTestKey(t).SignTx(ctx, tx, testChainID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = verify(other, tx, k.Address(), testChainID)
+		if !errors.Is(err, ErrWrongSigner) {
+			t.Errorf("%s: expected %v, got %v", name, ErrWrongSigner, err)
+		}
+	}
+
+	// signer raised gas price of requested tx
+	tx := testTxs()["legacy"]
+	changed := types.NewTx(&types.LegacyTx{
+		Nonce:    tx.Nonce(),
+		GasPrice: new(big.Int).Add(tx.GasPrice(), big.NewInt(1)),
+		Gas:      tx.Gas(),
+		To:       tx.To(),
+		Value:    tx.Value(),
+		Data:     tx.Data(),
+	})
+	signed, err := k.SignTx(ctx, changed, testChainID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = verify(signed, tx, k.Address(), testChainID); !errors.Is(err, ErrTxChanged) {
+		t.Errorf("expected %v, got %v", ErrTxChanged, err)
+	}
+}
+
+func TestSource(t *testing.T) {
+	dir := t.TempDir()
+
+	acc, err := keystore.StoreKey(
+		dir, "secret", keystore.LightScryptN, keystore.LightScryptP,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pass := filepath.Join(dir, "pass")
+	if err = os.WriteFile(pass, []byte("secret\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	src := &Source{PassphraseFile: pass}
+
+	k, err := src.Keystore(acc.URL.Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if k.Address() != acc.Address {
+		t.Errorf("address %s, expected %s", k.Address(), acc.Address)
+	}
+
+	// remote signer is not configured
+	if _, err = src.Remote(context.Background(), acc.Address); !errors.Is(err, ErrNoSource) {
+		t.Errorf("expected %v, got %v", ErrNoSource, err)
+	}
+
+	var none *Source
+	if _, err = none.Keystore(acc.URL.Path); !errors.Is(err, ErrNoSource) {
+		t.Errorf("expected %v, got %v", ErrNoSource, err)
+	}
+
+	// keystores by name are limited to configured directory
+	if _, err = src.KeystoreIn(filepath.Base(acc.URL.Path)); !errors.Is(err, ErrNoSource) {
+		t.Errorf("expected %v, got %v", ErrNoSource, err)
+	}
+
+	src.KeystoreDir = dir
+	k, err = src.KeystoreIn(filepath.Base(acc.URL.Path))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if k.Address() != acc.Address {
+		t.Errorf("address %s, expected %s", k.Address(), acc.Address)
+	}
+
+	for _, name := range []string{"", "..", acc.URL.Path, "../" + filepath.Base(dir) + "/pass"} {
+		if _, err = src.KeystoreIn(name); !errors.Is(err, ErrKeystoreName) {
+			t.Errorf("keystore %q: expected %v, got %v", name, ErrKeystoreName, err)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package signer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+var (
+	ErrNoSource     = errors.New("signer source is not configured")
+	ErrKeystoreName = errors.New("keystore is not a file name")
+)
+
+// Source opens signers by reference, a keystore file unlocked with
+// configured passphrase or an account of configured remote signer.
+// Keys are never passed by callers
+type Source struct {
+	PassphraseFile string // passphrase of keystores
+	KeystoreDir    string // keystores opened by name, none if empty
+	URL            string // remote signer, no remote accounts if empty
+	Method         string
+}
+
+// Keystore unlocks keystore file at path
+func (s *Source) Keystore(path string) (
+	k *Key,
+	err error,
+) {
+	if s == nil || s.PassphraseFile == "" {
+		err = ErrNoSource
+
+		return
+	}
+
+	k, err = NewKeystore(path, s.PassphraseFile)
+
+	return
+}
+
+// KeystoreIn unlocks keystore file of name in keystore directory,
+// so untrusted callers can't probe other paths
+func (s *Source) KeystoreIn(name string) (
+	k *Key,
+	err error,
+) {
+	if s == nil || s.KeystoreDir == "" {
+		err = ErrNoSource
+
+		return
+	}
+	if name == "" || name == "." || name == ".." ||
+		filepath.Base(name) != name {
+		err = fmt.Errorf("%w: %q", ErrKeystoreName, name)
+
+		return
+	}
+
+	k, err = s.Keystore(filepath.Join(s.KeystoreDir, name))
+
+	return
+}
+
+// Remote connects remote signer holding key of address
+func (s *Source) Remote(ctx context.Context, address common.Address) (
+	r *Remote,
+	err error,
+) {
+	if s == nil || s.URL == "" {
+		err = ErrNoSource
+
+		return
+	}
+
+	r, err = NewRemote(ctx, s.URL, address, s.Method)
+
+	return
+}
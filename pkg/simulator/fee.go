@@ -0,0 +1,119 @@
+package simulator
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// ContractFeeBps is swap fee FlashBot.getAmountIn/Out assume, 0.3%
+const ContractFeeBps = 30
+
+const feeBase = 10000
+
+// ErrFeeTooHigh is returned for pools charging more than contract
+// assumes, the pair K check would revert the flash swap
+var ErrFeeTooHigh = fmt.Errorf("pool fee above contract fee")
+
+// Fee returns swap fee of pool in basis points, contract fee if unset
+func (r Reserves) Fee() uint32 {
+	if r.FeeBps == 0 {
+		return ContractFeeBps
+	}
+
+	return r.FeeBps
+}
+
+// GetAmountInFee is GetAmountIn of pair charging feeBps
+func GetAmountInFee(amountOut, reserveIn, reserveOut *big.Int, feeBps uint32) (
+	amountIn *big.Int,
+	err error,
+) {
+	if amountOut.Sign() <= 0 {
+		err = ErrInsufficientOut
+
+		return
+	}
+	if reserveIn.Sign() <= 0 || reserveOut.Sign() <= 0 {
+		err = ErrInsufficientLiq
+
+		return
+	}
+	if feeBps >= feeBase {
+		err = fmt.Errorf("invalid fee %v bps", feeBps)
+
+		return
+	}
+
+	numerator, err := mulUint(reserveIn, amountOut)
+	if err != nil {
+		return
+	}
+	numerator, err = mulUint(numerator, big.NewInt(feeBase))
+	if err != nil {
+		return
+	}
+
+	if amountOut.Cmp(reserveOut) > 0 {
+		err = ErrSafeMathSubtract
+
+		return
+	}
+	denominator, err := mulUint(
+		new(big.Int).Sub(reserveOut, amountOut),
+		big.NewInt(int64(feeBase-feeBps)),
+	)
+	if err != nil {
+		return
+	}
+
+	amountIn, err = divUint(numerator, denominator)
+	if err != nil {
+		return
+	}
+	amountIn, err = addUint(amountIn, big.NewInt(1))
+
+	return
+}
+
+// GetAmountOutFee is GetAmountOut of pair charging feeBps
+func GetAmountOutFee(amountIn, reserveIn, reserveOut *big.Int, feeBps uint32) (
+	amountOut *big.Int,
+	err error,
+) {
+	if amountIn.Sign() <= 0 {
+		err = ErrInsufficientIn
+
+		return
+	}
+	if reserveIn.Sign() <= 0 || reserveOut.Sign() <= 0 {
+		err = ErrInsufficientLiq
+
+		return
+	}
+	if feeBps >= feeBase {
+		err = fmt.Errorf("invalid fee %v bps", feeBps)
+
+		return
+	}
+
+	withFee, err := mulUint(amountIn, big.NewInt(int64(feeBase-feeBps)))
+	if err != nil {
+		return
+	}
+	numerator, err := mulUint(withFee, reserveOut)
+	if err != nil {
+		return
+	}
+	denominator, err := mulUint(reserveIn, big.NewInt(feeBase))
+	if err != nil {
+		return
+	}
+	denominator, err = addUint(denominator, withFee)
+	if err != nil {
+		return
+	}
+
+	amountOut, err = divUint(numerator, denominator)
+
+	return
+}
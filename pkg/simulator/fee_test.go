@@ -0,0 +1,92 @@
+package simulator
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+)
+
+func TestGetAmountOutFee(t *testing.T) {
+	contract, err := GetAmountOut(ether(1), ether(100), ether(200))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var prev *big.Int
+	for _, fee := range []uint32{100, 30, 25, 0} {
+		out, err := GetAmountOutFee(ether(1), ether(100), ether(200), fee)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if fee == ContractFeeBps && out.Cmp(contract) != 0 {
+			t.Errorf("0.3%% fee out %s, contract %s", out, contract)
+		}
+		if prev != nil && out.Cmp(prev) <= 0 {
+			t.Errorf("%v bps out %s not above %s", fee, out, prev)
+		}
+		prev = out
+	}
+
+	// no fee is plain x*y=k
+	out, _ := GetAmountOutFee(big.NewInt(1000), big.NewInt(1000000), big.NewInt(1000000), 0)
+	if out.Int64() != 999 {
+		t.Errorf("no fee out %s", out)
+	}
+
+	if _, err = GetAmountOutFee(ether(1), ether(1), ether(1), 10000); err == nil {
+		t.Error("100% fee accepted")
+	}
+}
+
+func TestGetAmountInFee(t *testing.T) {
+	for _, fee := range []uint32{0, 20, 25, 30, 100} {
+		in, err := GetAmountInFee(ether(1), ether(100), ether(200), fee)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// input must buy at least the requested output
+		out, err := GetAmountOutFee(in, ether(100), ether(200), fee)
+		if err != nil || out.Cmp(ether(1)) < 0 {
+			t.Errorf("%v bps: %s in gives %s out %v", fee, in, out, err)
+		}
+	}
+}
+
+func TestReservesAmountOutFee(t *testing.T) {
+	r := testReserves(testPool0, ether(100), ether(200))
+
+	def, _ := r.AmountOut(testToken0, ether(1))
+	r.FeeBps = 25
+	low, _ := r.AmountOut(testToken0, ether(1))
+
+	if low.Cmp(def) <= 0 {
+		t.Errorf("0.25%% pool out %s, default %s", low, def)
+	}
+}
+
+func TestGetProfitFeeTooHigh(t *testing.T) {
+	pool0 := testReserves(testPool0, ether(100), ether(200))
+	pool1 := testReserves(testPool1, ether(100), ether(250))
+	pool1.FeeBps = 100
+
+	_, err := GetProfit(pool0, pool1, []string{testToken0})
+	if !errors.Is(err, ErrFeeTooHigh) {
+		t.Errorf("got %v, expected %v", err, ErrFeeTooHigh)
+	}
+
+	// cheaper pool is traded at contract price
+	pool1.FeeBps = 25
+	low, err := GetProfit(pool0, pool1, []string{testToken0})
+	if err != nil {
+		t.Fatal(err)
+	}
+	pool1.FeeBps = 0
+	def, err := GetProfit(pool0, pool1, []string{testToken0})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if low.Profit.Cmp(def.Profit) != 0 {
+		t.Errorf("profit %s, contract %s", low.Profit, def.Profit)
+	}
+}
@@ -0,0 +1,112 @@
+package simulator
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// Solidity 0.8 checked arithmetic, any overflow reverts the call
+var (
+	maxUint256 = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 256), big.NewInt(1))
+	maxInt256  = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 255), big.NewInt(1))
+	minInt256  = new(big.Int).Neg(new(big.Int).Lsh(big.NewInt(1), 255))
+)
+
+var (
+	ErrOverflow     = fmt.Errorf("arithmetic overflow")
+	ErrDivideByZero = fmt.Errorf("division or modulo by zero")
+	ErrAssert       = fmt.Errorf("assert failed")
+)
+
+func checkUint256(x *big.Int) (
+	out *big.Int,
+	err error,
+) {
+	if x.Sign() < 0 || x.Cmp(maxUint256) > 0 {
+		err = ErrOverflow
+
+		return
+	}
+	out = x
+
+	return
+}
+
+func checkInt256(x *big.Int) (
+	out *big.Int,
+	err error,
+) {
+	if x.Cmp(minInt256) < 0 || x.Cmp(maxInt256) > 0 {
+		err = ErrOverflow
+
+		return
+	}
+	out = x
+
+	return
+}
+
+func mulUint(a, b *big.Int) (*big.Int, error) {
+	return checkUint256(new(big.Int).Mul(a, b))
+}
+
+func addUint(a, b *big.Int) (*big.Int, error) {
+	return checkUint256(new(big.Int).Add(a, b))
+}
+
+func subUint(a, b *big.Int) (*big.Int, error) {
+	return checkUint256(new(big.Int).Sub(a, b))
+}
+
+func divUint(a, b *big.Int) (
+	out *big.Int,
+	err error,
+) {
+	if b.Sign() == 0 {
+		err = ErrDivideByZero
+
+		return
+	}
+	out = new(big.Int).Quo(a, b)
+
+	return
+}
+
+func mulInt(a, b *big.Int) (*big.Int, error) {
+	return checkInt256(new(big.Int).Mul(a, b))
+}
+
+func addInt(a, b *big.Int) (*big.Int, error) {
+	return checkInt256(new(big.Int).Add(a, b))
+}
+
+func subInt(a, b *big.Int) (*big.Int, error) {
+	return checkInt256(new(big.Int).Sub(a, b))
+}
+
+// signed division truncates toward zero as in solidity
+func divInt(a, b *big.Int) (
+	out *big.Int,
+	err error,
+) {
+	if b.Sign() == 0 {
+		err = ErrDivideByZero
+
+		return
+	}
+	out, err = checkInt256(new(big.Int).Quo(a, b))
+
+	return
+}
+
+func pow10(n int64) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(n), nil)
+}
+
+func minBig(a, b *big.Int) *big.Int {
+	if a.Cmp(b) < 0 {
+		return a
+	}
+
+	return b
+}
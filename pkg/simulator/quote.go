@@ -0,0 +1,131 @@
+package simulator
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+
+	"github.com/antonyuhnovets/flash-loan-arbitrage/internal/entities"
+)
+
+// Quoter prices exact input swaps of a pool
+type Quoter interface {
+	PoolAddress() string
+	AmountOut(tokenIn string, amountIn *big.Int) (*big.Int, error)
+}
+
+// Quote is a result of buying quote token on one pool
+// and selling it back to base token on another
+type Quote struct {
+	BuyPool   string   `json:"buyPool"`
+	SellPool  string   `json:"sellPool"`
+	BaseToken string   `json:"baseToken"`
+	AmountIn  *big.Int `json:"amountIn"`
+	Quote     *big.Int `json:"quoteAmount"`
+	AmountOut *big.Int `json:"amountOut"`
+	Profit    *big.Int `json:"profit"` // negative on loss
+}
+
+// MarshalJSON encodes amounts as decimal strings
+func (q Quote) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		BuyPool   string           `json:"buyPool"`
+		SellPool  string           `json:"sellPool"`
+		BaseToken string           `json:"baseToken"`
+		AmountIn  *entities.Amount `json:"amountIn"`
+		Quote     *entities.Amount `json:"quoteAmount"`
+		AmountOut *entities.Amount `json:"amountOut"`
+		Profit    *entities.Amount `json:"profit"`
+	}{
+		q.BuyPool, q.SellPool, q.BaseToken,
+		entities.NewAmount(q.AmountIn),
+		entities.NewAmount(q.Quote),
+		entities.NewAmount(q.AmountOut),
+		entities.NewAmount(q.Profit),
+	})
+}
+
+// AmountOut quotes exact input swap through uniswap-v2 like pool
+// charging its own fee
+func (r Reserves) AmountOut(tokenIn string, amountIn *big.Int) (
+	amountOut *big.Int,
+	err error,
+) {
+	switch {
+	case sameAddress(tokenIn, r.Token0):
+		amountOut, err = GetAmountOutFee(amountIn, r.Reserve0, r.Reserve1, r.Fee())
+	case sameAddress(tokenIn, r.Token1):
+		amountOut, err = GetAmountOutFee(amountIn, r.Reserve1, r.Reserve0, r.Fee())
+	default:
+		err = fmt.Errorf("token %s not in pool %s", tokenIn, r.Pool)
+	}
+
+	return
+}
+
+// PoolAddress returns address of the pool
+func (r Reserves) PoolAddress() string {
+	return r.Pool
+}
+
+// QuotePair swaps amountIn of base to quote on buy pool
+// and the received quote back to base on sell pool
+func QuotePair(
+	buy, sell Quoter,
+	base, quote string,
+	amountIn *big.Int,
+) (
+	res Quote,
+	err error,
+) {
+	mid, err := buy.AmountOut(base, amountIn)
+	if err != nil {
+		return
+	}
+
+	out, err := sell.AmountOut(quote, mid)
+	if err != nil {
+		return
+	}
+
+	res = Quote{
+		BuyPool:   buy.PoolAddress(),
+		SellPool:  sell.PoolAddress(),
+		BaseToken: base,
+		AmountIn:  amountIn,
+		Quote:     mid,
+		AmountOut: out,
+		Profit:    new(big.Int).Sub(out, amountIn),
+	}
+
+	return
+}
+
+// BestQuote tries both directions of a pool pair and returns
+// the more profitable one, pools may be of any AMM family
+func BestQuote(
+	pool0, pool1 Quoter,
+	base, quote string,
+	amountIn *big.Int,
+) (
+	res Quote,
+	err error,
+) {
+	q0, err0 := QuotePair(pool0, pool1, base, quote, amountIn)
+	q1, err1 := QuotePair(pool1, pool0, base, quote, amountIn)
+
+	switch {
+	case err0 != nil && err1 != nil:
+		err = err0
+	case err0 != nil:
+		res = q1
+	case err1 != nil:
+		res = q0
+	case q1.Profit.Cmp(q0.Profit) > 0:
+		res = q1
+	default:
+		res = q0
+	}
+
+	return
+}
@@ -0,0 +1,521 @@
+package simulator
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Revert reasons of FlashBot contract
+var (
+	ErrSamePair         = fmt.Errorf("Same pair address")
+	ErrNonStandardPair  = fmt.Errorf("Non standard uniswap AMM pair")
+	ErrNotSameTokenPair = fmt.Errorf("Require same token pair")
+	ErrNoBaseToken      = fmt.Errorf("No base token in pair")
+	ErrWrongInputOrder  = fmt.Errorf("Wrong input order")
+	ErrComplexNumber    = fmt.Errorf("Complex number")
+	ErrInsufficientOut  = fmt.Errorf("UniswapV2Library: INSUFFICIENT_OUTPUT_AMOUNT")
+	ErrInsufficientIn   = fmt.Errorf("UniswapV2Library: INSUFFICIENT_INPUT_AMOUNT")
+	ErrInsufficientLiq  = fmt.Errorf("UniswapV2Library: INSUFFICIENT_LIQUIDITY")
+	ErrSafeMathSubtract = fmt.Errorf("SafeMath: subtraction overflow")
+	ErrSafeMathDivision = fmt.Errorf("SafeMath: division by zero")
+)
+
+// decimal base of Decimal.D256 contract library
+var decimalBase = pow10(18)
+
+// Reserves is a state of uniswap-v2 like pool
+type Reserves struct {
+	Pool     string
+	Token0   string
+	Token1   string
+	Reserve0 *big.Int
+	Reserve1 *big.Int
+	FeeBps   uint32 // swap fee, contract fee if 0
+}
+
+// OrderedReserves mirrors contract struct:
+// (A1, B1) - pool with lower price, (A1, A2) - base token reserves
+type OrderedReserves struct {
+	A1 *big.Int
+	B1 *big.Int
+	A2 *big.Int
+	B2 *big.Int
+}
+
+// Result of profit calculation for two pools
+type Result struct {
+	Profit             *big.Int
+	BaseToken          string
+	QuoteToken         string
+	BaseTokenSmaller   bool
+	LowerPool          string
+	HigherPool         string
+	BorrowAmount       *big.Int
+	DebtAmount         *big.Int
+	BaseTokenOutAmount *big.Int
+}
+
+// GetProfit calculates arbitrage profit the same way as FlashBot.getProfit does.
+// Contract prices both swaps with 0.3% fee, so pools charging more are
+// rejected, pools charging less are traded at the contract price
+func GetProfit(
+	pool0, pool1 Reserves,
+	baseTokens []string,
+) (
+	res Result,
+	err error,
+) {
+	for _, pool := range []Reserves{pool0, pool1} {
+		if pool.Fee() > ContractFeeBps {
+			err = fmt.Errorf("%w: %s charges %v bps", ErrFeeTooHigh, pool.Pool, pool.Fee())
+
+			return
+		}
+	}
+
+	smaller, base, quote, err := IsBaseTokenSmaller(
+		pool0, pool1, baseTokens,
+	)
+	if err != nil {
+		return
+	}
+
+	lower, higher, ordered, err := GetOrderedReserves(
+		pool0, pool1, smaller,
+	)
+	if err != nil {
+		return
+	}
+
+	borrow, err := CalcBorrowAmount(ordered)
+	if err != nil {
+		return
+	}
+
+	// borrow quote token on lower price pool
+	debt, err := GetAmountIn(borrow, ordered.A1, ordered.B1)
+	if err != nil {
+		return
+	}
+
+	// sell borrowed quote token on higher price pool
+	out, err := GetAmountOut(borrow, ordered.B2, ordered.A2)
+	if err != nil {
+		return
+	}
+
+	profit := big.NewInt(0)
+	if out.Cmp(debt) >= 0 {
+		profit.Sub(out, debt)
+	}
+
+	res = Result{
+		Profit:             profit,
+		BaseToken:          base,
+		QuoteToken:         quote,
+		BaseTokenSmaller:   smaller,
+		LowerPool:          lower,
+		HigherPool:         higher,
+		BorrowAmount:       borrow,
+		DebtAmount:         debt,
+		BaseTokenOutAmount: out,
+	}
+
+	return
+}
+
+// IsBaseTokenSmaller checks pools the same way as contract and
+// finds out base & quote tokens
+func IsBaseTokenSmaller(
+	pool0, pool1 Reserves,
+	baseTokens []string,
+) (
+	baseSmaller bool,
+	baseToken, quoteToken string,
+	err error,
+) {
+	if sameAddress(pool0.Pool, pool1.Pool) {
+		err = ErrSamePair
+
+		return
+	}
+
+	if !lessAddress(pool0.Token0, pool0.Token1) ||
+		!lessAddress(pool1.Token0, pool1.Token1) {
+		err = ErrNonStandardPair
+
+		return
+	}
+
+	if !sameAddress(pool0.Token0, pool1.Token0) ||
+		!sameAddress(pool0.Token1, pool1.Token1) {
+		err = ErrNotSameTokenPair
+
+		return
+	}
+
+	switch {
+	case containAddress(baseTokens, pool0.Token0):
+		baseSmaller, baseToken, quoteToken = true, pool0.Token0, pool0.Token1
+	case containAddress(baseTokens, pool0.Token1):
+		baseSmaller, baseToken, quoteToken = false, pool0.Token1, pool0.Token0
+	default:
+		err = ErrNoBaseToken
+	}
+
+	return
+}
+
+// GetOrderedReserves compares price denominated in quote token between two pools
+func GetOrderedReserves(
+	pool0, pool1 Reserves,
+	baseTokenSmaller bool,
+) (
+	lowerPool, higherPool string,
+	ordered OrderedReserves,
+	err error,
+) {
+	var price0, price1 *big.Int
+
+	if baseTokenSmaller {
+		price0, err = decimalPrice(pool0.Reserve0, pool0.Reserve1)
+		if err != nil {
+			return
+		}
+		price1, err = decimalPrice(pool1.Reserve0, pool1.Reserve1)
+	} else {
+		price0, err = decimalPrice(pool0.Reserve1, pool0.Reserve0)
+		if err != nil {
+			return
+		}
+		price1, err = decimalPrice(pool1.Reserve1, pool1.Reserve0)
+	}
+	if err != nil {
+		return
+	}
+
+	low, high := pool1, pool0
+	if price0.Cmp(price1) < 0 {
+		low, high = pool0, pool1
+	}
+
+	lowerPool, higherPool = low.Pool, high.Pool
+
+	if baseTokenSmaller {
+		ordered = OrderedReserves{
+			A1: low.Reserve0, B1: low.Reserve1,
+			A2: high.Reserve0, B2: high.Reserve1,
+		}
+	} else {
+		ordered = OrderedReserves{
+			A1: low.Reserve1, B1: low.Reserve0,
+			A2: high.Reserve1, B2: high.Reserve0,
+		}
+	}
+
+	return
+}
+
+// CalcBorrowAmount calculates the maximum base asset amount to borrow
+// in order to get maximum profit during arbitrage
+func CalcBorrowAmount(reserves OrderedReserves) (
+	amount *big.Int,
+	err error,
+) {
+	min := minBig(
+		minBig(reserves.A1, reserves.B1),
+		minBig(reserves.A2, reserves.B2),
+	)
+
+	d := divider(min)
+
+	a1, _ := divUint(reserves.A1, d)
+	a2, _ := divUint(reserves.A2, d)
+	b1, _ := divUint(reserves.B1, d)
+	b2, _ := divUint(reserves.B2, d)
+
+	a, b, c, err := quadraticCoefficients(a1, a2, b1, b2)
+	if err != nil {
+		return
+	}
+
+	x1, x2, err := CalcSolutionForQuadratic(a, b, c)
+	if err != nil {
+		return
+	}
+
+	// 0 < x < b1 and 0 < x < b2
+	x1Fit := x1.Sign() > 0 && x1.Cmp(b1) < 0 && x1.Cmp(b2) < 0
+	x2Fit := x2.Sign() > 0 && x2.Cmp(b1) < 0 && x2.Cmp(b2) < 0
+
+	switch {
+	case x1Fit:
+		amount, err = mulUint(x1, d)
+	case x2Fit:
+		amount, err = mulUint(x2, d)
+	default:
+		err = ErrWrongInputOrder
+	}
+
+	return
+}
+
+// CalcSolutionForQuadratic finds solution of quadratic equation:
+// ax^2 + bx + c = 0
+func CalcSolutionForQuadratic(a, b, c *big.Int) (
+	x1, x2 *big.Int,
+	err error,
+) {
+	bb, err := mulInt(b, b)
+	if err != nil {
+		return
+	}
+	ac, err := mulInt(big.NewInt(4), a)
+	if err != nil {
+		return
+	}
+	ac, err = mulInt(ac, c)
+	if err != nil {
+		return
+	}
+	m, err := subInt(bb, ac)
+	if err != nil {
+		return
+	}
+
+	// m < 0 leads to complex number
+	if m.Sign() <= 0 {
+		err = ErrComplexNumber
+
+		return
+	}
+
+	sqrtM, err := Sqrt(m)
+	if err != nil {
+		return
+	}
+
+	a2, err := mulInt(big.NewInt(2), a)
+	if err != nil {
+		return
+	}
+	negB, err := subInt(big.NewInt(0), b)
+	if err != nil {
+		return
+	}
+
+	n1, err := addInt(negB, sqrtM)
+	if err != nil {
+		return
+	}
+	x1, err = divInt(n1, a2)
+	if err != nil {
+		return
+	}
+
+	n2, err := subInt(negB, sqrtM)
+	if err != nil {
+		return
+	}
+	x2, err = divInt(n2, a2)
+
+	return
+}
+
+// Sqrt mirrors contract Newton's method with the same precision
+func Sqrt(n *big.Int) (
+	res *big.Int,
+	err error,
+) {
+	if n.Cmp(big.NewInt(1)) <= 0 {
+		err = ErrAssert
+
+		return
+	}
+
+	_n, err := mulUint(n, pow10(6))
+	if err != nil {
+		return
+	}
+
+	c := _n
+	res = _n
+	threshold := big.NewInt(1000)
+
+	for {
+		xi, _err := divUint(c, res)
+		if _err != nil {
+			err = _err
+
+			return
+		}
+		xi, err = addUint(res, xi)
+		if err != nil {
+			return
+		}
+		xi.Quo(xi, big.NewInt(2))
+
+		diff, _err := subUint(res, xi)
+		if _err != nil {
+			err = _err
+
+			return
+		}
+		if diff.Cmp(threshold) < 0 {
+			break
+		}
+		res = xi
+	}
+
+	res = new(big.Int).Quo(res, pow10(3))
+
+	return
+}
+
+// GetAmountIn returns a required input amount of the other asset
+// for given output amount and pair reserves (UniswapV2Library)
+func GetAmountIn(amountOut, reserveIn, reserveOut *big.Int) (
+	amountIn *big.Int,
+	err error,
+) {
+	return GetAmountInFee(amountOut, reserveIn, reserveOut, ContractFeeBps)
+}
+
+// GetAmountOut returns the maximum output amount of the other asset
+// for given input amount and pair reserves (UniswapV2Library)
+func GetAmountOut(amountIn, reserveIn, reserveOut *big.Int) (
+	amountOut *big.Int,
+	err error,
+) {
+	return GetAmountOutFee(amountIn, reserveIn, reserveOut, ContractFeeBps)
+}
+
+// price as Decimal.from(a).div(b)
+func decimalPrice(a, b *big.Int) (
+	price *big.Int,
+	err error,
+) {
+	value, err := mulUint(a, decimalBase)
+	if err != nil {
+		return
+	}
+	if b.Sign() == 0 {
+		err = ErrSafeMathDivision
+
+		return
+	}
+	price = new(big.Int).Quo(value, b)
+
+	return
+}
+
+// divider chosen by contract to prevent intermediate overflow
+func divider(min *big.Int) (
+	d *big.Int,
+) {
+	// (threshold exponent, divider exponent)
+	steps := [][2]int64{
+		{24, 20}, {23, 19}, {22, 18}, {21, 17}, {20, 16},
+		{19, 15}, {18, 14}, {17, 13}, {16, 12}, {15, 11},
+	}
+
+	for _, step := range steps {
+		if min.Cmp(pow10(step[0])) > 0 {
+			d = pow10(step[1])
+
+			return
+		}
+	}
+	d = pow10(10)
+
+	return
+}
+
+// a = a1 * b1 - a2 * b2
+// b = 2 * b1 * b2 * (a1 + a2)
+// c = b1 * b2 * (a1 * b2 - a2 * b1)
+func quadraticCoefficients(a1, a2, b1, b2 *big.Int) (
+	a, b, c *big.Int,
+	err error,
+) {
+	for _, v := range []*big.Int{a1, a2, b1, b2} {
+		if _, err = checkInt256(v); err != nil {
+			return
+		}
+	}
+
+	a1b1, err := mulInt(a1, b1)
+	if err != nil {
+		return
+	}
+	a2b2, err := mulInt(a2, b2)
+	if err != nil {
+		return
+	}
+	a, err = subInt(a1b1, a2b2)
+	if err != nil {
+		return
+	}
+
+	b, err = mulInt(big.NewInt(2), b1)
+	if err != nil {
+		return
+	}
+	b, err = mulInt(b, b2)
+	if err != nil {
+		return
+	}
+	a1a2, err := addInt(a1, a2)
+	if err != nil {
+		return
+	}
+	b, err = mulInt(b, a1a2)
+	if err != nil {
+		return
+	}
+
+	b1b2, err := mulInt(b1, b2)
+	if err != nil {
+		return
+	}
+	a1b2, err := mulInt(a1, b2)
+	if err != nil {
+		return
+	}
+	a2b1, err := mulInt(a2, b1)
+	if err != nil {
+		return
+	}
+	diff, err := subInt(a1b2, a2b1)
+	if err != nil {
+		return
+	}
+	c, err = mulInt(b1b2, diff)
+
+	return
+}
+
+func sameAddress(a, b string) bool {
+	return common.HexToAddress(a) == common.HexToAddress(b)
+}
+
+// address comparison as uint160
+func lessAddress(a, b string) bool {
+	return bytes.Compare(
+		common.HexToAddress(a).Bytes(),
+		common.HexToAddress(b).Bytes(),
+	) < 0
+}
+
+func containAddress(list []string, addr string) bool {
+	for _, a := range list {
+		if sameAddress(a, addr) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,288 @@
+package simulator
+
+import (
+	"math/big"
+	"testing"
+)
+
+const (
+	testPool0  = "0x0000000000000000000000000000000000000a01"
+	testPool1  = "0x0000000000000000000000000000000000000a02"
+	testToken0 = "0x0000000000000000000000000000000000000b01"
+	testToken1 = "0x0000000000000000000000000000000000000b02"
+)
+
+func bigFromString(s string) *big.Int {
+	b, ok := new(big.Int).SetString(s, 10)
+	if !ok {
+		panic("invalid number " + s)
+	}
+
+	return b
+}
+
+func ether(n int64) *big.Int {
+	return new(big.Int).Mul(big.NewInt(n), pow10(18))
+}
+
+func testReserves(pool string, r0, r1 *big.Int) Reserves {
+	return Reserves{
+		Pool:     pool,
+		Token0:   testToken0,
+		Token1:   testToken1,
+		Reserve0: r0,
+		Reserve1: r1,
+	}
+}
+
+type goldenTest struct {
+	name       string
+	pool0      Reserves
+	pool1      Reserves
+	baseTokens []string
+	profit     string
+	borrow     string
+	debt       string
+	out        string
+	baseToken  string
+	lowerPool  string
+}
+
+// expected values are FlashBot.getProfit results for the same reserves
+var goldenTests = []goldenTest{
+	{
+		name:       "base token smaller, small gap",
+		pool0:      testReserves(testPool0, ether(1000), ether(2000000)),
+		pool1:      testReserves(testPool1, ether(1010), ether(1980000)),
+		baseTokens: []string{testToken0},
+		profit:     "20132177557023688",
+		borrow:     "9950240000000000000000",
+		debt:       "5015040700100320419",
+		out:        "5035172877657344107",
+		baseToken:  testToken0,
+		lowerPool:  testPool0,
+	},
+	{
+		name:       "base token larger, wide gap",
+		pool0:      testReserves(testPool0, ether(5000000), ether(2500)),
+		pool1:      testReserves(testPool1, ether(4700000), ether(2500)),
+		baseTokens: []string{testToken1},
+		profit:     "965491553019509219",
+		borrow:     "74982000000000000000000",
+		debt:       "38176318781274412324",
+		out:        "39141810334293921543",
+		baseToken:  testToken1,
+		lowerPool:  testPool0,
+	},
+	{
+		name:       "reversed pools order",
+		pool0:      testReserves(testPool0, ether(1010), ether(1980000)),
+		pool1:      testReserves(testPool1, ether(1000), ether(2000000)),
+		baseTokens: []string{testToken0},
+		profit:     "20132177557023688",
+		borrow:     "9950240000000000000000",
+		debt:       "5015040700100320419",
+		out:        "5035172877657344107",
+		baseToken:  testToken0,
+		lowerPool:  testPool1,
+	},
+	{
+		name: "small reserves",
+		pool0: testReserves(
+			testPool0,
+			bigFromString("3000000000000000"),
+			bigFromString("9000000000000000"),
+		),
+		pool1: testReserves(
+			testPool1,
+			bigFromString("4000000000000000"),
+			bigFromString("10000000000000000"),
+		),
+		baseTokens: []string{testToken0},
+		profit:     "13455676095762",
+		borrow:     "430400000000000",
+		debt:       "151125519950442",
+		out:        "164581196046204",
+		baseToken:  testToken0,
+		lowerPool:  testPool0,
+	},
+	{
+		name:       "both tokens are base, token0 preferred",
+		pool0:      testReserves(testPool0, ether(3000000), ether(9000000)),
+		pool1:      testReserves(testPool1, ether(2900000), ether(9100000)),
+		baseTokens: []string{testToken1, testToken0},
+		profit:     "546951525632664600694",
+		borrow:     "101700000000000000000000",
+		debt:       "32874815504825490775027",
+		out:        "33421767030458155375721",
+		baseToken:  testToken0,
+		lowerPool:  testPool1,
+	},
+	{
+		name:       "base token larger, quote heavy",
+		pool0:      testReserves(testPool0, ether(250), ether(400000)),
+		pool1:      testReserves(testPool1, ether(240), ether(410000)),
+		baseTokens: []string{testToken1},
+		profit:     "177745400431015935801",
+		borrow:     "4010000000000000000",
+		debt:       "6540210900598865583295",
+		out:        "6717956301029881519096",
+		baseToken:  testToken1,
+		lowerPool:  testPool0,
+	},
+}
+
+func TestGetProfitGolden(t *testing.T) {
+	for _, tc := range goldenTests {
+		res, err := GetProfit(tc.pool0, tc.pool1, tc.baseTokens)
+		if err != nil {
+			t.Errorf("%s: unexpected error %s", tc.name, err)
+
+			continue
+		}
+
+		checks := map[string][2]*big.Int{
+			"profit": {res.Profit, bigFromString(tc.profit)},
+			"borrow": {res.BorrowAmount, bigFromString(tc.borrow)},
+			"debt":   {res.DebtAmount, bigFromString(tc.debt)},
+			"out":    {res.BaseTokenOutAmount, bigFromString(tc.out)},
+		}
+		for field, v := range checks {
+			if v[0].Cmp(v[1]) != 0 {
+				t.Errorf(
+					"%s: %s got %s, expected %s",
+					tc.name, field, v[0], v[1],
+				)
+			}
+		}
+
+		if !sameAddress(res.BaseToken, tc.baseToken) {
+			t.Errorf(
+				"%s: base token got %s, expected %s",
+				tc.name, res.BaseToken, tc.baseToken,
+			)
+		}
+		if !sameAddress(res.LowerPool, tc.lowerPool) {
+			t.Errorf(
+				"%s: lower pool got %s, expected %s",
+				tc.name, res.LowerPool, tc.lowerPool,
+			)
+		}
+	}
+}
+
+type revertTest struct {
+	name       string
+	pool0      Reserves
+	pool1      Reserves
+	baseTokens []string
+	expected   error
+}
+
+var revertTests = []revertTest{
+	{
+		name:       "same pool",
+		pool0:      testReserves(testPool0, ether(1), ether(2)),
+		pool1:      testReserves(testPool0, ether(1), ether(2)),
+		baseTokens: []string{testToken0},
+		expected:   ErrSamePair,
+	},
+	{
+		name: "unordered tokens",
+		pool0: Reserves{
+			Pool:     testPool0,
+			Token0:   testToken1,
+			Token1:   testToken0,
+			Reserve0: ether(1),
+			Reserve1: ether(2),
+		},
+		pool1:      testReserves(testPool1, ether(1), ether(2)),
+		baseTokens: []string{testToken0},
+		expected:   ErrNonStandardPair,
+	},
+	{
+		name:  "different token pairs",
+		pool0: testReserves(testPool0, ether(1), ether(2)),
+		pool1: Reserves{
+			Pool:     testPool1,
+			Token0:   testToken0,
+			Token1:   "0x0000000000000000000000000000000000000b03",
+			Reserve0: ether(1),
+			Reserve1: ether(2),
+		},
+		baseTokens: []string{testToken0},
+		expected:   ErrNotSameTokenPair,
+	},
+	{
+		name:       "no base token",
+		pool0:      testReserves(testPool0, ether(1), ether(2)),
+		pool1:      testReserves(testPool1, ether(1), ether(3)),
+		baseTokens: []string{},
+		expected:   ErrNoBaseToken,
+	},
+	{
+		name:       "equal prices",
+		pool0:      testReserves(testPool0, ether(1), ether(2)),
+		pool1:      testReserves(testPool1, ether(1), ether(2)),
+		baseTokens: []string{testToken0},
+		expected:   ErrDivideByZero,
+	},
+}
+
+func TestGetProfitReverts(t *testing.T) {
+	for _, tc := range revertTests {
+		_, err := GetProfit(tc.pool0, tc.pool1, tc.baseTokens)
+		if err != tc.expected {
+			t.Errorf(
+				"%s: got error %v, expected %v",
+				tc.name, err, tc.expected,
+			)
+		}
+	}
+}
+
+type amountTest struct {
+	amount     *big.Int
+	reserveIn  *big.Int
+	reserveOut *big.Int
+	expected   *big.Int
+}
+
+func TestGetAmountOut(t *testing.T) {
+	tests := []amountTest{
+		{ether(1), ether(100), ether(200), bigFromString("1974316068794122597")},
+		{big.NewInt(1000), big.NewInt(1000000), big.NewInt(1000000), big.NewInt(996)},
+	}
+
+	for index, tc := range tests {
+		out, err := GetAmountOut(tc.amount, tc.reserveIn, tc.reserveOut)
+		if err != nil || out.Cmp(tc.expected) != 0 {
+			t.Errorf(
+				"%v: got %s %v, expected %s",
+				index, out, err, tc.expected,
+			)
+		}
+	}
+}
+
+func TestGetAmountIn(t *testing.T) {
+	tests := []amountTest{
+		{ether(1), ether(100), ether(200), bigFromString("504024636724243082")},
+		{big.NewInt(996), big.NewInt(1000000), big.NewInt(1000000), big.NewInt(1000)},
+	}
+
+	for index, tc := range tests {
+		in, err := GetAmountIn(tc.amount, tc.reserveIn, tc.reserveOut)
+		if err != nil || in.Cmp(tc.expected) != 0 {
+			t.Errorf(
+				"%v: got %s %v, expected %s",
+				index, in, err, tc.expected,
+			)
+		}
+	}
+
+	_, err := GetAmountIn(ether(3), ether(1), ether(2))
+	if err != ErrSafeMathSubtract {
+		t.Errorf("got %v, expected %v", err, ErrSafeMathSubtract)
+	}
+}
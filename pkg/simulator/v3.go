@@ -0,0 +1,557 @@
+package simulator
+
+import (
+	"fmt"
+	"math/big"
+	"sort"
+)
+
+// Tick range of uniswap-v3 pools (TickMath)
+const (
+	MinTick = -887272
+	MaxTick = 887272
+)
+
+var (
+	MinSqrtRatio, _ = new(big.Int).SetString("4295128739", 10)
+	MaxSqrtRatio, _ = new(big.Int).SetString("1461446703485210103287273052203988822378723970342", 10)
+)
+
+var (
+	ErrTickRange      = fmt.Errorf("tick out of range")
+	ErrSqrtPriceRange = fmt.Errorf("sqrt price out of range")
+	ErrLoadedRange    = fmt.Errorf("swap leaves loaded tick range")
+	ErrLiquidityNeg   = fmt.Errorf("liquidity below zero")
+)
+
+var (
+	q96       = new(big.Int).Lsh(big.NewInt(1), 96)
+	q128      = new(big.Int).Lsh(big.NewInt(1), 128)
+	q32       = new(big.Int).Lsh(big.NewInt(1), 32)
+	feePipsMx = big.NewInt(1_000_000)
+)
+
+// multipliers of TickMath.getSqrtRatioAtTick by tick bit
+var tickRatios = func() (out []*big.Int) {
+	for _, h := range []string{
+		"fffcb933bd6fad37aa2d162d1a594001",
+		"fff97272373d413259a46990580e213a",
+		"fff2e50f5f656932ef12357cf3c7fdcc",
+		"ffe5caca7e10e4e61c3624eaa0941cd0",
+		"ffcb9843d60f6159c9db58835c926644",
+		"ff973b41fa98c081472e6896dfb254c0",
+		"ff2ea16466c96a3843ec78b326b52861",
+		"fe5dee046a99a2a811c461f1969c3053",
+		"fcbe86c7900a88aedcffc83b479aa3a4",
+		"f987a7253ac413176f2b074cf7815e54",
+		"f3392b0822b70005940c7a398e4b70f3",
+		"e7159475a2c29b7443b29c7fa6e889d9",
+		"d097f3bdfd2022b8845ad8f792aa5825",
+		"a9f746462d870fdf8a65dc1f90e061e5",
+		"70d869a156d2a1b890bb3df62baf32f7",
+		"31be135f97d08fd981231505542fcfa6",
+		"9aa508b5b7a84e1c677de54f3e99bc9",
+		"5d6af8dedb81196699c329225ee604",
+		"2216e584f5fa1ea926041bedfe98",
+		"48a170391f7dc42444e8fa2",
+	} {
+		v, _ := new(big.Int).SetString(h, 16)
+		out = append(out, v)
+	}
+
+	return
+}()
+
+// V3Tick is an initialized tick of concentrated liquidity pool
+type V3Tick struct {
+	Index        int
+	LiquidityNet *big.Int
+}
+
+// V3Pool is a state of uniswap-v3 pool. Ticks are initialized ticks
+// sorted by index, known only within [TickLower, TickUpper] range
+type V3Pool struct {
+	Pool         string
+	Token0       string
+	Token1       string
+	Fee          uint32 // in hundredths of a bip
+	SqrtPriceX96 *big.Int
+	Tick         int
+	Liquidity    *big.Int
+	Ticks        []V3Tick
+	TickLower    int
+	TickUpper    int
+}
+
+// V3Swap is an outcome of exact input swap
+type V3Swap struct {
+	AmountIn     *big.Int
+	AmountOut    *big.Int
+	SqrtPriceX96 *big.Int
+	Tick         int
+	Liquidity    *big.Int
+}
+
+// GetSqrtRatioAtTick returns sqrt(1.0001^tick) * 2^96 (TickMath)
+func GetSqrtRatioAtTick(tick int) (
+	sqrtPriceX96 *big.Int,
+	err error,
+) {
+	if tick < MinTick || tick > MaxTick {
+		err = ErrTickRange
+
+		return
+	}
+
+	abs := tick
+	if abs < 0 {
+		abs = -abs
+	}
+
+	ratio := new(big.Int).Set(q128)
+	if abs&1 != 0 {
+		ratio.Set(tickRatios[0])
+	}
+	for i := 1; i < len(tickRatios); i++ {
+		if abs&(1<<i) != 0 {
+			ratio.Mul(ratio, tickRatios[i])
+			ratio.Rsh(ratio, 128)
+		}
+	}
+
+	if tick > 0 {
+		ratio.Quo(maxUint256, ratio)
+	}
+
+	// round up in division by 2^32
+	sqrtPriceX96 = new(big.Int).Rsh(ratio, 32)
+	if new(big.Int).Rem(ratio, q32).Sign() != 0 {
+		sqrtPriceX96.Add(sqrtPriceX96, big.NewInt(1))
+	}
+
+	return
+}
+
+// GetTickAtSqrtRatio returns the greatest tick which ratio
+// is less than or equal to given sqrt price
+func GetTickAtSqrtRatio(sqrtPriceX96 *big.Int) (
+	tick int,
+	err error,
+) {
+	if sqrtPriceX96.Cmp(MinSqrtRatio) < 0 ||
+		sqrtPriceX96.Cmp(MaxSqrtRatio) >= 0 {
+		err = ErrSqrtPriceRange
+
+		return
+	}
+
+	low, high := MinTick, MaxTick
+	for low < high {
+		mid := low + (high-low+1)/2
+
+		ratio, _ := GetSqrtRatioAtTick(mid)
+		if ratio.Cmp(sqrtPriceX96) <= 0 {
+			low = mid
+		} else {
+			high = mid - 1
+		}
+	}
+	tick = low
+
+	return
+}
+
+// TickSpacing returns tick spacing of fee tier enabled by factory
+func TickSpacing(fee uint32) int {
+	switch fee {
+	case 100:
+		return 1
+	case 500:
+		return 10
+	case 3000:
+		return 60
+	case 10000:
+		return 200
+	}
+
+	return 0
+}
+
+// AmountOut quotes exact input swap of tokenIn through the pool
+func (p V3Pool) AmountOut(tokenIn string, amountIn *big.Int) (
+	amountOut *big.Int,
+	err error,
+) {
+	res, err := p.Swap(tokenIn, amountIn)
+	if err != nil {
+		return
+	}
+	amountOut = res.AmountOut
+
+	return
+}
+
+// PoolAddress returns address of the pool
+func (p V3Pool) PoolAddress() string {
+	return p.Pool
+}
+
+// Swap simulates exact input swap the same way as UniswapV3Pool.swap,
+// crossing initialized ticks known to the pool state
+func (p V3Pool) Swap(tokenIn string, amountIn *big.Int) (
+	res V3Swap,
+	err error,
+) {
+	if amountIn.Sign() <= 0 {
+		err = ErrInsufficientIn
+
+		return
+	}
+
+	var zeroForOne bool
+
+	switch {
+	case sameAddress(tokenIn, p.Token0):
+		zeroForOne = true
+	case sameAddress(tokenIn, p.Token1):
+		zeroForOne = false
+	default:
+		err = fmt.Errorf("token %s not in pool %s", tokenIn, p.Pool)
+
+		return
+	}
+
+	limit := new(big.Int).Add(MinSqrtRatio, big.NewInt(1))
+	if !zeroForOne {
+		limit = new(big.Int).Sub(MaxSqrtRatio, big.NewInt(1))
+	}
+
+	remaining := new(big.Int).Set(amountIn)
+	res = V3Swap{
+		AmountIn:     big.NewInt(0),
+		AmountOut:    big.NewInt(0),
+		SqrtPriceX96: new(big.Int).Set(p.SqrtPriceX96),
+		Tick:         p.Tick,
+		Liquidity:    new(big.Int).Set(p.Liquidity),
+	}
+
+	for remaining.Sign() > 0 && res.SqrtPriceX96.Cmp(limit) != 0 {
+		if (zeroForOne && res.Tick < p.TickLower) ||
+			(!zeroForOne && res.Tick >= p.TickUpper) {
+			err = ErrLoadedRange
+
+			return
+		}
+
+		next, net := p.nextTick(res.Tick, zeroForOne)
+		if next < MinTick {
+			next = MinTick
+		}
+		if next > MaxTick {
+			next = MaxTick
+		}
+
+		sqrtNext, _err := GetSqrtRatioAtTick(next)
+		if _err != nil {
+			err = _err
+
+			return
+		}
+
+		target := sqrtNext
+		if (zeroForOne && sqrtNext.Cmp(limit) < 0) ||
+			(!zeroForOne && sqrtNext.Cmp(limit) > 0) {
+			target = limit
+		}
+
+		start := res.SqrtPriceX96
+
+		sqrtP, in, out, fee, _err := ComputeSwapStep(
+			start, target, res.Liquidity, remaining, p.Fee,
+		)
+		if _err != nil {
+			err = _err
+
+			return
+		}
+
+		remaining.Sub(remaining, in)
+		remaining.Sub(remaining, fee)
+		res.AmountIn.Add(res.AmountIn, in)
+		res.AmountIn.Add(res.AmountIn, fee)
+		res.AmountOut.Add(res.AmountOut, out)
+		res.SqrtPriceX96 = sqrtP
+
+		switch {
+		case sqrtP.Cmp(sqrtNext) == 0:
+			if net != nil {
+				if zeroForOne {
+					res.Liquidity.Sub(res.Liquidity, net)
+				} else {
+					res.Liquidity.Add(res.Liquidity, net)
+				}
+				if res.Liquidity.Sign() < 0 {
+					err = ErrLiquidityNeg
+
+					return
+				}
+			}
+			res.Tick = next
+			if zeroForOne {
+				res.Tick = next - 1
+			}
+		case sqrtP.Cmp(start) != 0:
+			res.Tick, err = GetTickAtSqrtRatio(sqrtP)
+			if err != nil {
+				return
+			}
+		}
+	}
+
+	return
+}
+
+// nextTick finds next initialized tick in swap direction,
+// boundary of loaded range is returned if there is none
+func (p V3Pool) nextTick(tick int, lte bool) (
+	next int,
+	liquidityNet *big.Int,
+) {
+	if lte {
+		i := sort.Search(len(p.Ticks), func(i int) bool {
+			return p.Ticks[i].Index > tick
+		})
+		if i > 0 {
+			next, liquidityNet = p.Ticks[i-1].Index, p.Ticks[i-1].LiquidityNet
+
+			return
+		}
+		next = p.TickLower
+
+		return
+	}
+
+	i := sort.Search(len(p.Ticks), func(i int) bool {
+		return p.Ticks[i].Index > tick
+	})
+	if i < len(p.Ticks) {
+		next, liquidityNet = p.Ticks[i].Index, p.Ticks[i].LiquidityNet
+
+		return
+	}
+	next = p.TickUpper
+
+	return
+}
+
+// ComputeSwapStep mirrors SwapMath.computeSwapStep for exact input
+func ComputeSwapStep(
+	sqrtCurrent, sqrtTarget, liquidity, amountRemaining *big.Int,
+	feePips uint32,
+) (
+	sqrtNext, amountIn, amountOut, feeAmount *big.Int,
+	err error,
+) {
+	zeroForOne := sqrtCurrent.Cmp(sqrtTarget) >= 0
+	fee := big.NewInt(int64(feePips))
+	feeComplement := new(big.Int).Sub(feePipsMx, fee)
+
+	lessFee := mulDiv(amountRemaining, feeComplement, feePipsMx)
+
+	if zeroForOne {
+		amountIn, err = getAmount0Delta(sqrtTarget, sqrtCurrent, liquidity, true)
+	} else {
+		amountIn, err = getAmount1Delta(sqrtCurrent, sqrtTarget, liquidity, true)
+	}
+	if err != nil {
+		return
+	}
+
+	if lessFee.Cmp(amountIn) >= 0 {
+		sqrtNext = new(big.Int).Set(sqrtTarget)
+	} else {
+		sqrtNext, err = nextSqrtPriceFromInput(
+			sqrtCurrent, liquidity, lessFee, zeroForOne,
+		)
+		if err != nil {
+			return
+		}
+	}
+
+	max := sqrtNext.Cmp(sqrtTarget) == 0
+
+	if zeroForOne {
+		if !max {
+			amountIn, err = getAmount0Delta(sqrtNext, sqrtCurrent, liquidity, true)
+			if err != nil {
+				return
+			}
+		}
+		amountOut, err = getAmount1Delta(sqrtNext, sqrtCurrent, liquidity, false)
+	} else {
+		if !max {
+			amountIn, err = getAmount1Delta(sqrtCurrent, sqrtNext, liquidity, true)
+			if err != nil {
+				return
+			}
+		}
+		amountOut, err = getAmount0Delta(sqrtCurrent, sqrtNext, liquidity, false)
+	}
+	if err != nil {
+		return
+	}
+
+	if !max {
+		// remainder of input is taken as fee
+		feeAmount = new(big.Int).Sub(amountRemaining, amountIn)
+
+		return
+	}
+	feeAmount = mulDivRoundingUp(amountIn, fee, feeComplement)
+
+	return
+}
+
+// getAmount0Delta = liquidity * (sqrtB - sqrtA) / (sqrtA * sqrtB)
+func getAmount0Delta(sqrtA, sqrtB, liquidity *big.Int, roundUp bool) (
+	amount *big.Int,
+	err error,
+) {
+	if sqrtA.Cmp(sqrtB) > 0 {
+		sqrtA, sqrtB = sqrtB, sqrtA
+	}
+	if sqrtA.Sign() <= 0 {
+		err = ErrSqrtPriceRange
+
+		return
+	}
+
+	numerator1 := new(big.Int).Lsh(liquidity, 96)
+	numerator2 := new(big.Int).Sub(sqrtB, sqrtA)
+
+	if roundUp {
+		amount = divRoundingUp(
+			mulDivRoundingUp(numerator1, numerator2, sqrtB),
+			sqrtA,
+		)
+
+		return
+	}
+	amount = new(big.Int).Quo(mulDiv(numerator1, numerator2, sqrtB), sqrtA)
+
+	return
+}
+
+// getAmount1Delta = liquidity * (sqrtB - sqrtA)
+func getAmount1Delta(sqrtA, sqrtB, liquidity *big.Int, roundUp bool) (
+	amount *big.Int,
+	err error,
+) {
+	if sqrtA.Cmp(sqrtB) > 0 {
+		sqrtA, sqrtB = sqrtB, sqrtA
+	}
+	diff := new(big.Int).Sub(sqrtB, sqrtA)
+
+	if roundUp {
+		amount = mulDivRoundingUp(liquidity, diff, q96)
+
+		return
+	}
+	amount = mulDiv(liquidity, diff, q96)
+
+	return
+}
+
+func nextSqrtPriceFromInput(
+	sqrtP, liquidity, amountIn *big.Int,
+	zeroForOne bool,
+) (
+	next *big.Int,
+	err error,
+) {
+	if sqrtP.Sign() <= 0 || liquidity.Sign() <= 0 {
+		err = ErrInsufficientLiq
+
+		return
+	}
+
+	if zeroForOne {
+		next = nextSqrtPriceFromAmount0(sqrtP, liquidity, amountIn)
+
+		return
+	}
+	next, err = nextSqrtPriceFromAmount1(sqrtP, liquidity, amountIn)
+
+	return
+}
+
+// price moves down when token0 is added, rounding up
+func nextSqrtPriceFromAmount0(sqrtP, liquidity, amount *big.Int) *big.Int {
+	if amount.Sign() == 0 {
+		return new(big.Int).Set(sqrtP)
+	}
+	numerator1 := new(big.Int).Lsh(liquidity, 96)
+
+	product := new(big.Int).Mul(amount, sqrtP)
+	if product.BitLen() <= 256 {
+		denominator := new(big.Int).Add(numerator1, product)
+		if denominator.BitLen() <= 256 {
+			return mulDivRoundingUp(numerator1, sqrtP, denominator)
+		}
+	}
+
+	// overflow fallback of the contract
+	return divRoundingUp(
+		numerator1,
+		new(big.Int).Add(new(big.Int).Quo(numerator1, sqrtP), amount),
+	)
+}
+
+// price moves up when token1 is added, rounding down
+func nextSqrtPriceFromAmount1(sqrtP, liquidity, amount *big.Int) (
+	next *big.Int,
+	err error,
+) {
+	quotient := mulDiv(amount, q96, liquidity)
+
+	next, err = checkUint(new(big.Int).Add(sqrtP, quotient), 160)
+
+	return
+}
+
+func checkUint(x *big.Int, bits int) (
+	out *big.Int,
+	err error,
+) {
+	if x.Sign() < 0 || x.BitLen() > bits {
+		err = ErrOverflow
+
+		return
+	}
+	out = x
+
+	return
+}
+
+func mulDiv(a, b, denominator *big.Int) *big.Int {
+	return new(big.Int).Quo(new(big.Int).Mul(a, b), denominator)
+}
+
+func mulDivRoundingUp(a, b, denominator *big.Int) *big.Int {
+	product := new(big.Int).Mul(a, b)
+	out, rem := new(big.Int).QuoRem(product, denominator, new(big.Int))
+	if rem.Sign() != 0 {
+		out.Add(out, big.NewInt(1))
+	}
+
+	return out
+}
+
+func divRoundingUp(a, b *big.Int) *big.Int {
+	out, rem := new(big.Int).QuoRem(a, b, new(big.Int))
+	if rem.Sign() != 0 {
+		out.Add(out, big.NewInt(1))
+	}
+
+	return out
+}
@@ -0,0 +1,187 @@
+package simulator
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+)
+
+func TestGetSqrtRatioAtTick(t *testing.T) {
+	tests := []struct {
+		tick int
+		want *big.Int
+	}{
+		{0, q96},
+		{MinTick, MinSqrtRatio},
+		{MaxTick, MaxSqrtRatio},
+	}
+
+	for _, tt := range tests {
+		got, err := GetSqrtRatioAtTick(tt.tick)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.Cmp(tt.want) != 0 {
+			t.Errorf("tick %v: got %s, want %s", tt.tick, got, tt.want)
+		}
+	}
+
+	if _, err := GetSqrtRatioAtTick(MaxTick + 1); !errors.Is(err, ErrTickRange) {
+		t.Errorf("expected %v, got %v", ErrTickRange, err)
+	}
+}
+
+func TestGetTickAtSqrtRatio(t *testing.T) {
+	for _, tick := range []int{MinTick, -200000, -60, -1, 0, 1, 60, 200000, MaxTick - 1} {
+		ratio, err := GetSqrtRatioAtTick(tick)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		got, err := GetTickAtSqrtRatio(ratio)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != tick {
+			t.Errorf("ratio of tick %v: got tick %v", tick, got)
+		}
+
+		if tick == MinTick {
+			continue
+		}
+		got, err = GetTickAtSqrtRatio(new(big.Int).Sub(ratio, big.NewInt(1)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != tick-1 {
+			t.Errorf("ratio below tick %v: got tick %v", tick, got)
+		}
+	}
+}
+
+// expected values are taken from uniswap v3-core SwapMath tests
+func TestComputeSwapStep(t *testing.T) {
+	price := q96
+	liquidity := ether(2)
+	amount := ether(1)
+
+	t.Run("capped at price target", func(t *testing.T) {
+		target := bigFromString("79623317895830914510639640423")
+
+		next, in, out, fee, err := ComputeSwapStep(price, target, liquidity, amount, 600)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assertBig(t, "amount in", in, "9975124224178055")
+		assertBig(t, "fee", fee, "5988667735148")
+		assertBig(t, "amount out", out, "9925619580021728")
+		assertBig(t, "price", next, target.String())
+	})
+
+	t.Run("fully spent", func(t *testing.T) {
+		target := bigFromString("250541448375047931186413801569")
+
+		next, in, out, fee, err := ComputeSwapStep(price, target, liquidity, amount, 600)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assertBig(t, "amount in", in, "999400000000000000")
+		assertBig(t, "fee", fee, "600000000000000")
+		assertBig(t, "amount out", out, "666399946655997866")
+		if next.Cmp(target) >= 0 {
+			t.Errorf("price %s reached target %s", next, target)
+		}
+	})
+}
+
+func testV3Pool(ticks []V3Tick) V3Pool {
+	return V3Pool{
+		Pool:         testPool1,
+		Token0:       testToken0,
+		Token1:       testToken1,
+		Fee:          3000,
+		SqrtPriceX96: q96,
+		Tick:         0,
+		Liquidity:    ether(1000),
+		Ticks:        ticks,
+		TickLower:    -6000,
+		TickUpper:    6000,
+	}
+}
+
+func TestV3SwapCrossesTicks(t *testing.T) {
+	plain := testV3Pool(nil)
+	crossing := testV3Pool([]V3Tick{
+		{Index: -60, LiquidityNet: ether(500)},
+		{Index: 60, LiquidityNet: ether(-500)},
+	})
+
+	amount := ether(20)
+
+	for _, zeroForOne := range []bool{true, false} {
+		tokenIn := testToken0
+		if !zeroForOne {
+			tokenIn = testToken1
+		}
+
+		a, err := plain.Swap(tokenIn, amount)
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, err := crossing.Swap(tokenIn, amount)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if b.Liquidity.Cmp(ether(500)) != 0 {
+			t.Errorf("liquidity after crossing %s, want %s", b.Liquidity, ether(500))
+		}
+		if zeroForOne && b.Tick >= -60 || !zeroForOne && b.Tick < 60 {
+			t.Errorf("tick %v did not cross", b.Tick)
+		}
+		// thinner liquidity past the tick gives worse price
+		if b.AmountOut.Cmp(a.AmountOut) >= 0 {
+			t.Errorf("out %s with crossing, %s without", b.AmountOut, a.AmountOut)
+		}
+		if a.AmountIn.Cmp(amount) != 0 || b.AmountIn.Cmp(amount) != 0 {
+			t.Errorf("input not fully spent: %s %s", a.AmountIn, b.AmountIn)
+		}
+	}
+}
+
+func TestV3SwapLoadedRange(t *testing.T) {
+	pool := testV3Pool(nil)
+	pool.TickLower, pool.TickUpper = -60, 60
+
+	if _, err := pool.Swap(testToken0, ether(1)); err != nil {
+		t.Fatalf("swap within range: %v", err)
+	}
+	if _, err := pool.Swap(testToken0, ether(100)); !errors.Is(err, ErrLoadedRange) {
+		t.Errorf("expected %v, got %v", ErrLoadedRange, err)
+	}
+}
+
+func TestBestQuoteMixed(t *testing.T) {
+	// v3 pool prices token0 at 1, v2 pool at 1.1 of token1
+	v3 := testV3Pool(nil)
+	v2 := testReserves(testPool0, ether(1000), ether(1100))
+
+	q, err := BestQuote(v2, v3, testToken1, testToken0, ether(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if q.BuyPool != testPool1 || q.SellPool != testPool0 {
+		t.Errorf("buy on %s sell on %s", q.BuyPool, q.SellPool)
+	}
+	if q.Profit.Sign() <= 0 {
+		t.Errorf("expected profit, got %s", q.Profit)
+	}
+}
+
+func assertBig(t *testing.T, name string, got *big.Int, want string) {
+	t.Helper()
+
+	if got.String() != want {
+		t.Errorf("%s: got %s, want %s", name, got, want)
+	}
+}
@@ -2,7 +2,7 @@ CREATE TYPE token as (
     id int, 
     name varchar(40), 
     address varchar(50), 
-    wei bigint
+    wei numeric(78,0)
     );
 
 CREATE TABLE tokens OF token(
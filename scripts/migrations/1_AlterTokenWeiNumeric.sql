@@ -0,0 +1,3 @@
+ALTER TYPE token 
+    ALTER ATTRIBUTE wei TYPE numeric(78,0) 
+    CASCADE;